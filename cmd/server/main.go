@@ -13,6 +13,14 @@ func main() {
 		log.Fatal("DATABASE_URL environment variable is required")
 	}
 
+	// "create-key" issues an API key instead of starting the server
+	if len(os.Args) > 1 && os.Args[1] == "create-key" {
+		if err := server.CreateKey(os.Args[2:]); err != nil {
+			log.Fatalf("Failed to create API key: %v", err)
+		}
+		return
+	}
+
 	// Run the server
 	if err := server.Run(); err != nil {
 		log.Fatalf("Server error: %v", err)
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/auth"
+)
+
+// ErrAPIKeyNameTaken is returned when the user already has an active key
+// with the name
+var ErrAPIKeyNameTaken = errors.New("API key name is already in use")
+
+// IssueAPIKey creates an API key that signs a user in to the context's
+// workspace. It returns the stored key and the secret key itself, which is
+// only kept as a hash and can't be recovered later.
+func (s *Store) IssueAPIKey(ctx context.Context, userID, name string) (sqlc.ApiKey, string, error) {
+	secret, err := auth.GenerateKey()
+	if err != nil {
+		return sqlc.ApiKey{}, "", err
+	}
+
+	key, err := s.queries.CreateAPIKey(ctx, sqlc.CreateAPIKeyParams{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		WorkspaceID: WorkspaceFromContext(ctx),
+		Name:        name,
+		Prefix:      auth.DisplayPrefix(secret),
+		KeyHash:     auth.HashKey(secret),
+	})
+	if isUniqueViolation(err) {
+		return key, "", ErrAPIKeyNameTaken
+	}
+	if err != nil {
+		return key, "", err
+	}
+	return key, secret, nil
+}
+
+// GetAPIKeyUser returns the user and workspace of the active key with the
+// hash. Keys are looked up before a workspace is known, so this covers
+// every workspace.
+func (s *Store) GetAPIKeyUser(ctx context.Context, keyHash string) (sqlc.GetAPIKeyUserRow, error) {
+	return s.queries.GetAPIKeyUser(ctx, keyHash)
+}
+
+// TouchAPIKey records that a key was just used
+func (s *Store) TouchAPIKey(ctx context.Context, id string) error {
+	return s.queries.TouchAPIKey(ctx, id)
+}
+
+// ListAPIKeysByUser returns a user's keys for the context's workspace
+func (s *Store) ListAPIKeysByUser(ctx context.Context, userID string) ([]sqlc.ApiKey, error) {
+	return s.queries.ListAPIKeysByUser(ctx, sqlc.ListAPIKeysByUserParams{UserID: userID, WorkspaceID: WorkspaceFromContext(ctx)})
+}
+
+// RevokeAPIKey revokes one of a user's keys for the context's workspace
+func (s *Store) RevokeAPIKey(ctx context.Context, arg sqlc.RevokeAPIKeyParams) (int64, error) {
+	arg.WorkspaceID = WorkspaceFromContext(ctx)
+	return s.queries.RevokeAPIKey(ctx, arg)
+}
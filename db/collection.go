@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+)
+
+var (
+	// ErrCollectionNameTaken is returned when a sibling collection already
+	// has the name
+	ErrCollectionNameTaken = errors.New("collection name is already in use")
+
+	// ErrCollectionCycle is returned when a collection would be moved into
+	// itself or one of its descendants
+	ErrCollectionCycle = errors.New("collection cannot be moved into itself")
+)
+
+// CreateCollection adds a collection to the context's workspace, returning
+// ErrCollectionNameTaken if its parent already has a child with the same
+// name
+func (s *Store) CreateCollection(ctx context.Context, arg sqlc.CreateCollectionParams) (sqlc.Collection, error) {
+	arg.WorkspaceID = WorkspaceFromContext(ctx)
+	collection, err := s.queries.CreateCollection(ctx, arg)
+	if isUniqueViolation(err) {
+		return collection, ErrCollectionNameTaken
+	}
+	return collection, err
+}
+
+// UpdateCollection renames or moves a collection of the context's
+// workspace. Moving a collection below itself returns ErrCollectionCycle,
+// and clashing with a sibling's name returns ErrCollectionNameTaken.
+func (s *Store) UpdateCollection(ctx context.Context, arg sqlc.UpdateCollectionParams) (sqlc.Collection, error) {
+	var collection sqlc.Collection
+	arg.WorkspaceID = WorkspaceFromContext(ctx)
+
+	err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+		if arg.ParentID.Valid {
+			parent := sqlc.GetCollectionParams{WorkspaceID: arg.WorkspaceID, ID: arg.ParentID.String}
+			if _, err := q.GetCollection(ctx, parent); err != nil {
+				return err
+			}
+			ancestors, err := q.ListCollectionAncestorIDs(ctx, arg.ParentID.String)
+			if err != nil {
+				return err
+			}
+			for _, id := range ancestors {
+				if id == arg.ID {
+					return ErrCollectionCycle
+				}
+			}
+		}
+
+		var err error
+		collection, err = q.UpdateCollection(ctx, arg)
+		return err
+	})
+	if isUniqueViolation(err) {
+		return collection, ErrCollectionNameTaken
+	}
+
+	return collection, err
+}
+
+// DeleteCollection deletes a collection of the context's workspace, moving
+// its prompts and child collections up to its parent. It returns
+// ErrCollectionNameTaken if a child would clash with one of the parent's
+// collections.
+func (s *Store) DeleteCollection(ctx context.Context, id string) error {
+	err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+		collection, err := q.GetCollection(ctx, sqlc.GetCollectionParams{WorkspaceID: WorkspaceFromContext(ctx), ID: id})
+		if err != nil {
+			return err
+		}
+
+		from := sql.NullString{String: id, Valid: true}
+		err = q.MoveChildCollections(ctx, sqlc.MoveChildCollectionsParams{ToParentID: collection.ParentID, FromParentID: from})
+		if err != nil {
+			return err
+		}
+		err = q.MovePromptsToCollection(ctx, sqlc.MovePromptsToCollectionParams{ToCollectionID: collection.ParentID, FromCollectionID: from})
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteCollection(ctx, id)
+	})
+	if isUniqueViolation(err) {
+		return ErrCollectionNameTaken
+	}
+
+	return err
+}
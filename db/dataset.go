@@ -0,0 +1,183 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+)
+
+// Eval run statuses
+const (
+	EvalRunPending   = "pending"
+	EvalRunRunning   = "running"
+	EvalRunCompleted = "completed"
+	EvalRunFailed    = "failed"
+)
+
+var (
+	// ErrDatasetNameTaken is returned when another dataset has the name
+	ErrDatasetNameTaken = errors.New("dataset name is already in use")
+
+	// ErrDatasetInUse is returned when deleting a dataset that eval runs
+	// still refer to
+	ErrDatasetInUse = errors.New("dataset is used by eval runs")
+)
+
+// CreateDataset adds a dataset to the context's workspace together with
+// its initial rows. The rows' DatasetID and Position are filled in.
+func (s *Store) CreateDataset(ctx context.Context, arg sqlc.CreateDatasetParams, rows []sqlc.CreateDatasetRowParams) (sqlc.Dataset, []sqlc.DatasetRow, error) {
+	var dataset sqlc.Dataset
+	var created []sqlc.DatasetRow
+	arg.WorkspaceID = WorkspaceFromContext(ctx)
+
+	err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		if dataset, err = q.CreateDataset(ctx, arg); err != nil {
+			return err
+		}
+		created, err = addDatasetRows(ctx, q, dataset.ID, rows)
+		return err
+	})
+	if isUniqueViolation(err) {
+		return dataset, nil, ErrDatasetNameTaken
+	}
+
+	return dataset, created, err
+}
+
+// UpdateDataset renames a dataset of the context's workspace or changes
+// its description
+func (s *Store) UpdateDataset(ctx context.Context, arg sqlc.UpdateDatasetParams) (sqlc.Dataset, error) {
+	arg.WorkspaceID = WorkspaceFromContext(ctx)
+	dataset, err := s.queries.UpdateDataset(ctx, arg)
+	if isUniqueViolation(err) {
+		return dataset, ErrDatasetNameTaken
+	}
+	return dataset, err
+}
+
+// AddDatasetRows appends rows to a dataset of the context's workspace,
+// numbering them after its last row
+func (s *Store) AddDatasetRows(ctx context.Context, datasetID string, rows []sqlc.CreateDatasetRowParams) ([]sqlc.DatasetRow, error) {
+	var created []sqlc.DatasetRow
+
+	err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+		if err := checkDataset(ctx, q, datasetID); err != nil {
+			return err
+		}
+
+		var err error
+		created, err = addDatasetRows(ctx, q, datasetID, rows)
+		return err
+	})
+
+	return created, err
+}
+
+// DeleteDataset deletes a dataset of the context's workspace and its rows.
+// Datasets that eval runs refer to are kept and ErrDatasetInUse is returned.
+func (s *Store) DeleteDataset(ctx context.Context, id string) error {
+	return s.executeTx(ctx, func(q *sqlc.Queries) error {
+		if err := checkDataset(ctx, q, id); err != nil {
+			return err
+		}
+
+		runs, err := q.CountEvalRunsByDataset(ctx, id)
+		if err != nil {
+			return err
+		}
+		if runs > 0 {
+			return ErrDatasetInUse
+		}
+
+		if err := q.DeleteDatasetRows(ctx, id); err != nil {
+			return err
+		}
+		return q.DeleteDataset(ctx, id)
+	})
+}
+
+// RecordEvalRunResult stores the result of one eval run row together with
+// the scorer evaluations of its output
+func (s *Store) RecordEvalRunResult(ctx context.Context, arg sqlc.CreateEvalRunResultParams, evals []sqlc.CreateEvaluationParams) (sqlc.EvalRunResult, error) {
+	var result sqlc.EvalRunResult
+
+	err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+		if err := checkEvalRun(ctx, q, arg.EvalRunID); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = q.CreateEvalRunResult(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		for _, eval := range evals {
+			if err := checkVersion(ctx, q, eval.PromptVersionID.String); err != nil {
+				return err
+			}
+			eval.EvalRunResultID = sql.NullInt64{Int64: result.ID, Valid: true}
+			if _, err := q.CreateEvaluation(ctx, eval); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// FailUnfinishedEvalRuns marks the pending and running eval runs of every
+// workspace as failed. It is meant for startup, when no eval run can still
+// be running.
+func (s *Store) FailUnfinishedEvalRuns(ctx context.Context, reason sql.NullString) (int64, error) {
+	return s.queries.FailUnfinishedEvalRuns(ctx, reason)
+}
+
+// CreateEvaluations stores several evaluations of versions of prompts of
+// the context's workspace at once
+func (s *Store) CreateEvaluations(ctx context.Context, evals []sqlc.CreateEvaluationParams) ([]sqlc.Evaluation, error) {
+	created := make([]sqlc.Evaluation, 0, len(evals))
+
+	err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+		for _, eval := range evals {
+			if err := checkVersion(ctx, q, eval.PromptVersionID.String); err != nil {
+				return err
+			}
+			e, err := q.CreateEvaluation(ctx, eval)
+			if err != nil {
+				return err
+			}
+			created = append(created, e)
+		}
+		return nil
+	})
+
+	return created, err
+}
+
+// addDatasetRows inserts rows after the dataset's last row using q
+func addDatasetRows(ctx context.Context, q *sqlc.Queries, datasetID string, rows []sqlc.CreateDatasetRowParams) ([]sqlc.DatasetRow, error) {
+	position, err := q.GetLastDatasetRowPosition(ctx, datasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]sqlc.DatasetRow, 0, len(rows))
+	for _, row := range rows {
+		position++
+		row.DatasetID = datasetID
+		row.Position = position
+
+		r, err := q.CreateDatasetRow(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, r)
+	}
+
+	return created, nil
+}
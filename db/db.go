@@ -6,22 +6,34 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
 	_ "modernc.org/sqlite"
 )
 
-// Store provides all functions to execute database queries and transactions
+// Store provides all functions to execute database queries and
+// transactions. The generated queries are not exposed: Store's methods
+// scope them to the context's workspace, and the few that span every
+// workspace say so.
 type Store struct {
-	*sqlc.Queries
-	db *sql.DB
+	queries *sqlc.Queries
+	db      *sql.DB
+
+	// inTx is set on the Store ExecuteTx passes to its callback; its
+	// transactions join the one already open
+	inTx bool
+
+	// versionMu serializes version number allocation
+	versionMu sync.Mutex
 }
 
 // NewStore creates a new store with the given database connection
 func NewStore(db *sql.DB) *Store {
 	return &Store{
 		db:      db,
-		Queries: sqlc.New(db),
+		queries: sqlc.New(db),
 	}
 }
 
@@ -39,7 +51,7 @@ func Connect(dataSourceName string) (*sql.DB, error) {
 		}
 	}
 
-	db, err := sql.Open("sqlite", dataSourceName)
+	db, err := sql.Open("sqlite", withBusyTimeout(dataSourceName))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open db connection: %w", err)
 	}
@@ -60,6 +72,20 @@ func DefaultDataSourceName() string {
 	return dsn
 }
 
+// withBusyTimeout makes every pooled connection wait for locks held by
+// other connections instead of failing with SQLITE_BUSY, unless the DSN
+// already sets a busy timeout
+func withBusyTimeout(dsn string) string {
+	if strings.Contains(dsn, "busy_timeout") {
+		return dsn
+	}
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return dsn + sep + "_pragma=busy_timeout(5000)"
+}
+
 // isURIScheme checks if the string starts with a URI scheme
 func isURIScheme(s string) bool {
 	for i := 0; i < len(s); i++ {
@@ -73,8 +99,21 @@ func isURIScheme(s string) bool {
 	return false
 }
 
-// ExecuteTx executes a function within a database transaction
-func (s *Store) ExecuteTx(ctx context.Context, fn func(*sqlc.Queries) error) error {
+// ExecuteTx runs fn within a database transaction. The Store fn gets is
+// bound to the transaction and scopes its calls like any other Store.
+func (s *Store) ExecuteTx(ctx context.Context, fn func(*Store) error) error {
+	return s.executeTx(ctx, func(q *sqlc.Queries) error {
+		return fn(&Store{queries: q, inTx: true})
+	})
+}
+
+// executeTx runs fn within a database transaction using the generated
+// queries
+func (s *Store) executeTx(ctx context.Context, fn func(*sqlc.Queries) error) error {
+	if s.inTx {
+		return fn(s.queries)
+	}
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
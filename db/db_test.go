@@ -32,7 +32,7 @@ func TestMigrateDB(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify tables exist
-	tables := []string{"users", "prompts", "prompt_versions", "comments", "evaluations"}
+	tables := []string{"users", "prompts", "prompt_versions", "comments", "evaluations", "runs"}
 
 	for _, table := range tables {
 		// Check if table exists by running a simple query
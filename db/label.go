@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+)
+
+// ErrNothingToRollback is returned when a label has no earlier version
+var ErrNothingToRollback = errors.New("label has no previous version")
+
+// MoveLabelParams contains the input parameters of MoveLabel
+type MoveLabelParams struct {
+	PromptID string
+	Name     string
+	Version  int64
+	MovedBy  sql.NullString
+}
+
+// MoveLabel points a label at a version, creating it if needed, and
+// records the move in the label history. It returns sql.ErrNoRows unless
+// the prompt is in the context's workspace.
+func (s *Store) MoveLabel(ctx context.Context, arg MoveLabelParams) (sqlc.PromptLabel, error) {
+	var label sqlc.PromptLabel
+
+	err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+		if err := checkPrompt(ctx, q, arg.PromptID); err != nil {
+			return err
+		}
+		var err error
+		label, err = moveLabel(ctx, q, arg)
+		return err
+	})
+
+	return label, err
+}
+
+// DeleteLabel removes a label and records the delete in the label history.
+// Labels of prompts outside the context's workspace are not found.
+func (s *Store) DeleteLabel(ctx context.Context, promptID, name string, deletedBy sql.NullString) error {
+	return s.executeTx(ctx, func(q *sqlc.Queries) error {
+		if err := checkPrompt(ctx, q, promptID); err != nil {
+			return err
+		}
+		current, err := q.GetLabel(ctx, sqlc.GetLabelParams{PromptID: promptID, Name: name})
+		if err != nil {
+			return err
+		}
+		if err := q.DeleteLabel(ctx, sqlc.DeleteLabelParams{PromptID: promptID, Name: name}); err != nil {
+			return err
+		}
+
+		_, err = q.CreateLabelEvent(ctx, sqlc.CreateLabelEventParams{
+			PromptID:        promptID,
+			Name:            name,
+			PreviousVersion: sql.NullInt64{Int64: current.Version, Valid: true},
+			MovedBy:         deletedBy,
+		})
+		return err
+	})
+}
+
+// RollbackLabel moves a label back to the version it pointed at before its
+// most recent move, which restores a deleted label. Labels of prompts
+// outside the context's workspace are not found.
+func (s *Store) RollbackLabel(ctx context.Context, promptID, name string, movedBy sql.NullString) (sqlc.PromptLabel, error) {
+	var label sqlc.PromptLabel
+
+	err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+		if err := checkPrompt(ctx, q, promptID); err != nil {
+			return err
+		}
+		event, err := q.GetLatestLabelEvent(ctx, sqlc.GetLatestLabelEventParams{PromptID: promptID, Name: name})
+		if err != nil {
+			return err
+		}
+		if !event.PreviousVersion.Valid {
+			return ErrNothingToRollback
+		}
+
+		label, err = moveLabel(ctx, q, MoveLabelParams{
+			PromptID: promptID,
+			Name:     name,
+			Version:  event.PreviousVersion.Int64,
+			MovedBy:  movedBy,
+		})
+		return err
+	})
+
+	return label, err
+}
+
+// moveLabel upserts the label and appends a history entry using q
+func moveLabel(ctx context.Context, q *sqlc.Queries, arg MoveLabelParams) (sqlc.PromptLabel, error) {
+	var previous sql.NullInt64
+	current, err := q.GetLabel(ctx, sqlc.GetLabelParams{PromptID: arg.PromptID, Name: arg.Name})
+	switch {
+	case err == nil:
+		previous = sql.NullInt64{Int64: current.Version, Valid: true}
+	case !errors.Is(err, sql.ErrNoRows):
+		return sqlc.PromptLabel{}, err
+	}
+
+	label, err := q.UpsertLabel(ctx, sqlc.UpsertLabelParams{
+		PromptID:  arg.PromptID,
+		Name:      arg.Name,
+		Version:   arg.Version,
+		UpdatedBy: arg.MovedBy,
+	})
+	if err != nil {
+		return sqlc.PromptLabel{}, err
+	}
+
+	_, err = q.CreateLabelEvent(ctx, sqlc.CreateLabelEventParams{
+		PromptID:        arg.PromptID,
+		Name:            arg.Name,
+		Version:         sql.NullInt64{Int64: arg.Version, Valid: true},
+		PreviousVersion: previous,
+		MovedBy:         arg.MovedBy,
+	})
+	if err != nil {
+		return sqlc.PromptLabel{}, err
+	}
+
+	return label, nil
+}
@@ -0,0 +1,231 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+)
+
+// Sort fields accepted by the paginated list methods
+const (
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+	SortTitle     = "title"
+	SortVersion   = "version"
+)
+
+// timestampLayout is how SQLite's CURRENT_TIMESTAMP stores times, so
+// cursor keys compare the same way as the stored columns
+const timestampLayout = "2006-01-02 15:04:05"
+
+// ListFilter narrows a paginated list. Invalid or empty fields do not
+// filter.
+type ListFilter struct {
+	CreatedBy     sql.NullString
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	// The remaining filters only apply to prompts
+	UpdatedAfter  sql.NullTime
+	UpdatedBefore sql.NullTime
+	TitlePrefix   string
+	CollectionID  sql.NullString
+	Tag           sql.NullString
+}
+
+// Cursor identifies the last row of a page by its sort key and ID
+type Cursor struct {
+	Key string
+	ID  string
+}
+
+// PageParams selects one page of a sorted list
+type PageParams struct {
+	Sort  string
+	Desc  bool
+	After *Cursor
+	Limit int64
+}
+
+// cursorArgs converts the page cursor into query arguments
+func (p PageParams) cursorArgs() (key, id sql.NullString) {
+	if p.After == nil {
+		return key, id
+	}
+	return sql.NullString{String: p.After.Key, Valid: true}, sql.NullString{String: p.After.ID, Valid: true}
+}
+
+// ListPromptsPage returns one page of the live prompts of the context's
+// workspace sorted by creation time, update time or title, and the cursor
+// of the next page if any
+func (s *Store) ListPromptsPage(ctx context.Context, filter ListFilter, page PageParams) ([]sqlc.Prompt, *Cursor, error) {
+	cursorKey, cursorID := page.cursorArgs()
+	arg := sqlc.ListPromptsPageByCreatedAscParams{
+		WorkspaceID:   WorkspaceFromContext(ctx),
+		CreatedBy:     filter.CreatedBy,
+		CreatedAfter:  filter.CreatedAfter,
+		CreatedBefore: filter.CreatedBefore,
+		UpdatedAfter:  filter.UpdatedAfter,
+		UpdatedBefore: filter.UpdatedBefore,
+		TitlePrefix:   likePrefix(filter.TitlePrefix),
+		CollectionID:  filter.CollectionID,
+		Tag:           filter.Tag,
+		CursorKey:     cursorKey,
+		CursorID:      cursorID,
+		Limit:         page.Limit + 1,
+	}
+
+	var prompts []sqlc.Prompt
+	var err error
+	switch {
+	case page.Sort == SortUpdatedAt && page.Desc:
+		prompts, err = s.queries.ListPromptsPageByUpdatedDesc(ctx, sqlc.ListPromptsPageByUpdatedDescParams(arg))
+	case page.Sort == SortUpdatedAt:
+		prompts, err = s.queries.ListPromptsPageByUpdatedAsc(ctx, sqlc.ListPromptsPageByUpdatedAscParams(arg))
+	case page.Sort == SortTitle && page.Desc:
+		prompts, err = s.queries.ListPromptsPageByTitleDesc(ctx, sqlc.ListPromptsPageByTitleDescParams(arg))
+	case page.Sort == SortTitle:
+		prompts, err = s.queries.ListPromptsPageByTitleAsc(ctx, sqlc.ListPromptsPageByTitleAscParams(arg))
+	case page.Desc:
+		prompts, err = s.queries.ListPromptsPageByCreatedDesc(ctx, sqlc.ListPromptsPageByCreatedDescParams(arg))
+	default:
+		prompts, err = s.queries.ListPromptsPageByCreatedAsc(ctx, arg)
+	}
+	if err != nil || int64(len(prompts)) <= page.Limit {
+		return prompts, nil, err
+	}
+
+	prompts = prompts[:page.Limit]
+	last := prompts[len(prompts)-1]
+	next := &Cursor{Key: formatTimestamp(last.CreatedAt), ID: last.ID}
+	switch page.Sort {
+	case SortUpdatedAt:
+		next.Key = formatTimestamp(last.UpdatedAt)
+	case SortTitle:
+		next.Key = last.Title
+	}
+	return prompts, next, nil
+}
+
+// ListVersionsPage returns one page of a prompt's versions sorted by
+// version number, and the cursor of the next page if any
+func (s *Store) ListVersionsPage(ctx context.Context, promptID string, filter ListFilter, page PageParams) ([]sqlc.PromptVersion, *Cursor, error) {
+	if visible, err := promptVisible(ctx, s.queries, promptID); !visible {
+		return nil, nil, err
+	}
+	arg := sqlc.ListVersionsPageAscParams{
+		PromptID:      sql.NullString{String: promptID, Valid: true},
+		CreatedBy:     filter.CreatedBy,
+		CreatedAfter:  filter.CreatedAfter,
+		CreatedBefore: filter.CreatedBefore,
+		Limit:         page.Limit + 1,
+	}
+	if page.After != nil {
+		number, err := strconv.ParseInt(page.After.Key, 10, 64)
+		if err != nil {
+			return nil, nil, err
+		}
+		arg.CursorVersion = sql.NullInt64{Int64: number, Valid: true}
+	}
+
+	var versions []sqlc.PromptVersion
+	var err error
+	if page.Desc {
+		versions, err = s.queries.ListVersionsPageDesc(ctx, sqlc.ListVersionsPageDescParams(arg))
+	} else {
+		versions, err = s.queries.ListVersionsPageAsc(ctx, arg)
+	}
+	if err != nil || int64(len(versions)) <= page.Limit {
+		return versions, nil, err
+	}
+
+	versions = versions[:page.Limit]
+	last := versions[len(versions)-1]
+	return versions, &Cursor{Key: strconv.FormatInt(last.Version, 10), ID: last.ID}, nil
+}
+
+// ListCommentsPage returns one page of a prompt's comments sorted by
+// creation time, and the cursor of the next page if any
+func (s *Store) ListCommentsPage(ctx context.Context, promptID string, filter ListFilter, page PageParams) ([]sqlc.Comment, *Cursor, error) {
+	if visible, err := promptVisible(ctx, s.queries, promptID); !visible {
+		return nil, nil, err
+	}
+	cursorKey, cursorID := page.cursorArgs()
+	arg := sqlc.ListCommentsPageAscParams{
+		PromptID:      sql.NullString{String: promptID, Valid: true},
+		CreatedBy:     filter.CreatedBy,
+		CreatedAfter:  filter.CreatedAfter,
+		CreatedBefore: filter.CreatedBefore,
+		CursorKey:     cursorKey,
+		CursorID:      cursorID,
+		Limit:         page.Limit + 1,
+	}
+
+	var comments []sqlc.Comment
+	var err error
+	if page.Desc {
+		comments, err = s.queries.ListCommentsPageDesc(ctx, sqlc.ListCommentsPageDescParams(arg))
+	} else {
+		comments, err = s.queries.ListCommentsPageAsc(ctx, arg)
+	}
+	if err != nil || int64(len(comments)) <= page.Limit {
+		return comments, nil, err
+	}
+
+	comments = comments[:page.Limit]
+	last := comments[len(comments)-1]
+	return comments, &Cursor{Key: formatTimestamp(last.CreatedAt), ID: last.ID}, nil
+}
+
+// ListEvaluationsPage returns one page of a version's evaluations sorted
+// by creation time, and the cursor of the next page if any
+func (s *Store) ListEvaluationsPage(ctx context.Context, versionID string, filter ListFilter, page PageParams) ([]sqlc.Evaluation, *Cursor, error) {
+	if visible, err := versionVisible(ctx, s.queries, versionID); !visible {
+		return nil, nil, err
+	}
+	cursorKey, cursorID := page.cursorArgs()
+	arg := sqlc.ListEvaluationsPageAscParams{
+		PromptVersionID: sql.NullString{String: versionID, Valid: true},
+		CreatedBy:       filter.CreatedBy,
+		CreatedAfter:    filter.CreatedAfter,
+		CreatedBefore:   filter.CreatedBefore,
+		CursorKey:       cursorKey,
+		CursorID:        cursorID,
+		Limit:           page.Limit + 1,
+	}
+
+	var evals []sqlc.Evaluation
+	var err error
+	if page.Desc {
+		evals, err = s.queries.ListEvaluationsPageDesc(ctx, sqlc.ListEvaluationsPageDescParams(arg))
+	} else {
+		evals, err = s.queries.ListEvaluationsPageAsc(ctx, arg)
+	}
+	if err != nil || int64(len(evals)) <= page.Limit {
+		return evals, nil, err
+	}
+
+	evals = evals[:page.Limit]
+	last := evals[len(evals)-1]
+	return evals, &Cursor{Key: formatTimestamp(last.CreatedAt), ID: last.ID}, nil
+}
+
+// formatTimestamp renders a scanned timestamp the way SQLite stored it
+func formatTimestamp(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(timestampLayout)
+}
+
+// likePrefix escapes LIKE wildcards in a prefix filter. The queries
+// append the trailing % and use \ as the escape character.
+func likePrefix(prefix string) sql.NullString {
+	if prefix == "" {
+		return sql.NullString{}
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix)
+	return sql.NullString{String: escaped, Valid: true}
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_runs_prompt;
+DROP INDEX IF EXISTS idx_runs_prompt_version;
+DROP TABLE IF EXISTS runs;
@@ -0,0 +1,20 @@
+-- Create runs table
+CREATE TABLE IF NOT EXISTS runs (
+    id TEXT PRIMARY KEY,
+    prompt_id TEXT REFERENCES prompts(id),
+    prompt_version_id TEXT REFERENCES prompt_versions(id),
+    model TEXT NOT NULL,
+    parameters TEXT NOT NULL,
+    messages TEXT NOT NULL,
+    response TEXT,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_by TEXT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_runs_prompt_version ON runs(prompt_version_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_runs_prompt ON runs(prompt_id, created_at);
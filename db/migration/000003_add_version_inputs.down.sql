@@ -0,0 +1 @@
+ALTER TABLE prompt_versions DROP COLUMN inputs;
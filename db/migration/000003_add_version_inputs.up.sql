@@ -0,0 +1,2 @@
+-- Typed declarations of the template variables used by a version
+ALTER TABLE prompt_versions ADD COLUMN inputs TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_prompt_label_history_label;
+DROP TABLE IF EXISTS prompt_label_history;
+DROP TABLE IF EXISTS prompt_labels;
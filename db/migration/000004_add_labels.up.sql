@@ -0,0 +1,23 @@
+-- Create prompt_labels table
+CREATE TABLE IF NOT EXISTS prompt_labels (
+    prompt_id TEXT NOT NULL REFERENCES prompts(id),
+    name TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    updated_by TEXT REFERENCES users(id),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (prompt_id, name),
+    FOREIGN KEY (prompt_id, version) REFERENCES prompt_versions(prompt_id, version)
+);
+
+-- Create prompt_label_history table
+CREATE TABLE IF NOT EXISTS prompt_label_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    prompt_id TEXT NOT NULL REFERENCES prompts(id),
+    name TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    previous_version INTEGER,
+    moved_by TEXT REFERENCES users(id),
+    moved_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_prompt_label_history_label ON prompt_label_history(prompt_id, name, id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS prompt_slug_redirects;
+DROP INDEX IF EXISTS idx_prompts_namespace_slug;
+ALTER TABLE prompts DROP COLUMN namespace;
+ALTER TABLE prompts DROP COLUMN slug;
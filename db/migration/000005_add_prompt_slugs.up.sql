@@ -0,0 +1,17 @@
+-- Human-readable prompt identifiers, unique within a namespace
+ALTER TABLE prompts ADD COLUMN slug TEXT NOT NULL DEFAULT '';
+ALTER TABLE prompts ADD COLUMN namespace TEXT NOT NULL DEFAULT '';
+
+-- Existing prompts keep their ID as slug until renamed
+UPDATE prompts SET slug = id WHERE slug = '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prompts_namespace_slug ON prompts(namespace, slug) WHERE slug <> '';
+
+-- Old slugs keep resolving to their prompt after a rename
+CREATE TABLE IF NOT EXISTS prompt_slug_redirects (
+    namespace TEXT NOT NULL,
+    slug TEXT NOT NULL,
+    prompt_id TEXT NOT NULL REFERENCES prompts(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (namespace, slug)
+);
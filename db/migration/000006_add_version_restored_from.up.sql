@@ -0,0 +1,2 @@
+-- Version number a restored version was copied from
+ALTER TABLE prompt_versions ADD COLUMN restored_from INTEGER;
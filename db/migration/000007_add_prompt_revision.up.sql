@@ -0,0 +1,2 @@
+-- Incremented on every change to a prompt or its versions, for ETags
+ALTER TABLE prompts ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_prompts_deleted_at;
+
+-- Tombstoned prompts would reappear as live ones
+DELETE FROM prompts WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE prompts DROP COLUMN deleted_at;
@@ -0,0 +1,4 @@
+-- Soft-deleted prompts keep their children until they are purged
+ALTER TABLE prompts ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_prompts_deleted_at ON prompts(deleted_at);
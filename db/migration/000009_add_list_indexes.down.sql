@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_evaluations_version_created;
+DROP INDEX IF EXISTS idx_comments_prompt_created;
+DROP INDEX IF EXISTS idx_prompts_title;
+DROP INDEX IF EXISTS idx_prompts_updated_at;
+DROP INDEX IF EXISTS idx_prompts_created_at;
@@ -0,0 +1,7 @@
+-- Keyset pagination indexes for the list endpoints. Prompt indexes only
+-- cover live prompts since soft-deleted ones are never listed.
+CREATE INDEX IF NOT EXISTS idx_prompts_created_at ON prompts(created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_prompts_updated_at ON prompts(updated_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_prompts_title ON prompts(title, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_prompt_created ON comments(prompt_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_evaluations_version_created ON evaluations(prompt_version_id, created_at, id);
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS prompt_versions_fts_delete;
+DROP TRIGGER IF EXISTS prompt_versions_fts_insert;
+DROP TRIGGER IF EXISTS prompts_fts_delete;
+DROP TRIGGER IF EXISTS prompts_fts_update;
+DROP TRIGGER IF EXISTS prompts_fts_insert;
+DROP VIEW IF EXISTS prompt_search_content;
+DROP TABLE IF EXISTS prompts_fts;
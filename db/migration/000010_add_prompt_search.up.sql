@@ -0,0 +1,50 @@
+-- Full-text index over each prompt's title, description and the message
+-- text of its latest version
+CREATE VIRTUAL TABLE IF NOT EXISTS prompts_fts USING fts5(
+    prompt_id UNINDEXED,
+    title,
+    description,
+    content,
+    tokenize = 'porter unicode61'
+);
+
+-- Message contents of each prompt's latest version, one message per line
+CREATE VIEW IF NOT EXISTS prompt_search_content AS
+SELECT v.prompt_id, group_concat(json_extract(m.value, '$.content'), char(10)) AS content
+FROM prompt_versions v,
+     json_each(CASE WHEN json_valid(v.content) THEN v.content ELSE '[]' END) m
+WHERE m.type = 'object'
+  AND v.version = (SELECT MAX(version) FROM prompt_versions WHERE prompt_id = v.prompt_id)
+GROUP BY v.prompt_id;
+
+INSERT INTO prompts_fts (prompt_id, title, description, content)
+SELECT p.id, p.title, COALESCE(p.description, ''), COALESCE(s.content, '')
+FROM prompts p
+LEFT JOIN prompt_search_content s ON s.prompt_id = p.id;
+
+CREATE TRIGGER IF NOT EXISTS prompts_fts_insert AFTER INSERT ON prompts BEGIN
+    INSERT INTO prompts_fts (prompt_id, title, description, content)
+    VALUES (NEW.id, NEW.title, COALESCE(NEW.description, ''), '');
+END;
+
+CREATE TRIGGER IF NOT EXISTS prompts_fts_update AFTER UPDATE OF title, description ON prompts BEGIN
+    UPDATE prompts_fts
+    SET title = NEW.title, description = COALESCE(NEW.description, '')
+    WHERE prompt_id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS prompts_fts_delete AFTER DELETE ON prompts BEGIN
+    DELETE FROM prompts_fts WHERE prompt_id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS prompt_versions_fts_insert AFTER INSERT ON prompt_versions BEGIN
+    UPDATE prompts_fts
+    SET content = COALESCE((SELECT content FROM prompt_search_content WHERE prompt_id = NEW.prompt_id), '')
+    WHERE prompt_id = NEW.prompt_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS prompt_versions_fts_delete AFTER DELETE ON prompt_versions BEGIN
+    UPDATE prompts_fts
+    SET content = COALESCE((SELECT content FROM prompt_search_content WHERE prompt_id = OLD.prompt_id), '')
+    WHERE prompt_id = OLD.prompt_id;
+END;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_prompts_collection;
+ALTER TABLE prompts DROP COLUMN collection_id;
+DROP INDEX IF EXISTS idx_collections_parent_name;
+DROP TABLE IF EXISTS collections;
+DROP INDEX IF EXISTS idx_prompt_tags_tag;
+DROP TABLE IF EXISTS prompt_tags;
+DROP TABLE IF EXISTS tags;
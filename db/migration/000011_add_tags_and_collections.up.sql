@@ -0,0 +1,32 @@
+-- Create tags table
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create prompt_tags table
+CREATE TABLE IF NOT EXISTS prompt_tags (
+    prompt_id TEXT NOT NULL REFERENCES prompts(id),
+    tag_id INTEGER NOT NULL REFERENCES tags(id),
+    PRIMARY KEY (prompt_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_prompt_tags_tag ON prompt_tags(tag_id);
+
+-- Collections are folders that can be nested; a prompt lives in at most one
+CREATE TABLE IF NOT EXISTS collections (
+    id TEXT PRIMARY KEY,
+    parent_id TEXT REFERENCES collections(id),
+    name TEXT NOT NULL,
+    description TEXT,
+    created_by TEXT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_parent_name ON collections(COALESCE(parent_id, ''), name);
+
+ALTER TABLE prompts ADD COLUMN collection_id TEXT REFERENCES collections(id);
+
+CREATE INDEX IF NOT EXISTS idx_prompts_collection ON prompts(collection_id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS eval_run_results;
+DROP INDEX IF EXISTS idx_eval_runs_dataset;
+DROP INDEX IF EXISTS idx_eval_runs_version;
+DROP TABLE IF EXISTS eval_runs;
+DROP TABLE IF EXISTS dataset_rows;
+DROP TABLE IF EXISTS datasets;
@@ -0,0 +1,55 @@
+-- Create datasets table
+CREATE TABLE IF NOT EXISTS datasets (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    created_by TEXT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Each row holds the input variables as a JSON object and, optionally,
+-- the output a prompt is expected to produce for them
+CREATE TABLE IF NOT EXISTS dataset_rows (
+    id TEXT PRIMARY KEY,
+    dataset_id TEXT NOT NULL REFERENCES datasets(id),
+    position INTEGER NOT NULL,
+    variables TEXT NOT NULL,
+    expected TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(dataset_id, position)
+);
+
+-- An eval run executes a prompt version against every row of a dataset.
+-- Status moves from pending to running to completed or failed.
+CREATE TABLE IF NOT EXISTS eval_runs (
+    id TEXT PRIMARY KEY,
+    prompt_id TEXT NOT NULL REFERENCES prompts(id),
+    prompt_version_id TEXT NOT NULL REFERENCES prompt_versions(id),
+    dataset_id TEXT NOT NULL REFERENCES datasets(id),
+    model TEXT NOT NULL,
+    parameters TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_by TEXT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_eval_runs_version ON eval_runs(prompt_version_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_eval_runs_dataset ON eval_runs(dataset_id);
+
+-- Create eval_run_results table
+CREATE TABLE IF NOT EXISTS eval_run_results (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    eval_run_id TEXT NOT NULL REFERENCES eval_runs(id),
+    dataset_row_id TEXT NOT NULL REFERENCES dataset_rows(id),
+    run_id TEXT REFERENCES runs(id),
+    output TEXT,
+    score REAL,
+    error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(eval_run_id, dataset_row_id)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE eval_runs DROP COLUMN scorers;
+DROP INDEX IF EXISTS idx_evaluations_eval_run_result;
+ALTER TABLE evaluations DROP COLUMN eval_run_result_id;
+ALTER TABLE evaluations DROP COLUMN passed;
+ALTER TABLE evaluations DROP COLUMN scorer;
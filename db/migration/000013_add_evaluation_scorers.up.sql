@@ -0,0 +1,10 @@
+-- Evaluations record which scorer produced them. Scores entered by hand
+-- use the "manual" scorer.
+ALTER TABLE evaluations ADD COLUMN scorer TEXT NOT NULL DEFAULT 'manual';
+ALTER TABLE evaluations ADD COLUMN passed BOOLEAN;
+ALTER TABLE evaluations ADD COLUMN eval_run_result_id INTEGER REFERENCES eval_run_results(id);
+
+CREATE INDEX IF NOT EXISTS idx_evaluations_eval_run_result ON evaluations(eval_run_result_id);
+
+-- Eval runs keep the scorer specs they were started with
+ALTER TABLE eval_runs ADD COLUMN scorers TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_evaluations_judge_version;
+ALTER TABLE evaluations DROP COLUMN judge_run_id;
+ALTER TABLE evaluations DROP COLUMN judge_prompt_version_id;
@@ -0,0 +1,6 @@
+-- Evaluations by a judge scorer record the rubric prompt version it graded
+-- with and the run holding the judge's reply
+ALTER TABLE evaluations ADD COLUMN judge_prompt_version_id TEXT REFERENCES prompt_versions(id);
+ALTER TABLE evaluations ADD COLUMN judge_run_id TEXT REFERENCES runs(id);
+
+CREATE INDEX IF NOT EXISTS idx_evaluations_judge_version ON evaluations(judge_prompt_version_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pairwise_judgments_results;
+DROP INDEX IF EXISTS idx_pairwise_judgments_prompt;
+DROP TABLE IF EXISTS pairwise_judgments;
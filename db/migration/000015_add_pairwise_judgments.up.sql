@@ -0,0 +1,18 @@
+-- A pairwise judgment records which of two outputs for the same dataset
+-- row a reviewer preferred. The outputs come from eval runs of two
+-- versions of the same prompt; winner is left, right or tie.
+CREATE TABLE IF NOT EXISTS pairwise_judgments (
+    id TEXT PRIMARY KEY,
+    prompt_id TEXT NOT NULL REFERENCES prompts(id),
+    left_result_id INTEGER NOT NULL REFERENCES eval_run_results(id),
+    right_result_id INTEGER NOT NULL REFERENCES eval_run_results(id),
+    left_version_id TEXT NOT NULL REFERENCES prompt_versions(id),
+    right_version_id TEXT NOT NULL REFERENCES prompt_versions(id),
+    winner TEXT NOT NULL CHECK (winner IN ('left', 'right', 'tie')),
+    notes TEXT,
+    created_by TEXT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pairwise_judgments_prompt ON pairwise_judgments(prompt_id);
+CREATE INDEX IF NOT EXISTS idx_pairwise_judgments_results ON pairwise_judgments(left_result_id, right_result_id);
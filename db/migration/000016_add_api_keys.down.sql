@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_api_keys_user_name;
+DROP INDEX IF EXISTS idx_api_keys_user;
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,32 @@
+-- Keys for other workspaces would otherwise unlock every prompt
+DELETE FROM api_keys WHERE workspace_id <> 'default';
+
+DROP INDEX IF EXISTS idx_api_keys_user_workspace_name;
+ALTER TABLE api_keys DROP COLUMN workspace_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_user_name ON api_keys(user_id, name) WHERE revoked_at IS NULL;
+
+CREATE TABLE prompt_slug_redirects_old (
+    namespace TEXT NOT NULL,
+    slug TEXT NOT NULL,
+    prompt_id TEXT NOT NULL REFERENCES prompts(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (namespace, slug)
+);
+
+INSERT OR IGNORE INTO prompt_slug_redirects_old (namespace, slug, prompt_id, created_at)
+SELECT namespace, slug, prompt_id, created_at FROM prompt_slug_redirects
+WHERE workspace_id = 'default';
+
+DROP TABLE prompt_slug_redirects;
+ALTER TABLE prompt_slug_redirects_old RENAME TO prompt_slug_redirects;
+
+DROP INDEX IF EXISTS idx_prompts_workspace_namespace_slug;
+DROP INDEX IF EXISTS idx_prompts_workspace;
+-- Slugs from other workspaces may collide once prompts share one space
+UPDATE prompts SET slug = '' WHERE workspace_id <> 'default';
+ALTER TABLE prompts DROP COLUMN workspace_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prompts_namespace_slug ON prompts(namespace, slug) WHERE slug <> '';
+
+DROP INDEX IF EXISTS idx_workspace_members_user;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
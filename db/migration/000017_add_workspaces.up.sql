@@ -0,0 +1,53 @@
+-- Workspaces partition prompts between teams. Everything that existed
+-- before workspaces moves into the default workspace.
+CREATE TABLE IF NOT EXISTS workspaces (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO workspaces (id, name) VALUES ('default', 'Default');
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id),
+    user_id TEXT NOT NULL REFERENCES users(id),
+    role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'member')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user ON workspace_members(user_id);
+
+INSERT INTO workspace_members (workspace_id, user_id, role)
+SELECT 'default', id, 'owner' FROM users;
+
+-- Versions, comments and evaluations belong to the workspace of their
+-- prompt
+ALTER TABLE prompts ADD COLUMN workspace_id TEXT NOT NULL DEFAULT 'default';
+
+CREATE INDEX IF NOT EXISTS idx_prompts_workspace ON prompts(workspace_id);
+
+-- Slugs are unique within a workspace
+DROP INDEX IF EXISTS idx_prompts_namespace_slug;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prompts_workspace_namespace_slug ON prompts(workspace_id, namespace, slug) WHERE slug <> '';
+
+CREATE TABLE prompt_slug_redirects_new (
+    workspace_id TEXT NOT NULL,
+    namespace TEXT NOT NULL,
+    slug TEXT NOT NULL,
+    prompt_id TEXT NOT NULL REFERENCES prompts(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workspace_id, namespace, slug)
+);
+
+INSERT INTO prompt_slug_redirects_new (workspace_id, namespace, slug, prompt_id, created_at)
+SELECT 'default', namespace, slug, prompt_id, created_at FROM prompt_slug_redirects;
+
+DROP TABLE prompt_slug_redirects;
+ALTER TABLE prompt_slug_redirects_new RENAME TO prompt_slug_redirects;
+
+-- An API key signs its user in to one workspace
+ALTER TABLE api_keys ADD COLUMN workspace_id TEXT NOT NULL DEFAULT 'default';
+
+DROP INDEX IF EXISTS idx_api_keys_user_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_user_workspace_name ON api_keys(user_id, workspace_id, name) WHERE revoked_at IS NULL;
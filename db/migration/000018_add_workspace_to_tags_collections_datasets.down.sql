@@ -0,0 +1,46 @@
+-- Names must be unique across workspaces again, so names from other
+-- workspaces are suffixed with their workspace. Copies of tags are merged
+-- back into one tag per name.
+CREATE TABLE datasets_old (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    created_by TEXT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO datasets_old (id, name, description, created_by, created_at, updated_at)
+SELECT id, CASE WHEN workspace_id = 'default' THEN name ELSE name || ' (' || workspace_id || ')' END,
+       description, created_by, created_at, updated_at
+FROM datasets;
+
+DROP TABLE datasets;
+ALTER TABLE datasets_old RENAME TO datasets;
+
+DROP INDEX IF EXISTS idx_collections_workspace_parent_name;
+UPDATE collections
+SET name = name || ' (' || workspace_id || ')'
+WHERE workspace_id <> 'default' AND parent_id IS NULL;
+ALTER TABLE collections DROP COLUMN workspace_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_parent_name ON collections(COALESCE(parent_id, ''), name);
+
+UPDATE prompt_tags
+SET tag_id = (
+    SELECT MIN(other.id) FROM tags other
+    JOIN tags t ON t.name = other.name
+    WHERE t.id = prompt_tags.tag_id
+);
+
+CREATE TABLE tags_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO tags_old (id, name, created_at)
+SELECT MIN(id), name, MIN(created_at) FROM tags
+GROUP BY name;
+
+DROP TABLE tags;
+ALTER TABLE tags_old RENAME TO tags;
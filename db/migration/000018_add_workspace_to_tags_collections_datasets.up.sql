@@ -0,0 +1,134 @@
+-- Tags, collections and datasets belong to a workspace and their names
+-- only need to be unique within it. Each existing one moves to the
+-- workspace of the prompts using it, and one used by several workspaces is
+-- copied into each of them.
+
+-- Tags are rebuilt to replace the global UNIQUE constraint on name
+CREATE TABLE tags_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    workspace_id TEXT NOT NULL DEFAULT 'default',
+    UNIQUE (workspace_id, name)
+);
+
+INSERT INTO tags_new (id, name, created_at, workspace_id)
+SELECT t.id, t.name, t.created_at, COALESCE((
+    SELECT MIN(p.workspace_id) FROM prompt_tags pt
+    JOIN prompts p ON p.id = pt.prompt_id
+    WHERE pt.tag_id = t.id
+), 'default')
+FROM tags t;
+
+INSERT INTO tags_new (name, created_at, workspace_id)
+SELECT DISTINCT t.name, t.created_at, p.workspace_id
+FROM tags t
+JOIN tags_new n ON n.id = t.id
+JOIN prompt_tags pt ON pt.tag_id = t.id
+JOIN prompts p ON p.id = pt.prompt_id
+WHERE p.workspace_id <> n.workspace_id;
+
+UPDATE prompt_tags
+SET tag_id = (
+    SELECT copy.id FROM tags_new copy
+    JOIN tags_new n ON n.name = copy.name
+    JOIN prompts p ON p.workspace_id = copy.workspace_id
+    WHERE n.id = prompt_tags.tag_id AND p.id = prompt_tags.prompt_id
+)
+WHERE EXISTS (
+    SELECT 1 FROM tags_new n
+    JOIN prompts p ON p.id = prompt_tags.prompt_id
+    WHERE n.id = prompt_tags.tag_id AND n.workspace_id <> p.workspace_id
+);
+
+DROP TABLE tags;
+ALTER TABLE tags_new RENAME TO tags;
+
+-- A collection tree belongs to the workspace of the prompts filed under its
+-- root. Prompts of other workspaces are taken out of it.
+ALTER TABLE collections ADD COLUMN workspace_id TEXT NOT NULL DEFAULT 'default';
+
+CREATE TEMP TABLE collection_roots AS
+WITH RECURSIVE tree(id, root_id) AS (
+    SELECT id, id FROM collections WHERE parent_id IS NULL
+    UNION ALL
+    SELECT c.id, t.root_id FROM collections c
+    JOIN tree t ON c.parent_id = t.id
+)
+SELECT id, root_id FROM tree;
+
+UPDATE collections
+SET workspace_id = COALESCE((
+    SELECT MIN(p.workspace_id) FROM prompts p
+    JOIN collection_roots r ON r.id = p.collection_id
+    WHERE r.root_id = (SELECT root_id FROM collection_roots WHERE id = collections.id)
+), 'default');
+
+DROP TABLE collection_roots;
+
+UPDATE prompts
+SET collection_id = NULL
+WHERE EXISTS (
+    SELECT 1 FROM collections c
+    WHERE c.id = prompts.collection_id AND c.workspace_id <> prompts.workspace_id
+);
+
+DROP INDEX IF EXISTS idx_collections_parent_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_workspace_parent_name ON collections(workspace_id, COALESCE(parent_id, ''), name);
+
+-- Datasets are rebuilt to replace the global UNIQUE constraint on name.
+-- A dataset belongs to the workspace of the prompts evaluated against it.
+CREATE TABLE datasets_new (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT,
+    created_by TEXT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    workspace_id TEXT NOT NULL DEFAULT 'default',
+    UNIQUE (workspace_id, name)
+);
+
+INSERT INTO datasets_new (id, name, description, created_by, created_at, updated_at, workspace_id)
+SELECT d.id, d.name, d.description, d.created_by, d.created_at, d.updated_at, COALESCE((
+    SELECT MIN(p.workspace_id) FROM eval_runs r
+    JOIN prompts p ON p.id = r.prompt_id
+    WHERE r.dataset_id = d.id
+), 'default')
+FROM datasets d;
+
+-- Copies keep the ID of the original with the workspace appended
+INSERT INTO datasets_new (id, name, description, created_by, created_at, updated_at, workspace_id)
+SELECT DISTINCT d.id || '-' || p.workspace_id, d.name, d.description, d.created_by, d.created_at, d.updated_at, p.workspace_id
+FROM datasets d
+JOIN datasets_new n ON n.id = d.id
+JOIN eval_runs r ON r.dataset_id = d.id
+JOIN prompts p ON p.id = r.prompt_id
+WHERE p.workspace_id <> n.workspace_id;
+
+INSERT INTO dataset_rows (id, dataset_id, position, variables, expected, created_at)
+SELECT r.id || '-' || n.workspace_id, n.id, r.position, r.variables, r.expected, r.created_at
+FROM dataset_rows r
+JOIN datasets_new n ON n.id = r.dataset_id || '-' || n.workspace_id
+WHERE n.id NOT IN (SELECT id FROM datasets);
+
+UPDATE eval_runs
+SET dataset_id = dataset_id || '-' || (SELECT workspace_id FROM prompts WHERE id = eval_runs.prompt_id)
+WHERE (SELECT workspace_id FROM prompts WHERE id = eval_runs.prompt_id)
+   <> (SELECT workspace_id FROM datasets_new WHERE id = eval_runs.dataset_id);
+
+UPDATE eval_run_results
+SET dataset_row_id = (
+    SELECT copy.id FROM eval_runs r
+    JOIN dataset_rows original ON original.id = eval_run_results.dataset_row_id
+    JOIN dataset_rows copy ON copy.dataset_id = r.dataset_id AND copy.position = original.position
+    WHERE r.id = eval_run_results.eval_run_id
+)
+WHERE EXISTS (
+    SELECT 1 FROM eval_runs r
+    JOIN dataset_rows original ON original.id = eval_run_results.dataset_row_id
+    WHERE r.id = eval_run_results.eval_run_id AND r.dataset_id <> original.dataset_id
+);
+
+DROP TABLE datasets;
+ALTER TABLE datasets_new RENAME TO datasets;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_prompts_workspace_title;
+DROP INDEX IF EXISTS idx_prompts_workspace_updated_at;
+DROP INDEX IF EXISTS idx_prompts_workspace_created_at;
+CREATE INDEX IF NOT EXISTS idx_prompts_created_at ON prompts(created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_prompts_updated_at ON prompts(updated_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_prompts_title ON prompts(title, id) WHERE deleted_at IS NULL;
@@ -0,0 +1,8 @@
+-- Prompt lists always filter on the workspace, so the keyset pagination
+-- indexes lead with it
+DROP INDEX IF EXISTS idx_prompts_created_at;
+DROP INDEX IF EXISTS idx_prompts_updated_at;
+DROP INDEX IF EXISTS idx_prompts_title;
+CREATE INDEX IF NOT EXISTS idx_prompts_workspace_created_at ON prompts(workspace_id, created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_prompts_workspace_updated_at ON prompts(workspace_id, updated_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_prompts_workspace_title ON prompts(workspace_id, title, id) WHERE deleted_at IS NULL;
@@ -0,0 +1,20 @@
+-- Delete events have no version and are dropped
+CREATE TABLE prompt_label_history_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    prompt_id TEXT NOT NULL REFERENCES prompts(id),
+    name TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    previous_version INTEGER,
+    moved_by TEXT REFERENCES users(id),
+    moved_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO prompt_label_history_new (id, prompt_id, name, version, previous_version, moved_by, moved_at)
+SELECT id, prompt_id, name, version, previous_version, moved_by, moved_at
+FROM prompt_label_history
+WHERE version IS NOT NULL;
+
+DROP TABLE prompt_label_history;
+ALTER TABLE prompt_label_history_new RENAME TO prompt_label_history;
+
+CREATE INDEX IF NOT EXISTS idx_prompt_label_history_label ON prompt_label_history(prompt_id, name, id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_eval_run_results_row;
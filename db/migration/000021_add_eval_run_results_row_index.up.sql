@@ -0,0 +1,3 @@
+-- Finds the outputs of every eval run for a dataset row. A result's prompt
+-- version is that of its eval run.
+CREATE INDEX IF NOT EXISTS idx_eval_run_results_row ON eval_run_results(dataset_row_id, eval_run_id);
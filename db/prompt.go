@@ -0,0 +1,183 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+)
+
+// ErrSlugTaken is returned when a slug is already used by another prompt
+var ErrSlugTaken = errors.New("slug is already in use")
+
+// RenamePromptSlugParams contains the input parameters of RenamePromptSlug
+type RenamePromptSlugParams struct {
+	ID        string
+	Slug      string
+	Namespace string
+	// IfRevision, if set, must be the prompt's current revision
+	IfRevision sql.NullInt64
+}
+
+// RenamePromptSlug changes the slug and namespace of a prompt of the
+// context's workspace. The old slug is kept as a redirect to the prompt,
+// and any redirect occupying the new slug is dropped. It returns
+// ErrRevisionMismatch if IfRevision is set and no longer current.
+func (s *Store) RenamePromptSlug(ctx context.Context, arg RenamePromptSlugParams) (sqlc.Prompt, error) {
+	var prompt sqlc.Prompt
+	workspaceID := WorkspaceFromContext(ctx)
+
+	err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+		current, err := q.GetPrompt(ctx, sqlc.GetPromptParams{ID: arg.ID, WorkspaceID: workspaceID})
+		if err != nil {
+			return err
+		}
+		if arg.IfRevision.Valid && current.Revision != arg.IfRevision.Int64 {
+			return ErrRevisionMismatch
+		}
+		if current.Slug == arg.Slug && current.Namespace == arg.Namespace {
+			prompt = current
+			return nil
+		}
+
+		owner, err := q.GetPromptBySlug(ctx, sqlc.GetPromptBySlugParams{
+			WorkspaceID: workspaceID,
+			Namespace:   arg.Namespace,
+			Slug:        arg.Slug,
+		})
+		switch {
+		case err == nil && owner.ID != arg.ID:
+			return ErrSlugTaken
+		case err != nil && !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		if current.Slug != "" {
+			err = q.UpsertSlugRedirect(ctx, sqlc.UpsertSlugRedirectParams{
+				WorkspaceID: workspaceID,
+				Namespace:   current.Namespace,
+				Slug:        current.Slug,
+				PromptID:    current.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = q.DeleteSlugRedirect(ctx, sqlc.DeleteSlugRedirectParams{
+			WorkspaceID: workspaceID,
+			Namespace:   arg.Namespace,
+			Slug:        arg.Slug,
+		})
+		if err != nil {
+			return err
+		}
+
+		prompt, err = q.UpdatePromptSlug(ctx, sqlc.UpdatePromptSlugParams{
+			Slug:        arg.Slug,
+			Namespace:   arg.Namespace,
+			ID:          arg.ID,
+			WorkspaceID: workspaceID,
+		})
+		return err
+	})
+
+	return prompt, err
+}
+
+// PurgePrompt permanently deletes a prompt, live or soft-deleted, together
+// with its versions, evaluations, eval runs, runs, comments, labels, tags
+// and slug redirects. It returns ErrRevisionMismatch if ifRevision is set and no
+// longer current, and sql.ErrNoRows if the prompt does not exist in the
+// context's workspace.
+func (s *Store) PurgePrompt(ctx context.Context, id string, ifRevision sql.NullInt64) error {
+	return s.executeTx(ctx, func(q *sqlc.Queries) error {
+		if err := checkPromptOrTrashed(ctx, q, id); err != nil {
+			return err
+		}
+		return purgePrompt(ctx, q, id, ifRevision)
+	})
+}
+
+// PurgeDeletedPrompts permanently deletes prompts of every workspace that
+// were soft-deleted before the cutoff and returns how many were removed
+func (s *Store) PurgeDeletedPrompts(ctx context.Context, before time.Time) (int, error) {
+	ids, err := s.queries.ListPromptIDsDeletedBefore(ctx, sql.NullTime{Time: before.UTC(), Valid: true})
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		// Each prompt gets its own transaction so one failure doesn't
+		// hold back the rest
+		err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+			return purgePrompt(ctx, q, id, sql.NullInt64{})
+		})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return purged, err
+		}
+		if err == nil {
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// purgePrompt deletes a prompt and everything that belongs to it using q
+func purgePrompt(ctx context.Context, q *sqlc.Queries, id string, ifRevision sql.NullInt64) error {
+	promptID := sql.NullString{String: id, Valid: true}
+
+	// Children go first so enforced foreign keys are never violated
+	if err := q.ClearJudgeReferencesByPrompt(ctx, promptID); err != nil {
+		return err
+	}
+	if err := q.DeletePairwiseJudgmentsByPrompt(ctx, id); err != nil {
+		return err
+	}
+	if err := q.DeleteEvaluationsByPrompt(ctx, promptID); err != nil {
+		return err
+	}
+	if err := q.DeleteEvalRunResultsByPrompt(ctx, id); err != nil {
+		return err
+	}
+	if err := q.DeleteEvalRunsByPrompt(ctx, id); err != nil {
+		return err
+	}
+	if err := q.DeleteRunsByPrompt(ctx, promptID); err != nil {
+		return err
+	}
+	if err := q.DeleteCommentsByPrompt(ctx, promptID); err != nil {
+		return err
+	}
+	if err := q.DeleteLabelHistoryByPrompt(ctx, id); err != nil {
+		return err
+	}
+	if err := q.DeleteLabelsByPrompt(ctx, id); err != nil {
+		return err
+	}
+	if err := q.DeletePromptTagsByPrompt(ctx, id); err != nil {
+		return err
+	}
+	if err := q.DeleteSlugRedirectsByPrompt(ctx, id); err != nil {
+		return err
+	}
+	if err := q.DeleteVersions(ctx, promptID); err != nil {
+		return err
+	}
+
+	deleted, err := q.DeletePrompt(ctx, sqlc.DeletePromptParams{ID: id, IfRevision: ifRevision})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		if ifRevision.Valid {
+			return ErrRevisionMismatch
+		}
+		return sql.ErrNoRows
+	}
+	return nil
+}
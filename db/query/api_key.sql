@@ -0,0 +1,45 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+  id, user_id, workspace_id, name, prefix, key_hash
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetAPIKeyUser :one
+-- Gets the user an active key belongs to, and the workspace it signs in
+-- to, by the key's hash. Keys stop working when the user leaves the
+-- workspace.
+SELECT
+  k.id AS key_id,
+  k.workspace_id,
+  u.id,
+  u.name,
+  u.email,
+  u.created_at
+FROM api_keys k
+JOIN users u ON u.id = k.user_id
+JOIN workspace_members m ON m.workspace_id = k.workspace_id AND m.user_id = k.user_id
+WHERE k.key_hash = ? AND k.revoked_at IS NULL
+LIMIT 1;
+
+-- name: ListAPIKeysByUser :many
+SELECT * FROM api_keys
+WHERE user_id = ? AND workspace_id = ?
+ORDER BY created_at DESC, id DESC;
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND user_id = ? AND workspace_id = ? AND revoked_at IS NULL;
+
+-- name: TouchAPIKey :exec
+-- Records that a key was used, at most once a minute
+UPDATE api_keys
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = ?
+  AND (last_used_at IS NULL OR last_used_at < datetime('now', '-1 minute'));
+
+-- name: DeleteAPIKeysByUser :exec
+DELETE FROM api_keys
+WHERE user_id = ?;
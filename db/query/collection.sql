@@ -0,0 +1,48 @@
+-- name: CreateCollection :one
+INSERT INTO collections (
+  id, parent_id, name, description, created_by, workspace_id
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetCollection :one
+SELECT * FROM collections
+WHERE workspace_id = ? AND id = ? LIMIT 1;
+
+-- name: ListCollections :many
+SELECT * FROM collections
+WHERE workspace_id = ?
+ORDER BY name, id;
+
+-- name: ListCollectionAncestorIDs :many
+-- The collection itself first, then its parent, up to the root
+WITH RECURSIVE ancestors(id, parent_id, depth) AS (
+  SELECT c.id, c.parent_id, 0 FROM collections c WHERE c.id = ?
+  UNION ALL
+  SELECT c.id, c.parent_id, a.depth + 1 FROM collections c
+  JOIN ancestors a ON c.id = a.parent_id
+)
+SELECT id FROM ancestors
+ORDER BY depth;
+
+-- name: UpdateCollection :one
+UPDATE collections
+SET
+  parent_id = ?,
+  name = ?,
+  description = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE workspace_id = ? AND id = ?
+RETURNING *;
+
+-- name: MoveChildCollections :exec
+UPDATE collections
+SET
+  parent_id = sqlc.narg('to_parent_id'),
+  updated_at = CURRENT_TIMESTAMP
+WHERE parent_id = sqlc.arg('from_parent_id');
+
+-- name: DeleteCollection :exec
+DELETE FROM collections
+WHERE id = ?;
@@ -21,4 +21,24 @@ WHERE id = ?;
 
 -- name: DeleteCommentsByPrompt :exec
 DELETE FROM comments
-WHERE prompt_id = ?; 
\ No newline at end of file
+WHERE prompt_id = ?; 
+
+-- name: ListCommentsPageAsc :many
+SELECT * FROM comments
+WHERE prompt_id = sqlc.arg('prompt_id')
+  AND (sqlc.narg('created_by') IS NULL OR created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('cursor_key') IS NULL OR (created_at, id) > (CAST(sqlc.narg('cursor_key') AS TEXT), sqlc.narg('cursor_id')))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListCommentsPageDesc :many
+SELECT * FROM comments
+WHERE prompt_id = sqlc.arg('prompt_id')
+  AND (sqlc.narg('created_by') IS NULL OR created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('cursor_key') IS NULL OR (created_at, id) < (CAST(sqlc.narg('cursor_key') AS TEXT), sqlc.narg('cursor_id')))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
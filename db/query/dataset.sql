@@ -0,0 +1,50 @@
+-- name: CreateDataset :one
+INSERT INTO datasets (
+  id, name, description, created_by, workspace_id
+) VALUES (
+  ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetDataset :one
+SELECT * FROM datasets
+WHERE workspace_id = ? AND id = ? LIMIT 1;
+
+-- name: ListDatasets :many
+SELECT * FROM datasets
+WHERE workspace_id = ?
+ORDER BY name;
+
+-- name: UpdateDataset :one
+UPDATE datasets
+SET
+  name = ?,
+  description = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE workspace_id = ? AND id = ?
+RETURNING *;
+
+-- name: DeleteDataset :exec
+DELETE FROM datasets
+WHERE id = ?;
+
+-- name: CreateDatasetRow :one
+INSERT INTO dataset_rows (
+  id, dataset_id, position, variables, expected
+) VALUES (
+  ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetLastDatasetRowPosition :one
+SELECT CAST(COALESCE(MAX(position), 0) AS INTEGER) AS position FROM dataset_rows
+WHERE dataset_id = ?;
+
+-- name: ListDatasetRows :many
+SELECT * FROM dataset_rows
+WHERE dataset_id = ?
+ORDER BY position;
+
+-- name: DeleteDatasetRows :exec
+DELETE FROM dataset_rows
+WHERE dataset_id = ?;
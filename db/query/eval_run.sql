@@ -0,0 +1,102 @@
+-- name: CreateEvalRun :one
+INSERT INTO eval_runs (
+  id, prompt_id, prompt_version_id, dataset_id, model, parameters, scorers, total_rows, created_by
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetEvalRun :one
+SELECT * FROM eval_runs
+WHERE id = ? LIMIT 1;
+
+-- name: ListEvalRunsByVersion :many
+SELECT * FROM eval_runs
+WHERE prompt_version_id = ?
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetReusableEvalRun :one
+SELECT * FROM eval_runs
+WHERE prompt_version_id = ?
+  AND dataset_id = ?
+  AND model = ?
+  AND parameters = ?
+  AND scorers = ?
+  AND total_rows = ?
+  AND status != 'failed'
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
+
+-- name: CountEvalRunsByDataset :one
+SELECT COUNT(*) FROM eval_runs
+WHERE dataset_id = ?;
+
+-- name: StartEvalRun :exec
+UPDATE eval_runs
+SET
+  status = 'running',
+  started_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: FinishEvalRun :exec
+UPDATE eval_runs
+SET
+  status = ?,
+  error = ?,
+  finished_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: FailUnfinishedEvalRuns :execrows
+UPDATE eval_runs
+SET
+  status = 'failed',
+  error = ?,
+  finished_at = CURRENT_TIMESTAMP
+WHERE status IN ('pending', 'running');
+
+-- name: CreateEvalRunResult :one
+INSERT INTO eval_run_results (
+  eval_run_id, dataset_row_id, run_id, output, score, error
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: ListEvalRunResults :many
+SELECT r.* FROM eval_run_results r
+JOIN dataset_rows d ON d.id = r.dataset_row_id
+WHERE r.eval_run_id = ?
+ORDER BY d.position;
+
+-- name: GetEvalRunSummary :one
+SELECT
+  COUNT(*) AS results,
+  COUNT(r.error) AS errors,
+  COUNT(r.score) AS scored,
+  CAST(AVG(r.score) AS REAL) AS mean_score,
+  CAST(COALESCE(SUM(u.total_tokens), 0) AS INTEGER) AS total_tokens,
+  CAST(AVG(u.latency_ms) AS REAL) AS mean_latency_ms
+FROM eval_run_results r
+LEFT JOIN runs u ON u.id = r.run_id
+WHERE r.eval_run_id = ?;
+
+-- name: ListEvalRunScorerSummaries :many
+SELECT
+  e.scorer,
+  COUNT(*) AS evaluations,
+  COUNT(e.passed) AS checked,
+  CAST(COALESCE(SUM(e.passed), 0) AS INTEGER) AS passed,
+  CAST(AVG(e.score) AS REAL) AS mean_score
+FROM evaluations e
+JOIN eval_run_results r ON r.id = e.eval_run_result_id
+WHERE r.eval_run_id = ?
+GROUP BY e.scorer
+ORDER BY e.scorer;
+
+-- name: DeleteEvalRunResultsByPrompt :exec
+DELETE FROM eval_run_results
+WHERE eval_run_id IN (SELECT id FROM eval_runs WHERE prompt_id = ?);
+
+-- name: DeleteEvalRunsByPrompt :exec
+DELETE FROM eval_runs
+WHERE prompt_id = ?;
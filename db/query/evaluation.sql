@@ -1,8 +1,9 @@
 -- name: CreateEvaluation :one
 INSERT INTO evaluations (
-  id, prompt_version_id, score, notes, created_by
+  id, prompt_version_id, score, notes, created_by, scorer, passed, eval_run_result_id,
+  judge_prompt_version_id, judge_run_id
 ) VALUES (
-  ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
 RETURNING *;
 
@@ -15,10 +16,100 @@ SELECT * FROM evaluations
 WHERE prompt_version_id = ?
 ORDER BY created_at DESC;
 
+-- name: ListEvaluationsByEvalRun :many
+-- Lists the scorer evaluations of every result of an eval run
+SELECT evaluations.* FROM evaluations
+JOIN eval_run_results r ON r.id = evaluations.eval_run_result_id
+WHERE r.eval_run_id = ?
+ORDER BY evaluations.eval_run_result_id, evaluations.created_at, evaluations.id;
+
+-- name: ListVersionScoreStats :many
+-- Aggregates the evaluation scores of every version of a prompt.
+-- Percentiles interpolate linearly between the two closest ranks and the
+-- standard deviation is the sample one.
+WITH scored AS (
+  SELECT
+    e.prompt_version_id,
+    e.score,
+    ROW_NUMBER() OVER (PARTITION BY e.prompt_version_id ORDER BY e.score) - 1 AS idx,
+    COUNT(*) OVER (PARTITION BY e.prompt_version_id) - 1 AS last_idx
+  FROM evaluations e
+  WHERE e.score IS NOT NULL
+    AND e.prompt_version_id IN (SELECT id FROM prompt_versions WHERE prompt_id = sqlc.arg('prompt_id'))
+    AND (sqlc.narg('scorer') IS NULL OR e.scorer = sqlc.narg('scorer'))
+)
+SELECT
+  v.version,
+  v.created_at,
+  COUNT(s.score) AS count,
+  CAST(AVG(s.score) AS REAL) AS mean,
+  CAST(SUM(CASE
+    WHEN s.idx = CAST(s.last_idx * 0.5 AS INTEGER)
+      THEN s.score * (1 - (s.last_idx * 0.5 - CAST(s.last_idx * 0.5 AS INTEGER)))
+    WHEN s.idx = CAST(s.last_idx * 0.5 AS INTEGER) + 1
+      THEN s.score * (s.last_idx * 0.5 - CAST(s.last_idx * 0.5 AS INTEGER))
+  END) AS REAL) AS median,
+  CAST(SUM(CASE
+    WHEN s.idx = CAST(s.last_idx * 0.1 AS INTEGER)
+      THEN s.score * (1 - (s.last_idx * 0.1 - CAST(s.last_idx * 0.1 AS INTEGER)))
+    WHEN s.idx = CAST(s.last_idx * 0.1 AS INTEGER) + 1
+      THEN s.score * (s.last_idx * 0.1 - CAST(s.last_idx * 0.1 AS INTEGER))
+  END) AS REAL) AS p10,
+  CAST(SUM(CASE
+    WHEN s.idx = CAST(s.last_idx * 0.9 AS INTEGER)
+      THEN s.score * (1 - (s.last_idx * 0.9 - CAST(s.last_idx * 0.9 AS INTEGER)))
+    WHEN s.idx = CAST(s.last_idx * 0.9 AS INTEGER) + 1
+      THEN s.score * (s.last_idx * 0.9 - CAST(s.last_idx * 0.9 AS INTEGER))
+  END) AS REAL) AS p90,
+  CAST(CASE WHEN COUNT(s.score) > 1 THEN SQRT(MAX(0,
+    (SUM(s.score * s.score) - SUM(s.score) * SUM(s.score) / COUNT(s.score)) / (COUNT(s.score) - 1)
+  )) END AS REAL) AS stddev
+FROM prompt_versions v
+LEFT JOIN scored s ON s.prompt_version_id = v.id
+WHERE v.prompt_id = sqlc.arg('prompt_id')
+GROUP BY v.id
+ORDER BY v.version;
+
 -- name: DeleteEvaluation :exec
 DELETE FROM evaluations
 WHERE id = ?;
 
 -- name: DeleteEvaluationsByVersion :exec
 DELETE FROM evaluations
-WHERE prompt_version_id = ?; 
\ No newline at end of file
+WHERE prompt_version_id = ?;
+
+-- name: DeleteEvaluationsByPrompt :exec
+DELETE FROM evaluations
+WHERE prompt_version_id IN (
+  SELECT id FROM prompt_versions WHERE prompt_id = ?
+);
+
+-- name: ClearJudgeReferencesByPrompt :exec
+-- Unlinks evaluations from a prompt's versions used as a judge rubric
+UPDATE evaluations
+SET
+  judge_prompt_version_id = NULL,
+  judge_run_id = NULL
+WHERE judge_prompt_version_id IN (
+  SELECT id FROM prompt_versions WHERE prompt_id = ?
+);
+
+-- name: ListEvaluationsPageAsc :many
+SELECT * FROM evaluations
+WHERE prompt_version_id = sqlc.arg('prompt_version_id')
+  AND (sqlc.narg('created_by') IS NULL OR created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('cursor_key') IS NULL OR (created_at, id) > (CAST(sqlc.narg('cursor_key') AS TEXT), sqlc.narg('cursor_id')))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListEvaluationsPageDesc :many
+SELECT * FROM evaluations
+WHERE prompt_version_id = sqlc.arg('prompt_version_id')
+  AND (sqlc.narg('created_by') IS NULL OR created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('cursor_key') IS NULL OR (created_at, id) < (CAST(sqlc.narg('cursor_key') AS TEXT), sqlc.narg('cursor_id')))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
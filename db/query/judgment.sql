@@ -0,0 +1,100 @@
+-- name: CreatePairwiseJudgment :one
+INSERT INTO pairwise_judgments (
+  id, prompt_id, left_result_id, right_result_id, left_version_id, right_version_id, winner, notes, created_by
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetReviewResult :one
+-- Gets an eval run result with the prompt version that produced it
+SELECT
+  r.id,
+  r.dataset_row_id,
+  r.output,
+  r.error,
+  e.prompt_id,
+  e.prompt_version_id
+FROM eval_run_results r
+JOIN eval_runs e ON e.id = r.eval_run_id
+WHERE r.id = ? LIMIT 1;
+
+-- name: GetReviewPair :one
+-- Picks a random output of a prompt that the reviewer hasn't judged against
+-- every differing output of another version for the same dataset row, then
+-- pairs it with one of those outputs, preferring the pairs with the fewest
+-- judgments
+WITH pick AS (
+  SELECT a.id, a.output, a.dataset_row_id, ea.prompt_id, ea.prompt_version_id
+  FROM eval_run_results a
+  JOIN eval_runs ea ON ea.id = a.eval_run_id
+  JOIN prompts p ON p.id = ea.prompt_id
+  WHERE a.error IS NULL AND a.output IS NOT NULL
+    AND p.workspace_id = sqlc.arg('workspace_id')
+    AND p.deleted_at IS NULL
+    AND (sqlc.narg('prompt_id') IS NULL OR ea.prompt_id = sqlc.narg('prompt_id'))
+    AND EXISTS (
+      SELECT 1 FROM eval_run_results b
+      JOIN eval_runs eb ON eb.id = b.eval_run_id
+      WHERE b.dataset_row_id = a.dataset_row_id
+        AND eb.prompt_id = ea.prompt_id
+        AND eb.prompt_version_id != ea.prompt_version_id
+        AND b.error IS NULL AND b.output IS NOT NULL
+        AND b.output != a.output
+        AND NOT EXISTS (
+          SELECT 1 FROM pairwise_judgments j
+          WHERE j.created_by = sqlc.narg('created_by')
+            AND ((j.left_result_id = a.id AND j.right_result_id = b.id)
+              OR (j.left_result_id = b.id AND j.right_result_id = a.id))
+        )
+    )
+  ORDER BY RANDOM()
+  LIMIT 1
+)
+SELECT
+  a.id AS first_result_id,
+  a.output AS first_output,
+  b.id AS second_result_id,
+  b.output AS second_output,
+  a.prompt_id,
+  d.id AS dataset_row_id,
+  d.variables,
+  d.expected
+FROM pick a
+JOIN eval_run_results b ON b.dataset_row_id = a.dataset_row_id
+JOIN eval_runs eb ON eb.id = b.eval_run_id
+JOIN dataset_rows d ON d.id = a.dataset_row_id
+WHERE eb.prompt_id = a.prompt_id
+  AND eb.prompt_version_id != a.prompt_version_id
+  AND b.error IS NULL AND b.output IS NOT NULL
+  AND b.output != a.output
+  AND NOT EXISTS (
+    SELECT 1 FROM pairwise_judgments j
+    WHERE j.created_by = sqlc.narg('created_by')
+      AND ((j.left_result_id = a.id AND j.right_result_id = b.id)
+        OR (j.left_result_id = b.id AND j.right_result_id = a.id))
+  )
+ORDER BY (
+  SELECT COUNT(*) FROM pairwise_judgments j
+  WHERE (j.left_result_id = a.id AND j.right_result_id = b.id)
+     OR (j.left_result_id = b.id AND j.right_result_id = a.id)
+), RANDOM()
+LIMIT 1;
+
+-- name: ListPairwiseJudgmentCounts :many
+-- Counts a prompt's judgments by the versions compared and the outcome
+SELECT
+  lv.version AS left_version,
+  rv.version AS right_version,
+  j.winner,
+  COUNT(*) AS judgments
+FROM pairwise_judgments j
+JOIN prompt_versions lv ON lv.id = j.left_version_id
+JOIN prompt_versions rv ON rv.id = j.right_version_id
+WHERE j.prompt_id = ?
+GROUP BY lv.version, rv.version, j.winner
+ORDER BY lv.version, rv.version, j.winner;
+
+-- name: DeletePairwiseJudgmentsByPrompt :exec
+DELETE FROM pairwise_judgments
+WHERE prompt_id = ?;
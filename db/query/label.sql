@@ -0,0 +1,51 @@
+-- name: UpsertLabel :one
+INSERT INTO prompt_labels (
+  prompt_id, name, version, updated_by
+) VALUES (
+  ?, ?, ?, ?
+)
+ON CONFLICT (prompt_id, name) DO UPDATE SET
+  version = excluded.version,
+  updated_by = excluded.updated_by,
+  updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetLabel :one
+SELECT * FROM prompt_labels
+WHERE prompt_id = ? AND name = ? LIMIT 1;
+
+-- name: ListLabels :many
+SELECT * FROM prompt_labels
+WHERE prompt_id = ?
+ORDER BY name;
+
+-- name: DeleteLabel :exec
+DELETE FROM prompt_labels
+WHERE prompt_id = ? AND name = ?;
+
+-- name: CreateLabelEvent :one
+INSERT INTO prompt_label_history (
+  prompt_id, name, version, previous_version, moved_by
+) VALUES (
+  ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetLatestLabelEvent :one
+SELECT * FROM prompt_label_history
+WHERE prompt_id = ? AND name = ?
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ListLabelHistory :many
+SELECT * FROM prompt_label_history
+WHERE prompt_id = ? AND name = ?
+ORDER BY id DESC;
+
+-- name: DeleteLabelsByPrompt :exec
+DELETE FROM prompt_labels
+WHERE prompt_id = ?;
+
+-- name: DeleteLabelHistoryByPrompt :exec
+DELETE FROM prompt_label_history
+WHERE prompt_id = ?;
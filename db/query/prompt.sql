@@ -1,33 +1,231 @@
 -- name: CreatePrompt :one
 INSERT INTO prompts (
-  id, title, description, created_by
+  id, title, description, slug, namespace, collection_id, created_by, workspace_id
 ) VALUES (
-  ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?
 )
 RETURNING *;
 
 -- name: GetPrompt :one
 SELECT * FROM prompts
-WHERE id = ? LIMIT 1;
+WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetDeletedPrompt :one
+SELECT * FROM prompts
+WHERE id = ? AND workspace_id = ? AND deleted_at IS NOT NULL LIMIT 1;
+
+-- name: GetPromptBySlug :one
+-- Soft-deleted prompts keep their slug so they can be restored
+SELECT * FROM prompts
+WHERE workspace_id = ? AND namespace = ? AND slug = ? LIMIT 1;
 
 -- name: ListPrompts :many
 SELECT * FROM prompts
+WHERE workspace_id = ? AND deleted_at IS NULL
 ORDER BY created_at DESC;
 
 -- name: ListPromptsByUser :many
 SELECT * FROM prompts
-WHERE created_by = ?
+WHERE workspace_id = ? AND created_by = ? AND deleted_at IS NULL
 ORDER BY created_at DESC;
 
 -- name: UpdatePrompt :one
 UPDATE prompts
 SET 
-  title = ?,
-  description = ?,
+  title = sqlc.arg('title'),
+  description = sqlc.arg('description'),
+  collection_id = sqlc.narg('collection_id'),
+  revision = revision + 1,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')
+  AND workspace_id = sqlc.arg('workspace_id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('if_revision') IS NULL OR revision = sqlc.narg('if_revision'))
+RETURNING *;
+
+-- name: BumpPromptRevision :execrows
+UPDATE prompts
+SET
+  revision = revision + 1,
   updated_at = CURRENT_TIMESTAMP
-WHERE id = ?
+WHERE id = sqlc.arg('id')
+  AND workspace_id = sqlc.arg('workspace_id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('if_revision') IS NULL OR revision = sqlc.narg('if_revision'));
+
+-- name: UpdatePromptSlug :one
+UPDATE prompts
+SET
+  slug = ?,
+  namespace = ?,
+  revision = revision + 1,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND workspace_id = ?
 RETURNING *;
 
--- name: DeletePrompt :exec
+-- name: DeletePrompt :execrows
 DELETE FROM prompts
-WHERE id = ?; 
\ No newline at end of file
+WHERE id = sqlc.arg('id')
+  AND (sqlc.narg('if_revision') IS NULL OR revision = sqlc.narg('if_revision'));
+
+-- name: SoftDeletePrompt :execrows
+UPDATE prompts
+SET
+  deleted_at = CURRENT_TIMESTAMP,
+  revision = revision + 1
+WHERE id = sqlc.arg('id')
+  AND workspace_id = sqlc.arg('workspace_id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('if_revision') IS NULL OR revision = sqlc.narg('if_revision'));
+
+-- name: RestorePrompt :one
+UPDATE prompts
+SET
+  deleted_at = NULL,
+  revision = revision + 1,
+  updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND workspace_id = ? AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: MovePromptsToCollection :exec
+-- Used when a collection is deleted; includes soft-deleted prompts
+UPDATE prompts
+SET
+  collection_id = sqlc.narg('to_collection_id'),
+  revision = revision + 1
+WHERE collection_id = sqlc.arg('from_collection_id');
+
+-- name: ListPromptIDsDeletedBefore :many
+-- Used by the retention job; covers every workspace
+SELECT id FROM prompts
+WHERE deleted_at IS NOT NULL AND deleted_at < ?
+ORDER BY deleted_at;
+
+-- name: ListPromptsPageByCreatedAsc :many
+-- One query per sort column and direction so the keyset comparison and
+-- the ORDER BY use the workspace's list index. Without a cursor the first
+-- page compares against '' going up and against a blob going down, which
+-- sorts after any text.
+SELECT * FROM prompts
+WHERE workspace_id = sqlc.arg('workspace_id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('created_by') IS NULL OR created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('updated_after') IS NULL OR updated_at >= sqlc.narg('updated_after'))
+  AND (sqlc.narg('updated_before') IS NULL OR updated_at < sqlc.narg('updated_before'))
+  AND (sqlc.narg('title_prefix') IS NULL OR title LIKE CAST(sqlc.narg('title_prefix') AS TEXT) || '%' ESCAPE '\')
+  AND (sqlc.narg('collection_id') IS NULL OR collection_id = sqlc.narg('collection_id'))
+  AND (sqlc.narg('tag') IS NULL OR id IN (
+    SELECT pt.prompt_id FROM prompt_tags pt
+    JOIN tags t ON t.id = pt.tag_id
+    WHERE t.name = sqlc.narg('tag')
+  ))
+  AND (created_at, id) > (COALESCE(sqlc.narg('cursor_key'), ''), COALESCE(sqlc.narg('cursor_id'), ''))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListPromptsPageByCreatedDesc :many
+SELECT * FROM prompts
+WHERE workspace_id = sqlc.arg('workspace_id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('created_by') IS NULL OR created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('updated_after') IS NULL OR updated_at >= sqlc.narg('updated_after'))
+  AND (sqlc.narg('updated_before') IS NULL OR updated_at < sqlc.narg('updated_before'))
+  AND (sqlc.narg('title_prefix') IS NULL OR title LIKE CAST(sqlc.narg('title_prefix') AS TEXT) || '%' ESCAPE '\')
+  AND (sqlc.narg('collection_id') IS NULL OR collection_id = sqlc.narg('collection_id'))
+  AND (sqlc.narg('tag') IS NULL OR id IN (
+    SELECT pt.prompt_id FROM prompt_tags pt
+    JOIN tags t ON t.id = pt.tag_id
+    WHERE t.name = sqlc.narg('tag')
+  ))
+  AND (created_at, id) < (COALESCE(sqlc.narg('cursor_key'), x'ff'), COALESCE(sqlc.narg('cursor_id'), x'ff'))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListPromptsPageByUpdatedAsc :many
+SELECT * FROM prompts
+WHERE workspace_id = sqlc.arg('workspace_id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('created_by') IS NULL OR created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('updated_after') IS NULL OR updated_at >= sqlc.narg('updated_after'))
+  AND (sqlc.narg('updated_before') IS NULL OR updated_at < sqlc.narg('updated_before'))
+  AND (sqlc.narg('title_prefix') IS NULL OR title LIKE CAST(sqlc.narg('title_prefix') AS TEXT) || '%' ESCAPE '\')
+  AND (sqlc.narg('collection_id') IS NULL OR collection_id = sqlc.narg('collection_id'))
+  AND (sqlc.narg('tag') IS NULL OR id IN (
+    SELECT pt.prompt_id FROM prompt_tags pt
+    JOIN tags t ON t.id = pt.tag_id
+    WHERE t.name = sqlc.narg('tag')
+  ))
+  AND (updated_at, id) > (COALESCE(sqlc.narg('cursor_key'), ''), COALESCE(sqlc.narg('cursor_id'), ''))
+ORDER BY updated_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListPromptsPageByUpdatedDesc :many
+SELECT * FROM prompts
+WHERE workspace_id = sqlc.arg('workspace_id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('created_by') IS NULL OR created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('updated_after') IS NULL OR updated_at >= sqlc.narg('updated_after'))
+  AND (sqlc.narg('updated_before') IS NULL OR updated_at < sqlc.narg('updated_before'))
+  AND (sqlc.narg('title_prefix') IS NULL OR title LIKE CAST(sqlc.narg('title_prefix') AS TEXT) || '%' ESCAPE '\')
+  AND (sqlc.narg('collection_id') IS NULL OR collection_id = sqlc.narg('collection_id'))
+  AND (sqlc.narg('tag') IS NULL OR id IN (
+    SELECT pt.prompt_id FROM prompt_tags pt
+    JOIN tags t ON t.id = pt.tag_id
+    WHERE t.name = sqlc.narg('tag')
+  ))
+  AND (updated_at, id) < (COALESCE(sqlc.narg('cursor_key'), x'ff'), COALESCE(sqlc.narg('cursor_id'), x'ff'))
+ORDER BY updated_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListPromptsPageByTitleAsc :many
+SELECT * FROM prompts
+WHERE workspace_id = sqlc.arg('workspace_id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('created_by') IS NULL OR created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('updated_after') IS NULL OR updated_at >= sqlc.narg('updated_after'))
+  AND (sqlc.narg('updated_before') IS NULL OR updated_at < sqlc.narg('updated_before'))
+  AND (sqlc.narg('title_prefix') IS NULL OR title LIKE CAST(sqlc.narg('title_prefix') AS TEXT) || '%' ESCAPE '\')
+  AND (sqlc.narg('collection_id') IS NULL OR collection_id = sqlc.narg('collection_id'))
+  AND (sqlc.narg('tag') IS NULL OR id IN (
+    SELECT pt.prompt_id FROM prompt_tags pt
+    JOIN tags t ON t.id = pt.tag_id
+    WHERE t.name = sqlc.narg('tag')
+  ))
+  AND (title, id) > (COALESCE(sqlc.narg('cursor_key'), ''), COALESCE(sqlc.narg('cursor_id'), ''))
+ORDER BY title ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListPromptsPageByTitleDesc :many
+SELECT * FROM prompts
+WHERE workspace_id = sqlc.arg('workspace_id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('created_by') IS NULL OR created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('updated_after') IS NULL OR updated_at >= sqlc.narg('updated_after'))
+  AND (sqlc.narg('updated_before') IS NULL OR updated_at < sqlc.narg('updated_before'))
+  AND (sqlc.narg('title_prefix') IS NULL OR title LIKE CAST(sqlc.narg('title_prefix') AS TEXT) || '%' ESCAPE '\')
+  AND (sqlc.narg('collection_id') IS NULL OR collection_id = sqlc.narg('collection_id'))
+  AND (sqlc.narg('tag') IS NULL OR id IN (
+    SELECT pt.prompt_id FROM prompt_tags pt
+    JOIN tags t ON t.id = pt.tag_id
+    WHERE t.name = sqlc.narg('tag')
+  ))
+  AND (title, id) < (COALESCE(sqlc.narg('cursor_key'), x'ff'), COALESCE(sqlc.narg('cursor_id'), x'ff'))
+ORDER BY title DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetPromptWorkspace :one
+-- Includes soft-deleted prompts
+SELECT workspace_id, deleted_at FROM prompts
+WHERE id = ? LIMIT 1;
@@ -0,0 +1,36 @@
+-- name: CreateRun :one
+INSERT INTO runs (
+  id, prompt_id, prompt_version_id, model, parameters, messages, response,
+  prompt_tokens, completion_tokens, total_tokens, latency_ms, error, created_by
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetRun :one
+SELECT * FROM runs
+WHERE id = ? LIMIT 1;
+
+-- name: ListRunsByVersion :many
+SELECT * FROM runs
+WHERE prompt_version_id = sqlc.arg('prompt_version_id')
+  AND (sqlc.narg('model') IS NULL OR model = sqlc.narg('model'))
+  AND (sqlc.narg('failed') IS NULL OR (error IS NOT NULL) = CAST(sqlc.narg('failed') AS BOOLEAN))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListRunsByPrompt :many
+SELECT * FROM runs
+WHERE prompt_id = sqlc.arg('prompt_id')
+  AND (sqlc.narg('model') IS NULL OR model = sqlc.narg('model'))
+  AND (sqlc.narg('failed') IS NULL OR (error IS NOT NULL) = CAST(sqlc.narg('failed') AS BOOLEAN))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit');
+
+-- name: DeleteRunsByPrompt :exec
+DELETE FROM runs
+WHERE prompt_id = ?;
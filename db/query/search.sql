@@ -0,0 +1,15 @@
+-- name: SearchPrompts :many
+-- Matches are marked with char(2) and char(3) so callers can escape the
+-- text before highlighting it
+SELECT p.id, p.title, p.description, p.slug, p.namespace,
+  highlight(prompts_fts, 1, char(2), char(3)) AS title_highlight,
+  snippet(prompts_fts, 2, char(2), char(3), '…', 16) AS description_snippet,
+  snippet(prompts_fts, 3, char(2), char(3), '…', 16) AS content_snippet,
+  CAST(-bm25(prompts_fts, 0.0, 10.0, 5.0, 1.0) AS REAL) AS score
+FROM prompts_fts
+JOIN prompts p ON p.id = prompts_fts.prompt_id
+WHERE prompts_fts MATCH sqlc.arg('query')
+  AND p.workspace_id = sqlc.arg('workspace_id')
+  AND p.deleted_at IS NULL
+ORDER BY score DESC
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,21 @@
+-- name: UpsertSlugRedirect :exec
+INSERT INTO prompt_slug_redirects (
+  workspace_id, namespace, slug, prompt_id
+) VALUES (
+  ?, ?, ?, ?
+)
+ON CONFLICT (workspace_id, namespace, slug) DO UPDATE SET
+  prompt_id = excluded.prompt_id,
+  created_at = CURRENT_TIMESTAMP;
+
+-- name: GetSlugRedirect :one
+SELECT * FROM prompt_slug_redirects
+WHERE workspace_id = ? AND namespace = ? AND slug = ? LIMIT 1;
+
+-- name: DeleteSlugRedirect :exec
+DELETE FROM prompt_slug_redirects
+WHERE workspace_id = ? AND namespace = ? AND slug = ?;
+
+-- name: DeleteSlugRedirectsByPrompt :exec
+DELETE FROM prompt_slug_redirects
+WHERE prompt_id = ?;
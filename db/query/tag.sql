@@ -0,0 +1,66 @@
+-- name: UpsertTag :one
+INSERT INTO tags (
+  name, workspace_id
+) VALUES (
+  ?, ?
+)
+ON CONFLICT (workspace_id, name) DO UPDATE SET
+  name = excluded.name
+RETURNING *;
+
+-- name: GetTag :one
+SELECT * FROM tags
+WHERE workspace_id = ? AND id = ? LIMIT 1;
+
+-- name: GetTagByName :one
+SELECT * FROM tags
+WHERE workspace_id = ? AND name = ? LIMIT 1;
+
+-- name: ListTags :many
+-- Counts only live prompts
+SELECT t.id, t.name, t.created_at, COUNT(p.id) AS prompt_count
+FROM tags t
+LEFT JOIN prompt_tags pt ON pt.tag_id = t.id
+LEFT JOIN prompts p ON p.id = pt.prompt_id AND p.deleted_at IS NULL
+WHERE t.workspace_id = ?
+GROUP BY t.id
+ORDER BY t.name;
+
+-- name: RenameTag :one
+UPDATE tags
+SET name = ?
+WHERE workspace_id = ? AND id = ?
+RETURNING *;
+
+-- name: DeleteTag :exec
+DELETE FROM tags
+WHERE id = ?;
+
+-- name: AddPromptTag :exec
+INSERT INTO prompt_tags (
+  prompt_id, tag_id
+) VALUES (
+  ?, ?
+)
+ON CONFLICT (prompt_id, tag_id) DO NOTHING;
+
+-- name: ListPromptTagNames :many
+SELECT t.name FROM tags t
+JOIN prompt_tags pt ON pt.tag_id = t.id
+WHERE pt.prompt_id = ?
+ORDER BY t.name;
+
+-- name: BumpTaggedPromptRevisions :exec
+-- Tag names are part of a prompt's representation, so renaming or
+-- deleting a tag changes the ETag of every prompt using it
+UPDATE prompts
+SET revision = revision + 1
+WHERE id IN (SELECT prompt_id FROM prompt_tags WHERE tag_id = ?);
+
+-- name: DeletePromptTagsByPrompt :exec
+DELETE FROM prompt_tags
+WHERE prompt_id = ?;
+
+-- name: DeletePromptTagsByTag :exec
+DELETE FROM prompt_tags
+WHERE tag_id = ?;
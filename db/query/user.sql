@@ -26,4 +26,28 @@ RETURNING *;
 
 -- name: DeleteUser :exec
 DELETE FROM users
-WHERE id = ?; 
\ No newline at end of file
+WHERE id = ?; 
+-- name: EnsureUser :exec
+-- EnsureUser records a user the first time they are seen, leaving
+-- existing users untouched
+INSERT INTO users (
+  id, name, email
+) VALUES (
+  ?, ?, ?
+)
+ON CONFLICT DO NOTHING;
+
+-- name: GetWorkspaceUser :one
+-- Gets a user who is a member of the workspace
+SELECT u.id, u.name, u.email, u.created_at
+FROM users u
+JOIN workspace_members m ON m.user_id = u.id
+WHERE m.workspace_id = ? AND u.id = ? LIMIT 1;
+
+-- name: ListWorkspaceUsers :many
+-- Lists the members of a workspace as users
+SELECT u.id, u.name, u.email, u.created_at
+FROM users u
+JOIN workspace_members m ON m.user_id = u.id
+WHERE m.workspace_id = ?
+ORDER BY u.name;
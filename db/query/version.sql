@@ -1,8 +1,8 @@
 -- name: CreateVersion :one
 INSERT INTO prompt_versions (
-  id, prompt_id, version, content, created_by
+  id, prompt_id, version, content, inputs, restored_from, created_by
 ) VALUES (
-  ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?
 )
 RETURNING *;
 
@@ -26,4 +26,24 @@ WHERE prompt_id = ?;
 
 -- name: DeleteVersions :exec
 DELETE FROM prompt_versions
-WHERE prompt_id = ?; 
\ No newline at end of file
+WHERE prompt_id = ?; 
+
+-- name: ListVersionsPageAsc :many
+SELECT * FROM prompt_versions
+WHERE prompt_id = sqlc.arg('prompt_id')
+  AND (sqlc.narg('created_by') IS NULL OR created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('cursor_version') IS NULL OR version > sqlc.narg('cursor_version'))
+ORDER BY version ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListVersionsPageDesc :many
+SELECT * FROM prompt_versions
+WHERE prompt_id = sqlc.arg('prompt_id')
+  AND (sqlc.narg('created_by') IS NULL OR created_by = sqlc.narg('created_by'))
+  AND (sqlc.narg('created_after') IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before') IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('cursor_version') IS NULL OR version < sqlc.narg('cursor_version'))
+ORDER BY version DESC
+LIMIT sqlc.arg('limit');
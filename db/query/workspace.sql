@@ -0,0 +1,59 @@
+-- name: CreateWorkspace :one
+INSERT INTO workspaces (
+  id, name
+) VALUES (
+  ?, ?
+)
+RETURNING *;
+
+-- name: GetWorkspace :one
+SELECT * FROM workspaces
+WHERE id = ? LIMIT 1;
+
+-- name: ListWorkspacesByUser :many
+-- Lists the workspaces a user belongs to with their role in each
+SELECT w.id, w.name, w.created_at, m.role
+FROM workspaces w
+JOIN workspace_members m ON m.workspace_id = w.id
+WHERE m.user_id = ?
+ORDER BY w.name, w.id;
+
+-- name: UpsertWorkspaceMember :one
+INSERT INTO workspace_members (
+  workspace_id, user_id, role
+) VALUES (
+  ?, ?, ?
+)
+ON CONFLICT (workspace_id, user_id) DO UPDATE SET
+  role = excluded.role
+RETURNING *;
+
+-- name: GetWorkspaceMember :one
+SELECT * FROM workspace_members
+WHERE workspace_id = ? AND user_id = ? LIMIT 1;
+
+-- name: ListWorkspaceMembers :many
+SELECT u.id, u.name, u.email, m.role, m.created_at
+FROM workspace_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.workspace_id = ?
+ORDER BY u.name, u.id;
+
+-- name: CountWorkspaceOwners :one
+SELECT COUNT(*) FROM workspace_members
+WHERE workspace_id = ? AND role = 'owner';
+
+-- name: DeleteWorkspaceMember :execrows
+DELETE FROM workspace_members
+WHERE workspace_id = ? AND user_id = ?;
+
+-- name: DeleteWorkspaceMembersByUser :exec
+DELETE FROM workspace_members
+WHERE user_id = ?;
+
+-- name: GetVersionWorkspace :one
+-- Gets the workspace of a version's prompt
+SELECT p.workspace_id, p.deleted_at
+FROM prompt_versions v
+JOIN prompts p ON p.id = v.prompt_id
+WHERE v.id = ? LIMIT 1;
@@ -0,0 +1,527 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+)
+
+// The methods in this file wrap generated queries so that prompts, and
+// the versions, comments, evaluations, labels, runs, eval runs and
+// judgments that belong to them, are only visible in the workspace of the
+// context (see WithWorkspace). Prompt queries filter by workspace
+// themselves; records of a prompt in another workspace, or of a prompt in
+// the trash, behave as if they did not exist: lookups return
+// sql.ErrNoRows and lists come back empty. Tags, collections and datasets
+// belong to a workspace themselves.
+
+// promptVisible reports whether a live prompt exists in the context's
+// workspace
+func promptVisible(ctx context.Context, q *sqlc.Queries, promptID string) (bool, error) {
+	row, err := q.GetPromptWorkspace(ctx, promptID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return row.WorkspaceID == WorkspaceFromContext(ctx) && !row.DeletedAt.Valid, nil
+}
+
+// versionVisible reports whether a version's prompt is live and in the
+// context's workspace
+func versionVisible(ctx context.Context, q *sqlc.Queries, versionID string) (bool, error) {
+	row, err := q.GetVersionWorkspace(ctx, versionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return row.WorkspaceID == WorkspaceFromContext(ctx) && !row.DeletedAt.Valid, nil
+}
+
+// checkPromptOrTrashed returns sql.ErrNoRows unless the prompt, live or in
+// the trash, exists in the context's workspace. Only purging looks into
+// the trash this way.
+func checkPromptOrTrashed(ctx context.Context, q *sqlc.Queries, promptID string) error {
+	row, err := q.GetPromptWorkspace(ctx, promptID)
+	if err == nil && row.WorkspaceID != WorkspaceFromContext(ctx) {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// checkPrompt returns sql.ErrNoRows unless the prompt is visible
+func checkPrompt(ctx context.Context, q *sqlc.Queries, promptID string) error {
+	visible, err := promptVisible(ctx, q, promptID)
+	if err == nil && !visible {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// checkVersion returns sql.ErrNoRows unless the version is visible
+func checkVersion(ctx context.Context, q *sqlc.Queries, versionID string) error {
+	visible, err := versionVisible(ctx, q, versionID)
+	if err == nil && !visible {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// checkDataset returns sql.ErrNoRows unless the dataset is in the
+// context's workspace
+func checkDataset(ctx context.Context, q *sqlc.Queries, datasetID string) error {
+	_, err := q.GetDataset(ctx, sqlc.GetDatasetParams{WorkspaceID: WorkspaceFromContext(ctx), ID: datasetID})
+	return err
+}
+
+// checkEvalRun returns sql.ErrNoRows unless the eval run's prompt is
+// visible
+func checkEvalRun(ctx context.Context, q *sqlc.Queries, evalRunID string) error {
+	run, err := q.GetEvalRun(ctx, evalRunID)
+	if err != nil {
+		return err
+	}
+	return checkPrompt(ctx, q, run.PromptID)
+}
+
+// CreatePrompt creates a prompt in the context's workspace
+func (s *Store) CreatePrompt(ctx context.Context, arg sqlc.CreatePromptParams) (sqlc.Prompt, error) {
+	arg.WorkspaceID = WorkspaceFromContext(ctx)
+	return s.queries.CreatePrompt(ctx, arg)
+}
+
+// GetPrompt returns a live prompt of the context's workspace
+func (s *Store) GetPrompt(ctx context.Context, id string) (sqlc.Prompt, error) {
+	return s.queries.GetPrompt(ctx, sqlc.GetPromptParams{ID: id, WorkspaceID: WorkspaceFromContext(ctx)})
+}
+
+// GetDeletedPrompt returns a soft-deleted prompt of the context's workspace
+func (s *Store) GetDeletedPrompt(ctx context.Context, id string) (sqlc.Prompt, error) {
+	return s.queries.GetDeletedPrompt(ctx, sqlc.GetDeletedPromptParams{ID: id, WorkspaceID: WorkspaceFromContext(ctx)})
+}
+
+// GetPromptBySlug looks a slug up in the context's workspace
+func (s *Store) GetPromptBySlug(ctx context.Context, arg sqlc.GetPromptBySlugParams) (sqlc.Prompt, error) {
+	arg.WorkspaceID = WorkspaceFromContext(ctx)
+	return s.queries.GetPromptBySlug(ctx, arg)
+}
+
+// GetSlugRedirect looks a former slug up in the context's workspace
+func (s *Store) GetSlugRedirect(ctx context.Context, arg sqlc.GetSlugRedirectParams) (sqlc.PromptSlugRedirect, error) {
+	arg.WorkspaceID = WorkspaceFromContext(ctx)
+	return s.queries.GetSlugRedirect(ctx, arg)
+}
+
+// ListPrompts returns the live prompts of the context's workspace
+func (s *Store) ListPrompts(ctx context.Context) ([]sqlc.Prompt, error) {
+	return s.queries.ListPrompts(ctx, WorkspaceFromContext(ctx))
+}
+
+// ListPromptsByUser returns the live prompts a user created in the
+// context's workspace
+func (s *Store) ListPromptsByUser(ctx context.Context, createdBy sql.NullString) ([]sqlc.Prompt, error) {
+	return s.queries.ListPromptsByUser(ctx, sqlc.ListPromptsByUserParams{WorkspaceID: WorkspaceFromContext(ctx), CreatedBy: createdBy})
+}
+
+// UpdatePrompt updates a live prompt of the context's workspace
+func (s *Store) UpdatePrompt(ctx context.Context, arg sqlc.UpdatePromptParams) (sqlc.Prompt, error) {
+	arg.WorkspaceID = WorkspaceFromContext(ctx)
+	return s.queries.UpdatePrompt(ctx, arg)
+}
+
+// SoftDeletePrompt soft-deletes a live prompt of the context's workspace
+func (s *Store) SoftDeletePrompt(ctx context.Context, arg sqlc.SoftDeletePromptParams) (int64, error) {
+	arg.WorkspaceID = WorkspaceFromContext(ctx)
+	return s.queries.SoftDeletePrompt(ctx, arg)
+}
+
+// RestorePrompt restores a soft-deleted prompt of the context's workspace
+func (s *Store) RestorePrompt(ctx context.Context, id string) (sqlc.Prompt, error) {
+	return s.queries.RestorePrompt(ctx, sqlc.RestorePromptParams{ID: id, WorkspaceID: WorkspaceFromContext(ctx)})
+}
+
+// SearchPrompts searches the live prompts of the context's workspace
+func (s *Store) SearchPrompts(ctx context.Context, arg sqlc.SearchPromptsParams) ([]sqlc.SearchPromptsRow, error) {
+	arg.WorkspaceID = WorkspaceFromContext(ctx)
+	return s.queries.SearchPrompts(ctx, arg)
+}
+
+// ListTags returns the tags of the context's workspace with the number of
+// live prompts using each
+func (s *Store) ListTags(ctx context.Context) ([]sqlc.ListTagsRow, error) {
+	return s.queries.ListTags(ctx, WorkspaceFromContext(ctx))
+}
+
+// CreateVersion adds a version to a prompt of the context's workspace
+func (s *Store) CreateVersion(ctx context.Context, arg sqlc.CreateVersionParams) (sqlc.PromptVersion, error) {
+	if err := checkPrompt(ctx, s.queries, arg.PromptID.String); err != nil {
+		return sqlc.PromptVersion{}, err
+	}
+	return s.queries.CreateVersion(ctx, arg)
+}
+
+// GetVersion returns a version of a prompt of the context's workspace
+func (s *Store) GetVersion(ctx context.Context, id string) (sqlc.PromptVersion, error) {
+	if err := checkVersion(ctx, s.queries, id); err != nil {
+		return sqlc.PromptVersion{}, err
+	}
+	return s.queries.GetVersion(ctx, id)
+}
+
+// GetVersionByPromptAndNumber returns a version of a prompt of the
+// context's workspace
+func (s *Store) GetVersionByPromptAndNumber(ctx context.Context, arg sqlc.GetVersionByPromptAndNumberParams) (sqlc.PromptVersion, error) {
+	if err := checkPrompt(ctx, s.queries, arg.PromptID.String); err != nil {
+		return sqlc.PromptVersion{}, err
+	}
+	return s.queries.GetVersionByPromptAndNumber(ctx, arg)
+}
+
+// GetLatestVersionNumber returns the latest version number of a prompt of
+// the context's workspace, or 0 like for a prompt without versions
+func (s *Store) GetLatestVersionNumber(ctx context.Context, promptID sql.NullString) (interface{}, error) {
+	if visible, err := promptVisible(ctx, s.queries, promptID.String); !visible {
+		return int64(0), err
+	}
+	return s.queries.GetLatestVersionNumber(ctx, promptID)
+}
+
+// ListVersions returns the versions of a prompt of the context's workspace
+func (s *Store) ListVersions(ctx context.Context, promptID sql.NullString) ([]sqlc.PromptVersion, error) {
+	if visible, err := promptVisible(ctx, s.queries, promptID.String); !visible {
+		return nil, err
+	}
+	return s.queries.ListVersions(ctx, promptID)
+}
+
+// CreateComment adds a comment to a prompt of the context's workspace
+func (s *Store) CreateComment(ctx context.Context, arg sqlc.CreateCommentParams) (sqlc.Comment, error) {
+	if err := checkPrompt(ctx, s.queries, arg.PromptID.String); err != nil {
+		return sqlc.Comment{}, err
+	}
+	return s.queries.CreateComment(ctx, arg)
+}
+
+// GetComment returns a comment on a prompt of the context's workspace
+func (s *Store) GetComment(ctx context.Context, id string) (sqlc.Comment, error) {
+	comment, err := s.queries.GetComment(ctx, id)
+	if err != nil {
+		return comment, err
+	}
+	if err := checkPrompt(ctx, s.queries, comment.PromptID.String); err != nil {
+		return sqlc.Comment{}, err
+	}
+	return comment, nil
+}
+
+// ListComments returns the comments on a prompt of the context's workspace
+func (s *Store) ListComments(ctx context.Context, promptID sql.NullString) ([]sqlc.Comment, error) {
+	if visible, err := promptVisible(ctx, s.queries, promptID.String); !visible {
+		return nil, err
+	}
+	return s.queries.ListComments(ctx, promptID)
+}
+
+// CreateEvaluation adds an evaluation to a version of a prompt of the
+// context's workspace
+func (s *Store) CreateEvaluation(ctx context.Context, arg sqlc.CreateEvaluationParams) (sqlc.Evaluation, error) {
+	if err := checkVersion(ctx, s.queries, arg.PromptVersionID.String); err != nil {
+		return sqlc.Evaluation{}, err
+	}
+	return s.queries.CreateEvaluation(ctx, arg)
+}
+
+// GetEvaluation returns an evaluation of a version of a prompt of the
+// context's workspace
+func (s *Store) GetEvaluation(ctx context.Context, id string) (sqlc.Evaluation, error) {
+	eval, err := s.queries.GetEvaluation(ctx, id)
+	if err != nil {
+		return eval, err
+	}
+	if err := checkVersion(ctx, s.queries, eval.PromptVersionID.String); err != nil {
+		return sqlc.Evaluation{}, err
+	}
+	return eval, nil
+}
+
+// ListEvaluations returns the evaluations of a version of a prompt of the
+// context's workspace
+func (s *Store) ListEvaluations(ctx context.Context, versionID sql.NullString) ([]sqlc.Evaluation, error) {
+	if visible, err := versionVisible(ctx, s.queries, versionID.String); !visible {
+		return nil, err
+	}
+	return s.queries.ListEvaluations(ctx, versionID)
+}
+
+// ListVersionScoreStats aggregates the evaluation scores of a prompt of
+// the context's workspace
+func (s *Store) ListVersionScoreStats(ctx context.Context, arg sqlc.ListVersionScoreStatsParams) ([]sqlc.ListVersionScoreStatsRow, error) {
+	if visible, err := promptVisible(ctx, s.queries, arg.PromptID.String); !visible {
+		return nil, err
+	}
+	return s.queries.ListVersionScoreStats(ctx, arg)
+}
+
+// GetRun returns a run. Runs of a prompt are only visible in the prompt's
+// workspace; runs of ad-hoc messages have no workspace.
+func (s *Store) GetRun(ctx context.Context, id string) (sqlc.Run, error) {
+	run, err := s.queries.GetRun(ctx, id)
+	if err != nil || !run.PromptID.Valid {
+		return run, err
+	}
+	if err := checkPrompt(ctx, s.queries, run.PromptID.String); err != nil {
+		return sqlc.Run{}, err
+	}
+	return run, nil
+}
+
+// CreateRun records a run. Runs of a prompt can only be recorded in the
+// prompt's workspace.
+func (s *Store) CreateRun(ctx context.Context, arg sqlc.CreateRunParams) (sqlc.Run, error) {
+	if arg.PromptID.Valid {
+		if err := checkPrompt(ctx, s.queries, arg.PromptID.String); err != nil {
+			return sqlc.Run{}, err
+		}
+	}
+	return s.queries.CreateRun(ctx, arg)
+}
+
+// ListRunsByVersion returns the runs of a version of a prompt of the
+// context's workspace
+func (s *Store) ListRunsByVersion(ctx context.Context, arg sqlc.ListRunsByVersionParams) ([]sqlc.Run, error) {
+	if visible, err := versionVisible(ctx, s.queries, arg.PromptVersionID.String); !visible {
+		return nil, err
+	}
+	return s.queries.ListRunsByVersion(ctx, arg)
+}
+
+// ListRunsByPrompt returns the runs of a prompt of the context's workspace
+func (s *Store) ListRunsByPrompt(ctx context.Context, arg sqlc.ListRunsByPromptParams) ([]sqlc.Run, error) {
+	if visible, err := promptVisible(ctx, s.queries, arg.PromptID.String); !visible {
+		return nil, err
+	}
+	return s.queries.ListRunsByPrompt(ctx, arg)
+}
+
+// CreateEvalRun creates an eval run of a version of a prompt of the
+// context's workspace
+func (s *Store) CreateEvalRun(ctx context.Context, arg sqlc.CreateEvalRunParams) (sqlc.EvalRun, error) {
+	if err := checkPrompt(ctx, s.queries, arg.PromptID); err != nil {
+		return sqlc.EvalRun{}, err
+	}
+	if err := checkVersion(ctx, s.queries, arg.PromptVersionID); err != nil {
+		return sqlc.EvalRun{}, err
+	}
+	return s.queries.CreateEvalRun(ctx, arg)
+}
+
+// GetEvalRun returns an eval run of a prompt of the context's workspace
+func (s *Store) GetEvalRun(ctx context.Context, id string) (sqlc.EvalRun, error) {
+	run, err := s.queries.GetEvalRun(ctx, id)
+	if err != nil {
+		return run, err
+	}
+	if err := checkPrompt(ctx, s.queries, run.PromptID); err != nil {
+		return sqlc.EvalRun{}, err
+	}
+	return run, nil
+}
+
+// StartEvalRun marks an eval run of a prompt of the context's workspace as
+// running
+func (s *Store) StartEvalRun(ctx context.Context, id string) error {
+	if err := checkEvalRun(ctx, s.queries, id); err != nil {
+		return err
+	}
+	return s.queries.StartEvalRun(ctx, id)
+}
+
+// FinishEvalRun records the outcome of an eval run of a prompt of the
+// context's workspace
+func (s *Store) FinishEvalRun(ctx context.Context, arg sqlc.FinishEvalRunParams) error {
+	if err := checkEvalRun(ctx, s.queries, arg.ID); err != nil {
+		return err
+	}
+	return s.queries.FinishEvalRun(ctx, arg)
+}
+
+// ListEvaluationsByEvalRun returns the scorer evaluations of an eval run
+// of a prompt of the context's workspace
+func (s *Store) ListEvaluationsByEvalRun(ctx context.Context, evalRunID string) ([]sqlc.Evaluation, error) {
+	if _, err := s.GetEvalRun(ctx, evalRunID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s.queries.ListEvaluationsByEvalRun(ctx, evalRunID)
+}
+
+// ListEvalRunsByVersion returns the eval runs of a version of a prompt of
+// the context's workspace
+func (s *Store) ListEvalRunsByVersion(ctx context.Context, versionID string) ([]sqlc.EvalRun, error) {
+	if visible, err := versionVisible(ctx, s.queries, versionID); !visible {
+		return nil, err
+	}
+	return s.queries.ListEvalRunsByVersion(ctx, versionID)
+}
+
+// GetReusableEvalRun finds an earlier eval run of a version of a prompt of
+// the context's workspace
+func (s *Store) GetReusableEvalRun(ctx context.Context, arg sqlc.GetReusableEvalRunParams) (sqlc.EvalRun, error) {
+	if err := checkVersion(ctx, s.queries, arg.PromptVersionID); err != nil {
+		return sqlc.EvalRun{}, err
+	}
+	return s.queries.GetReusableEvalRun(ctx, arg)
+}
+
+// ListEvalRunResults returns the results of an eval run of a prompt of the
+// context's workspace
+func (s *Store) ListEvalRunResults(ctx context.Context, evalRunID string) ([]sqlc.EvalRunResult, error) {
+	if err := checkEvalRun(ctx, s.queries, evalRunID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s.queries.ListEvalRunResults(ctx, evalRunID)
+}
+
+// GetEvalRunSummary aggregates the results of an eval run of a prompt of
+// the context's workspace
+func (s *Store) GetEvalRunSummary(ctx context.Context, evalRunID string) (sqlc.GetEvalRunSummaryRow, error) {
+	if err := checkEvalRun(ctx, s.queries, evalRunID); err != nil {
+		return sqlc.GetEvalRunSummaryRow{}, err
+	}
+	return s.queries.GetEvalRunSummary(ctx, evalRunID)
+}
+
+// ListEvalRunScorerSummaries aggregates the scorer evaluations of an eval
+// run of a prompt of the context's workspace
+func (s *Store) ListEvalRunScorerSummaries(ctx context.Context, evalRunID string) ([]sqlc.ListEvalRunScorerSummariesRow, error) {
+	if err := checkEvalRun(ctx, s.queries, evalRunID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s.queries.ListEvalRunScorerSummaries(ctx, evalRunID)
+}
+
+// GetReviewPair picks a pair to review from the context's workspace
+func (s *Store) GetReviewPair(ctx context.Context, arg sqlc.GetReviewPairParams) (sqlc.GetReviewPairRow, error) {
+	arg.WorkspaceID = WorkspaceFromContext(ctx)
+	return s.queries.GetReviewPair(ctx, arg)
+}
+
+// GetReviewResult returns an eval run result of a prompt of the context's
+// workspace
+func (s *Store) GetReviewResult(ctx context.Context, id int64) (sqlc.GetReviewResultRow, error) {
+	result, err := s.queries.GetReviewResult(ctx, id)
+	if err != nil {
+		return result, err
+	}
+	if err := checkPrompt(ctx, s.queries, result.PromptID); err != nil {
+		return sqlc.GetReviewResultRow{}, err
+	}
+	return result, nil
+}
+
+// ListPairwiseJudgmentCounts counts the judgments of a prompt of the
+// context's workspace
+func (s *Store) ListPairwiseJudgmentCounts(ctx context.Context, promptID string) ([]sqlc.ListPairwiseJudgmentCountsRow, error) {
+	if visible, err := promptVisible(ctx, s.queries, promptID); !visible {
+		return nil, err
+	}
+	return s.queries.ListPairwiseJudgmentCounts(ctx, promptID)
+}
+
+// CreatePairwiseJudgment records a judgment on a prompt of the context's
+// workspace
+func (s *Store) CreatePairwiseJudgment(ctx context.Context, arg sqlc.CreatePairwiseJudgmentParams) (sqlc.PairwiseJudgment, error) {
+	if err := checkPrompt(ctx, s.queries, arg.PromptID); err != nil {
+		return sqlc.PairwiseJudgment{}, err
+	}
+	return s.queries.CreatePairwiseJudgment(ctx, arg)
+}
+
+// GetLabel returns a label of a prompt of the context's workspace
+func (s *Store) GetLabel(ctx context.Context, arg sqlc.GetLabelParams) (sqlc.PromptLabel, error) {
+	if err := checkPrompt(ctx, s.queries, arg.PromptID); err != nil {
+		return sqlc.PromptLabel{}, err
+	}
+	return s.queries.GetLabel(ctx, arg)
+}
+
+// ListLabels returns the labels of a prompt of the context's workspace
+func (s *Store) ListLabels(ctx context.Context, promptID string) ([]sqlc.PromptLabel, error) {
+	if visible, err := promptVisible(ctx, s.queries, promptID); !visible {
+		return nil, err
+	}
+	return s.queries.ListLabels(ctx, promptID)
+}
+
+// ListLabelHistory returns the moves of a label of a prompt of the
+// context's workspace
+func (s *Store) ListLabelHistory(ctx context.Context, arg sqlc.ListLabelHistoryParams) ([]sqlc.PromptLabelHistory, error) {
+	if visible, err := promptVisible(ctx, s.queries, arg.PromptID); !visible {
+		return nil, err
+	}
+	return s.queries.ListLabelHistory(ctx, arg)
+}
+
+// ListPromptTagNames returns the tag names of a prompt of the context's
+// workspace
+func (s *Store) ListPromptTagNames(ctx context.Context, promptID string) ([]string, error) {
+	if visible, err := promptVisible(ctx, s.queries, promptID); !visible {
+		return nil, err
+	}
+	return s.queries.ListPromptTagNames(ctx, promptID)
+}
+
+// UpsertTag returns the tag of the context's workspace with the name,
+// creating it if needed
+func (s *Store) UpsertTag(ctx context.Context, name string) (sqlc.Tag, error) {
+	return s.queries.UpsertTag(ctx, sqlc.UpsertTagParams{Name: name, WorkspaceID: WorkspaceFromContext(ctx)})
+}
+
+// GetTagByName returns a tag of the context's workspace
+func (s *Store) GetTagByName(ctx context.Context, name string) (sqlc.Tag, error) {
+	return s.queries.GetTagByName(ctx, sqlc.GetTagByNameParams{WorkspaceID: WorkspaceFromContext(ctx), Name: name})
+}
+
+// GetCollection returns a collection of the context's workspace
+func (s *Store) GetCollection(ctx context.Context, id string) (sqlc.Collection, error) {
+	return s.queries.GetCollection(ctx, sqlc.GetCollectionParams{WorkspaceID: WorkspaceFromContext(ctx), ID: id})
+}
+
+// ListCollections returns the collections of the context's workspace
+func (s *Store) ListCollections(ctx context.Context) ([]sqlc.Collection, error) {
+	return s.queries.ListCollections(ctx, WorkspaceFromContext(ctx))
+}
+
+// GetDataset returns a dataset of the context's workspace
+func (s *Store) GetDataset(ctx context.Context, id string) (sqlc.Dataset, error) {
+	return s.queries.GetDataset(ctx, sqlc.GetDatasetParams{WorkspaceID: WorkspaceFromContext(ctx), ID: id})
+}
+
+// ListDatasets returns the datasets of the context's workspace
+func (s *Store) ListDatasets(ctx context.Context) ([]sqlc.Dataset, error) {
+	return s.queries.ListDatasets(ctx, WorkspaceFromContext(ctx))
+}
+
+// ListDatasetRows returns the rows of a dataset of the context's workspace
+func (s *Store) ListDatasetRows(ctx context.Context, datasetID string) ([]sqlc.DatasetRow, error) {
+	if err := checkDataset(ctx, s.queries, datasetID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s.queries.ListDatasetRows(ctx, datasetID)
+}
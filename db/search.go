@@ -0,0 +1,26 @@
+package db
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MatchQuery turns free text into an FTS5 query that matches prompts
+// containing every word, treating the last word as a prefix so results
+// update while typing. FTS5 operators and punctuation in the input are
+// ignored. It returns "" when the text has no words.
+func MatchQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = `"` + word + `"`
+	}
+	terms[len(terms)-1] += "*"
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+)
+
+// ErrTagTaken is returned when renaming a tag to a name already in use
+var ErrTagTaken = errors.New("tag name is already in use")
+
+// SetPromptTags replaces a prompt's tags with names, creating tags that do
+// not exist yet, and returns the prompt's tag names in order. It returns
+// sql.ErrNoRows unless the prompt is in the context's workspace.
+func (s *Store) SetPromptTags(ctx context.Context, promptID string, names []string) ([]string, error) {
+	var tags []string
+
+	err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+		if err := checkPrompt(ctx, q, promptID); err != nil {
+			return err
+		}
+		if err := q.DeletePromptTagsByPrompt(ctx, promptID); err != nil {
+			return err
+		}
+		for _, name := range names {
+			tag, err := q.UpsertTag(ctx, sqlc.UpsertTagParams{Name: name, WorkspaceID: WorkspaceFromContext(ctx)})
+			if err != nil {
+				return err
+			}
+			err = q.AddPromptTag(ctx, sqlc.AddPromptTagParams{PromptID: promptID, TagID: tag.ID})
+			if err != nil {
+				return err
+			}
+		}
+
+		var err error
+		tags, err = q.ListPromptTagNames(ctx, promptID)
+		return err
+	})
+
+	return tags, err
+}
+
+// RenameTag changes the name of a tag of the context's workspace,
+// returning ErrTagTaken if another tag there already has it
+func (s *Store) RenameTag(ctx context.Context, id int64, name string) (sqlc.Tag, error) {
+	var tag sqlc.Tag
+
+	err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		arg := sqlc.RenameTagParams{Name: name, WorkspaceID: WorkspaceFromContext(ctx), ID: id}
+		if tag, err = q.RenameTag(ctx, arg); err != nil {
+			return err
+		}
+		return q.BumpTaggedPromptRevisions(ctx, id)
+	})
+	if isUniqueViolation(err) {
+		return tag, ErrTagTaken
+	}
+
+	return tag, err
+}
+
+// DeleteTag removes a tag of the context's workspace from every prompt and
+// deletes it
+func (s *Store) DeleteTag(ctx context.Context, id int64) error {
+	return s.executeTx(ctx, func(q *sqlc.Queries) error {
+		if _, err := q.GetTag(ctx, sqlc.GetTagParams{WorkspaceID: WorkspaceFromContext(ctx), ID: id}); err != nil {
+			return err
+		}
+		if err := q.BumpTaggedPromptRevisions(ctx, id); err != nil {
+			return err
+		}
+		if err := q.DeletePromptTagsByTag(ctx, id); err != nil {
+			return err
+		}
+		return q.DeleteTag(ctx, id)
+	})
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+)
+
+// ErrEmailTaken is returned when another user already has the email
+var ErrEmailTaken = errors.New("email is already in use")
+
+// CreateUser adds a user, returning ErrEmailTaken if the email is already
+// registered
+func (s *Store) CreateUser(ctx context.Context, arg sqlc.CreateUserParams) (sqlc.User, error) {
+	user, err := s.queries.CreateUser(ctx, arg)
+	if isUniqueViolation(err) {
+		return user, ErrEmailTaken
+	}
+	return user, err
+}
+
+// CreateWorkspaceUser adds a user as a member of the context's workspace,
+// returning ErrEmailTaken if the email is already registered
+func (s *Store) CreateWorkspaceUser(ctx context.Context, arg sqlc.CreateUserParams) (sqlc.User, error) {
+	var user sqlc.User
+
+	err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		if user, err = q.CreateUser(ctx, arg); err != nil {
+			return err
+		}
+		_, err = q.UpsertWorkspaceMember(ctx, sqlc.UpsertWorkspaceMemberParams{
+			WorkspaceID: WorkspaceFromContext(ctx),
+			UserID:      user.ID,
+			Role:        RoleMember,
+		})
+		return err
+	})
+	if isUniqueViolation(err) {
+		return user, ErrEmailTaken
+	}
+
+	return user, err
+}
+
+// UpdateUser changes a user's name and email, returning ErrEmailTaken if
+// another user has the email
+func (s *Store) UpdateUser(ctx context.Context, arg sqlc.UpdateUserParams) (sqlc.User, error) {
+	user, err := s.queries.UpdateUser(ctx, arg)
+	if isUniqueViolation(err) {
+		return user, ErrEmailTaken
+	}
+	return user, err
+}
+
+// DeleteUser deletes a user, their API keys and their workspace
+// memberships. Records the user created keep their ID as the author.
+func (s *Store) DeleteUser(ctx context.Context, id string) error {
+	return s.executeTx(ctx, func(q *sqlc.Queries) error {
+		if _, err := q.GetUser(ctx, id); err != nil {
+			return err
+		}
+		if err := q.DeleteAPIKeysByUser(ctx, id); err != nil {
+			return err
+		}
+		if err := q.DeleteWorkspaceMembersByUser(ctx, id); err != nil {
+			return err
+		}
+		return q.DeleteUser(ctx, id)
+	})
+}
+
+// GetUser returns any user. Users are not part of a workspace, so handlers
+// acting for a workspace look users up with GetWorkspaceUser instead.
+func (s *Store) GetUser(ctx context.Context, id string) (sqlc.User, error) {
+	return s.queries.GetUser(ctx, id)
+}
+
+// GetUserByEmail returns any user by their email
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (sqlc.User, error) {
+	return s.queries.GetUserByEmail(ctx, email)
+}
+
+// EnsureUser records a user the first time they are seen, leaving existing
+// users untouched
+func (s *Store) EnsureUser(ctx context.Context, arg sqlc.EnsureUserParams) error {
+	return s.queries.EnsureUser(ctx, arg)
+}
+
+// GetWorkspaceUser returns a user who is a member of the context's
+// workspace
+func (s *Store) GetWorkspaceUser(ctx context.Context, id string) (sqlc.User, error) {
+	return s.queries.GetWorkspaceUser(ctx, sqlc.GetWorkspaceUserParams{WorkspaceID: WorkspaceFromContext(ctx), ID: id})
+}
+
+// ListWorkspaceUsers returns the members of the context's workspace
+func (s *Store) ListWorkspaceUsers(ctx context.Context) ([]sqlc.User, error) {
+	return s.queries.ListWorkspaceUsers(ctx, WorkspaceFromContext(ctx))
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+)
+
+// maxVersionAttempts bounds how often version allocation is retried when
+// another writer gets there first
+const maxVersionAttempts = 10
+
+// ErrRevisionMismatch is returned when a write was conditional on a prompt
+// revision that is no longer current
+var ErrRevisionMismatch = errors.New("prompt revision does not match")
+
+// CreateNextVersionParams contains the input parameters of CreateNextVersion
+type CreateNextVersionParams struct {
+	sqlc.CreateVersionParams
+	// IfRevision, when valid, must equal the prompt's current revision
+	IfRevision sql.NullInt64
+}
+
+// RestoreVersionParams contains the input parameters of RestoreVersion
+type RestoreVersionParams struct {
+	ID         string
+	PromptID   string
+	Version    int64
+	CreatedBy  sql.NullString
+	IfRevision sql.NullInt64
+}
+
+// CreateNextVersion inserts a version numbered one past the prompt's latest
+// version and bumps the prompt's revision. arg.Version is ignored.
+// Allocation is serialized within the process and retried when another
+// writer takes the number first.
+func (s *Store) CreateNextVersion(ctx context.Context, arg CreateNextVersionParams) (sqlc.PromptVersion, error) {
+	var version sqlc.PromptVersion
+
+	err := s.executeVersionTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		version, err = createNextVersion(ctx, q, arg.CreateVersionParams, arg.IfRevision)
+		return err
+	})
+
+	return version, err
+}
+
+// RestoreVersion creates a new latest version of a prompt that copies the
+// messages and inputs of an earlier one and records where it came from
+func (s *Store) RestoreVersion(ctx context.Context, arg RestoreVersionParams) (sqlc.PromptVersion, error) {
+	var version sqlc.PromptVersion
+
+	err := s.executeVersionTx(ctx, func(q *sqlc.Queries) error {
+		source, err := q.GetVersionByPromptAndNumber(ctx, sqlc.GetVersionByPromptAndNumberParams{
+			PromptID: sql.NullString{String: arg.PromptID, Valid: true},
+			Version:  arg.Version,
+		})
+		if err != nil {
+			return err
+		}
+
+		version, err = createNextVersion(ctx, q, sqlc.CreateVersionParams{
+			ID:           arg.ID,
+			PromptID:     source.PromptID,
+			Content:      source.Content,
+			Inputs:       source.Inputs,
+			RestoredFrom: sql.NullInt64{Int64: source.Version, Valid: true},
+			CreatedBy:    arg.CreatedBy,
+		}, arg.IfRevision)
+		return err
+	})
+
+	return version, err
+}
+
+// executeVersionTx runs fn in a transaction while holding the version
+// lock, retrying with jittered backoff if the transaction loses a race
+// with another connection or process
+func (s *Store) executeVersionTx(ctx context.Context, fn func(*sqlc.Queries) error) error {
+	s.versionMu.Lock()
+	defer s.versionMu.Unlock()
+
+	backoff := 5 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		err := s.executeTx(ctx, fn)
+		if err == nil || !isWriteConflict(err) || attempt == maxVersionAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff + time.Duration(rand.Int63n(int64(backoff)))):
+		}
+		backoff *= 2
+	}
+}
+
+// createNextVersion inserts arg as the prompt's next version using q,
+// provided the prompt is in the context's workspace and at ifRevision
+// when that is set
+func createNextVersion(ctx context.Context, q *sqlc.Queries, arg sqlc.CreateVersionParams, ifRevision sql.NullInt64) (sqlc.PromptVersion, error) {
+	bumped, err := q.BumpPromptRevision(ctx, sqlc.BumpPromptRevisionParams{
+		ID:          arg.PromptID.String,
+		WorkspaceID: WorkspaceFromContext(ctx),
+		IfRevision:  ifRevision,
+	})
+	if err != nil {
+		return sqlc.PromptVersion{}, err
+	}
+	if bumped == 0 {
+		if ifRevision.Valid {
+			return sqlc.PromptVersion{}, ErrRevisionMismatch
+		}
+		return sqlc.PromptVersion{}, sql.ErrNoRows
+	}
+
+	latest, err := q.GetLatestVersionNumber(ctx, arg.PromptID)
+	if err != nil {
+		return sqlc.PromptVersion{}, err
+	}
+	latestVersion, _ := latest.(int64)
+
+	arg.Version = latestVersion + 1
+	return q.CreateVersion(ctx, arg)
+}
+
+// isWriteConflict reports whether err means another writer held the
+// database or already used the version number, so a retry may succeed
+func isWriteConflict(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	switch sqliteErr.Code() & 0xff {
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+		return true
+	}
+	return isUniqueViolation(err)
+}
+
+// isUniqueViolation reports whether err was caused by a UNIQUE constraint
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+)
+
+// DefaultWorkspaceID is the workspace that holds everything created before
+// workspaces existed. It is used when a context carries no workspace.
+const DefaultWorkspaceID = "default"
+
+// Roles of workspace members. Owners manage the membership.
+const (
+	RoleOwner  = "owner"
+	RoleMember = "member"
+)
+
+// ErrLastOwner is returned when a change would leave a workspace without
+// an owner
+var ErrLastOwner = errors.New("workspace must keep at least one owner")
+
+type workspaceKey struct{}
+
+// WithWorkspace returns a copy of ctx that scopes store calls to the
+// workspace
+func WithWorkspace(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, id)
+}
+
+// WorkspaceFromContext returns the workspace store calls made with ctx are
+// scoped to
+func WorkspaceFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(workspaceKey{}).(string); ok && id != "" {
+		return id
+	}
+	return DefaultWorkspaceID
+}
+
+// CreateWorkspace creates a workspace with ownerID as its first owner
+func (s *Store) CreateWorkspace(ctx context.Context, arg sqlc.CreateWorkspaceParams, ownerID string) (sqlc.Workspace, error) {
+	var workspace sqlc.Workspace
+
+	err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		if workspace, err = q.CreateWorkspace(ctx, arg); err != nil {
+			return err
+		}
+		_, err = q.UpsertWorkspaceMember(ctx, sqlc.UpsertWorkspaceMemberParams{
+			WorkspaceID: workspace.ID,
+			UserID:      ownerID,
+			Role:        RoleOwner,
+		})
+		return err
+	})
+
+	return workspace, err
+}
+
+// SetWorkspaceMember adds a user to a workspace or changes their role. It
+// returns ErrLastOwner if the user is the workspace's only owner and would
+// stop being one.
+func (s *Store) SetWorkspaceMember(ctx context.Context, arg sqlc.UpsertWorkspaceMemberParams) (sqlc.WorkspaceMember, error) {
+	var member sqlc.WorkspaceMember
+
+	err := s.executeTx(ctx, func(q *sqlc.Queries) error {
+		if arg.Role != RoleOwner {
+			if err := keepOwner(ctx, q, arg.WorkspaceID, arg.UserID); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		member, err = q.UpsertWorkspaceMember(ctx, arg)
+		return err
+	})
+
+	return member, err
+}
+
+// RemoveWorkspaceMember removes a user from a workspace. Their API keys for
+// the workspace stop working. It returns sql.ErrNoRows if the user is not
+// a member and ErrLastOwner if they are its only owner.
+func (s *Store) RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	return s.executeTx(ctx, func(q *sqlc.Queries) error {
+		if err := keepOwner(ctx, q, workspaceID, userID); err != nil {
+			return err
+		}
+
+		removed, err := q.DeleteWorkspaceMember(ctx, sqlc.DeleteWorkspaceMemberParams{WorkspaceID: workspaceID, UserID: userID})
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// keepOwner returns ErrLastOwner if userID is the workspace's only owner
+func keepOwner(ctx context.Context, q *sqlc.Queries, workspaceID, userID string) error {
+	member, err := q.GetWorkspaceMember(ctx, sqlc.GetWorkspaceMemberParams{WorkspaceID: workspaceID, UserID: userID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if member.Role != RoleOwner {
+		return nil
+	}
+
+	owners, err := q.CountWorkspaceOwners(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// GetWorkspace returns a workspace by ID
+func (s *Store) GetWorkspace(ctx context.Context, id string) (sqlc.Workspace, error) {
+	return s.queries.GetWorkspace(ctx, id)
+}
+
+// GetWorkspaceMember returns a user's membership of a workspace
+func (s *Store) GetWorkspaceMember(ctx context.Context, arg sqlc.GetWorkspaceMemberParams) (sqlc.WorkspaceMember, error) {
+	return s.queries.GetWorkspaceMember(ctx, arg)
+}
+
+// ListWorkspacesByUser returns the workspaces a user belongs to
+func (s *Store) ListWorkspacesByUser(ctx context.Context, userID string) ([]sqlc.ListWorkspacesByUserRow, error) {
+	return s.queries.ListWorkspacesByUser(ctx, userID)
+}
+
+// ListWorkspaceMembers returns the members of a workspace
+func (s *Store) ListWorkspaceMembers(ctx context.Context, workspaceID string) ([]sqlc.ListWorkspaceMembersRow, error) {
+	return s.queries.ListWorkspaceMembers(ctx, workspaceID)
+}
@@ -0,0 +1,191 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+)
+
+func TestWorkspaceScoping(t *testing.T) {
+	sqlDB, err := Connect("file:" + filepath.Join(t.TempDir(), "test.db") + "?_foreign_keys=on")
+	require.NoError(t, err)
+	require.NoError(t, RunMigrations(sqlDB))
+	store := NewStore(sqlDB)
+	defer store.Close()
+
+	bg := context.Background()
+	for _, id := range []string{"ana", "bo"} {
+		_, err := store.CreateUser(bg, sqlc.CreateUserParams{ID: id, Name: id, Email: id + "@example.com"})
+		require.NoError(t, err)
+		_, err = store.CreateWorkspace(bg, sqlc.CreateWorkspaceParams{ID: "team-" + id, Name: id}, id)
+		require.NoError(t, err)
+	}
+	teamA := WithWorkspace(bg, "team-ana")
+	teamB := WithWorkspace(bg, "team-bo")
+	promptID := sql.NullString{String: "prompt-a", Valid: true}
+
+	_, err = store.CreatePrompt(teamA, sqlc.CreatePromptParams{ID: "prompt-a", Title: "Launch plan", Slug: "launch"})
+	require.NoError(t, err)
+	version, err := store.CreateNextVersion(teamA, CreateNextVersionParams{CreateVersionParams: sqlc.CreateVersionParams{
+		ID:       "version-a",
+		PromptID: promptID,
+		Content:  "[]",
+	}})
+	require.NoError(t, err)
+	_, err = store.CreateComment(teamA, sqlc.CreateCommentParams{ID: "comment-a", PromptID: promptID, Content: "Ship it"})
+	require.NoError(t, err)
+	_, err = store.CreateEvaluations(teamA, []sqlc.CreateEvaluationParams{{ID: "eval-a", PromptVersionID: sql.NullString{String: version.ID, Valid: true}, Scorer: "manual"}})
+	require.NoError(t, err)
+	_, err = store.CreateRun(teamA, sqlc.CreateRunParams{ID: "run-a", PromptID: promptID, Model: "fake/echo", Parameters: "{}", Messages: "[]"})
+	require.NoError(t, err)
+
+	// Contexts without a workspace use the default one
+	assert.Equal(t, DefaultWorkspaceID, WorkspaceFromContext(bg))
+	prompts, err := store.ListPrompts(bg)
+	require.NoError(t, err)
+	assert.Empty(t, prompts)
+
+	// Another workspace can't read the prompt or anything that belongs to it
+	_, err = store.GetPrompt(teamB, "prompt-a")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.GetPromptBySlug(teamB, sqlc.GetPromptBySlugParams{Slug: "launch"})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.GetVersion(teamB, version.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.GetComment(teamB, "comment-a")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.GetEvaluation(teamB, "eval-a")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.GetRun(teamB, "run-a")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	versions, _, err := store.ListVersionsPage(teamB, "prompt-a", ListFilter{}, PageParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+	comments, err := store.ListComments(teamB, promptID)
+	require.NoError(t, err)
+	assert.Empty(t, comments)
+	evals, err := store.ListEvaluations(teamB, sql.NullString{String: version.ID, Valid: true})
+	require.NoError(t, err)
+	assert.Empty(t, evals)
+	rows, err := store.SearchPrompts(teamB, sqlc.SearchPromptsParams{Query: "launch", Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+	runs, err := store.ListRunsByPrompt(teamB, sqlc.ListRunsByPromptParams{PromptID: promptID, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+	_, err = store.GetLabel(teamB, sqlc.GetLabelParams{PromptID: "prompt-a", Name: "production"})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// ...nor change it
+	_, err = store.CreateNextVersion(teamB, CreateNextVersionParams{CreateVersionParams: sqlc.CreateVersionParams{ID: "version-b", PromptID: promptID, Content: "[]"}})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.CreateComment(teamB, sqlc.CreateCommentParams{ID: "comment-b", PromptID: promptID, Content: "Leaked?"})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.CreateEvaluations(teamB, []sqlc.CreateEvaluationParams{{ID: "eval-b", PromptVersionID: sql.NullString{String: version.ID, Valid: true}, Scorer: "manual"}})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.MoveLabel(teamB, MoveLabelParams{PromptID: "prompt-a", Name: "production", Version: 1})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.SetPromptTags(teamB, "prompt-a", []string{"leaked"})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.RenamePromptSlug(teamB, RenamePromptSlugParams{ID: "prompt-a", Slug: "taken"})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	deleted, err := store.SoftDeletePrompt(teamB, sqlc.SoftDeletePromptParams{ID: "prompt-a"})
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+	assert.ErrorIs(t, store.PurgePrompt(teamB, "prompt-a", sql.NullInt64{}), sql.ErrNoRows)
+	err = store.ExecuteTx(teamB, func(tx *Store) error {
+		_, err := tx.GetPrompt(teamB, "prompt-a")
+		return err
+	})
+	assert.ErrorIs(t, err, sql.ErrNoRows, "transactions are scoped too")
+
+	// The owning workspace still sees everything
+	prompt, err := store.GetPrompt(teamA, "prompt-a")
+	require.NoError(t, err)
+	assert.Equal(t, "team-ana", prompt.WorkspaceID)
+	assert.Equal(t, int64(2), prompt.Revision, "rejected writes must not bump the revision")
+	comments, err = store.ListComments(teamA, promptID)
+	require.NoError(t, err)
+	assert.Len(t, comments, 1)
+	evals, err = store.ListEvaluations(teamA, sql.NullString{String: version.ID, Valid: true})
+	require.NoError(t, err)
+	assert.Len(t, evals, 1)
+
+	// API keys belong to the workspace they were issued in
+	_, _, err = store.IssueAPIKey(teamA, "ana", "laptop")
+	require.NoError(t, err)
+	keys, err := store.ListAPIKeysByUser(teamB, "ana")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+	keys, err = store.ListAPIKeysByUser(teamA, "ana")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	revoked, err := store.RevokeAPIKey(teamB, sqlc.RevokeAPIKeyParams{ID: keys[0].ID, UserID: "ana"})
+	require.NoError(t, err)
+	assert.Zero(t, revoked)
+
+	// Tags, collections and datasets belong to a workspace, so both can use
+	// the same names without seeing each other's
+	_, err = store.SetPromptTags(teamA, "prompt-a", []string{"prod"})
+	require.NoError(t, err)
+	tag, err := store.GetTagByName(teamA, "prod")
+	require.NoError(t, err)
+	_, err = store.GetTagByName(teamB, "prod")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.RenameTag(teamB, tag.ID, "hijacked")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.ErrorIs(t, store.DeleteTag(teamB, tag.ID), sql.ErrNoRows)
+	tagB, err := store.UpsertTag(teamB, "prod")
+	require.NoError(t, err)
+	assert.NotEqual(t, tag.ID, tagB.ID)
+	tags, err := store.ListTags(teamB)
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	assert.Zero(t, tags[0].PromptCount)
+
+	_, err = store.CreateCollection(teamA, sqlc.CreateCollectionParams{ID: "collection-a", Name: "Drafts"})
+	require.NoError(t, err)
+	_, err = store.CreateCollection(teamB, sqlc.CreateCollectionParams{ID: "collection-b", Name: "Drafts"})
+	require.NoError(t, err)
+	_, err = store.GetCollection(teamB, "collection-a")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.UpdateCollection(teamB, sqlc.UpdateCollectionParams{ID: "collection-b", Name: "Drafts", ParentID: sql.NullString{String: "collection-a", Valid: true}})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.ErrorIs(t, store.DeleteCollection(teamB, "collection-a"), sql.ErrNoRows)
+	collections, err := store.ListCollections(teamB)
+	require.NoError(t, err)
+	require.Len(t, collections, 1)
+	assert.Equal(t, "collection-b", collections[0].ID)
+
+	_, _, err = store.CreateDataset(teamA, sqlc.CreateDatasetParams{ID: "dataset-a", Name: "Golden"}, []sqlc.CreateDatasetRowParams{{Variables: "{}"}})
+	require.NoError(t, err)
+	_, _, err = store.CreateDataset(teamB, sqlc.CreateDatasetParams{ID: "dataset-b", Name: "Golden"}, nil)
+	require.NoError(t, err)
+	_, err = store.GetDataset(teamB, "dataset-a")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	datasetRows, err := store.ListDatasetRows(teamB, "dataset-a")
+	require.NoError(t, err)
+	assert.Empty(t, datasetRows)
+	_, err = store.AddDatasetRows(teamB, "dataset-a", []sqlc.CreateDatasetRowParams{{Variables: "{}"}})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.ErrorIs(t, store.DeleteDataset(teamB, "dataset-a"), sql.ErrNoRows)
+	datasetRows, err = store.ListDatasetRows(teamA, "dataset-a")
+	require.NoError(t, err)
+	assert.Len(t, datasetRows, 1)
+
+	// Workspaces keep an owner
+	assert.ErrorIs(t, store.RemoveWorkspaceMember(bg, "team-ana", "ana"), ErrLastOwner)
+	_, err = store.SetWorkspaceMember(bg, sqlc.UpsertWorkspaceMemberParams{WorkspaceID: "team-ana", UserID: "ana", Role: RoleMember})
+	assert.ErrorIs(t, err, ErrLastOwner)
+	_, err = store.SetWorkspaceMember(bg, sqlc.UpsertWorkspaceMemberParams{WorkspaceID: "team-ana", UserID: "bo", Role: RoleOwner})
+	require.NoError(t, err)
+	assert.NoError(t, store.RemoveWorkspaceMember(bg, "team-ana", "ana"))
+	assert.ErrorIs(t, store.RemoveWorkspaceMember(bg, "team-ana", "ana"), sql.ErrNoRows)
+}
@@ -1,7 +1,8 @@
 'use client';
 
-import { useState } from 'react';
+import { useEffect, useState } from 'react';
 import { createClient } from '@/utils/supabase/client';
+import { getApiKey, setApiKey } from '@/lib/api';
 import { toast } from 'sonner';
 
 interface ApiKeyFormProps {
@@ -12,6 +13,33 @@ export function ApiKeyForm({ userId }: ApiKeyFormProps) {
   const [name, setName] = useState('');
   const [isLoading, setIsLoading] = useState(false);
   const [newKey, setNewKey] = useState<string | null>(null);
+  const [sessionKey, setSessionKey] = useState('');
+  const [hasSessionKey, setHasSessionKey] = useState(false);
+
+  useEffect(() => {
+    setHasSessionKey(getApiKey() !== null);
+  }, []);
+
+  const handleUseKey = (e: React.FormEvent) => {
+    e.preventDefault();
+
+    if (!sessionKey.trim()) {
+      toast.error('Please enter your API key');
+      return;
+    }
+
+    // Kept in session storage so it is gone when the browser closes
+    setApiKey(sessionKey.trim());
+    setSessionKey('');
+    setHasSessionKey(true);
+    toast.success('API key saved for this session');
+  };
+
+  const handleForgetKey = () => {
+    setApiKey(null);
+    setHasSessionKey(false);
+    toast.success('API key removed from this session');
+  };
   
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
@@ -65,6 +93,43 @@ export function ApiKeyForm({ userId }: ApiKeyFormProps) {
   
   return (
     <div>
+      <form onSubmit={handleUseKey} className="space-y-4 mb-8">
+        <div>
+          <label htmlFor="session-key" className="block text-sm font-medium text-gray-700 mb-1">
+            Your API Key
+          </label>
+          <input
+            id="session-key"
+            type="password"
+            value={sessionKey}
+            onChange={(e) => setSessionKey(e.target.value)}
+            className="w-full px-3 py-2 border border-gray-300 rounded-md"
+            placeholder={hasSessionKey ? 'A key is saved for this session' : 'pk_...'}
+            autoComplete="off"
+          />
+          <p className="mt-1 text-sm text-gray-500">
+            The web interface sends this key with every request until you close the browser.
+          </p>
+        </div>
+        <div className="flex gap-2">
+          <button
+            type="submit"
+            className="px-4 py-2 bg-blue-600 text-white rounded-md hover:bg-blue-700"
+          >
+            Use Key
+          </button>
+          {hasSessionKey && (
+            <button
+              type="button"
+              onClick={handleForgetKey}
+              className="px-4 py-2 bg-gray-200 text-gray-800 rounded-md hover:bg-gray-300"
+            >
+              Forget Key
+            </button>
+          )}
+        </div>
+      </form>
+
       <form onSubmit={handleSubmit} className="space-y-4">
         <div>
           <label htmlFor="name" className="block text-sm font-medium text-gray-700 mb-1">
@@ -2,6 +2,23 @@ import axios from 'axios';
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api';
 
+// The signed-in user's own API key, kept for the browser session only
+const API_KEY_STORAGE = 'kitchenai_api_key';
+
+export const getApiKey = (): string | null => {
+  if (typeof window === 'undefined') return null;
+  return window.sessionStorage.getItem(API_KEY_STORAGE);
+};
+
+export const setApiKey = (key: string | null) => {
+  if (typeof window === 'undefined') return;
+  if (key) {
+    window.sessionStorage.setItem(API_KEY_STORAGE, key);
+  } else {
+    window.sessionStorage.removeItem(API_KEY_STORAGE);
+  }
+};
+
 const api = axios.create({
   baseURL: API_URL,
   headers: {
@@ -9,6 +26,15 @@ const api = axios.create({
   },
 });
 
+// Add request interceptor to authenticate with the session's API key
+api.interceptors.request.use((config) => {
+  const key = getApiKey();
+  if (key) {
+    config.headers.Authorization = `Bearer ${key}`;
+  }
+  return config;
+});
+
 // Add response interceptor to handle errors globally
 api.interceptors.response.use(
   (response) => response.data,
@@ -65,6 +91,8 @@ export interface Eval {
   version_id: string;
   score: number;
   notes: string;
+  scorer?: string;
+  passed?: boolean;
   created_by: User;
   created_at?: string;
 }
@@ -87,11 +115,22 @@ export interface RunPromptResponse {
   };
 }
 
+export interface ListResponse<T> {
+  items: T[];
+  next_cursor?: string;
+}
+
+// List endpoints return a page of items; older servers return a bare array
+const listItems = <T>(response: ListResponse<T> | T[]): T[] => {
+  if (Array.isArray(response)) return response;
+  return Array.isArray(response?.items) ? response.items : [];
+};
+
 // Prompts
 export const getPrompts = async (): Promise<Prompt[]> => {
   try {
-    const response: Prompt[] = await api.get('/prompts');
-    return Array.isArray(response) ? response : [];
+    const response: ListResponse<Prompt> | Prompt[] = await api.get('/prompts');
+    return listItems(response);
   } catch (error) {
     console.error('API Error - getPrompts:', error);
     throw error;
@@ -140,8 +179,8 @@ export const deletePrompt = async (id: string): Promise<void> => {
 // Versions
 export const getVersions = async (promptId: string): Promise<Version[]> => {
   try {
-    const response: Version[] = await api.get(`/prompts/${promptId}/versions`);
-    return Array.isArray(response) ? response : [];
+    const response: ListResponse<Version> | Version[] = await api.get(`/prompts/${promptId}/versions`);
+    return listItems(response);
   } catch (error) {
     console.error('API Error - getVersions:', error);
     throw error;
@@ -161,8 +200,8 @@ export const createVersion = async (promptId: string, version: { messages: Messa
 // Comments
 export const getComments = async (promptId: string): Promise<Comment[]> => {
   try {
-    const response: Comment[] = await api.get(`/prompts/${promptId}/comments`);
-    return Array.isArray(response) ? response : [];
+    const response: ListResponse<Comment> | Comment[] = await api.get(`/prompts/${promptId}/comments`);
+    return listItems(response);
   } catch (error) {
     console.error('API Error - getComments:', error);
     throw error;
@@ -196,8 +235,8 @@ export const createEvaluation = async (
 
 export const getEvaluations = async (promptId: string, version: number): Promise<Eval[]> => {
   try {
-    const response: Eval[] = await api.get(`/prompts/${promptId}/versions/${version}/evals`);
-    return Array.isArray(response) ? response : [];
+    const response: ListResponse<Eval> | Eval[] = await api.get(`/prompts/${promptId}/versions/${version}/evals`);
+    return listItems(response);
   } catch (error) {
     console.error('API Error - getEvaluations:', error);
     throw error;
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/auth"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// Authenticate is middleware that verifies "Authorization: Bearer <key>"
+// API keys and puts the key's user and workspace in the request context.
+// Invalid and revoked keys, and keys of users who left the workspace, are
+// always rejected; requests without a key are only rejected when required
+// is set and otherwise use the default workspace.
+func (h *Handler) Authenticate(required bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			if header == "" {
+				if required {
+					return unauthorized(c, "Missing API key")
+				}
+				return next(c)
+			}
+
+			scheme, key, ok := strings.Cut(header, " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || key == "" {
+				return unauthorized(c, "Authorization must be a bearer API key")
+			}
+
+			row, err := h.Store.GetAPIKeyUser(c.Request().Context(), auth.HashKey(strings.TrimSpace(key)))
+			if err == sql.ErrNoRows {
+				return unauthorized(c, "Invalid API key")
+			}
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify API key: "+err.Error())
+			}
+			if err := h.Store.TouchAPIKey(c.Request().Context(), row.KeyID); err != nil {
+				log.Printf("Failed to record use of API key %s: %v", row.KeyID, err)
+			}
+
+			user := models.User{ID: row.ID, Name: row.Name, Email: row.Email, CreatedAt: row.CreatedAt.Time}
+			ctx := db.WithWorkspace(auth.WithUser(c.Request().Context(), user), row.WorkspaceID)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// unauthorized rejects a request that lacks valid credentials
+func unauthorized(c echo.Context, message string) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+	return echo.NewHTTPError(http.StatusUnauthorized, message)
+}
+
+// currentUser returns the authenticated caller
+func currentUser(c echo.Context) (models.User, error) {
+	user, ok := auth.UserFromContext(c.Request().Context())
+	if !ok {
+		return models.User{}, unauthorized(c, "An API key is required")
+	}
+	return user, nil
+}
+
+// newAPIKeyPayload describes a stored key without its hash
+func newAPIKeyPayload(key sqlc.ApiKey) models.APIKey {
+	payload := models.APIKey{
+		ID:          key.ID,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		WorkspaceID: key.WorkspaceID,
+		CreatedAt:   key.CreatedAt.Time,
+	}
+	if key.LastUsedAt.Valid {
+		payload.LastUsedAt = &key.LastUsedAt.Time
+	}
+	if key.RevokedAt.Valid {
+		payload.RevokedAt = &key.RevokedAt.Time
+	}
+	return payload
+}
+
+// CreateAPIKey issues an API key for the caller in the current workspace
+// or another workspace they belong to. The response is the only place the
+// key is ever shown.
+func (h *Handler) CreateAPIKey(c echo.Context) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	var req models.APIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Name is required")
+	}
+
+	ctx := c.Request().Context()
+	if req.WorkspaceID != "" {
+		_, err := h.Store.GetWorkspaceMember(ctx, sqlc.GetWorkspaceMemberParams{WorkspaceID: req.WorkspaceID, UserID: user.ID})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return echo.NewHTTPError(http.StatusNotFound, "Workspace not found")
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch workspace: "+err.Error())
+		}
+		ctx = db.WithWorkspace(ctx, req.WorkspaceID)
+	}
+
+	key, secret, err := h.Store.IssueAPIKey(ctx, user.ID, req.Name)
+	if err != nil {
+		if errors.Is(err, db.ErrAPIKeyNameTaken) {
+			return echo.NewHTTPError(http.StatusConflict, "An API key named "+req.Name+" already exists")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create API key: "+err.Error())
+	}
+
+	payload := newAPIKeyPayload(key)
+	payload.Key = secret
+	return c.JSON(http.StatusCreated, payload)
+}
+
+// GetAPIKeys returns the caller's API keys for the current workspace,
+// newest first, including revoked ones
+func (h *Handler) GetAPIKeys(c echo.Context) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	keys, err := h.Store.ListAPIKeysByUser(c.Request().Context(), user.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch API keys: "+err.Error())
+	}
+
+	payloads := make([]models.APIKey, len(keys))
+	for i, key := range keys {
+		payloads[i] = newAPIKeyPayload(key)
+	}
+	return c.JSON(http.StatusOK, models.ListResponse{Items: payloads})
+}
+
+// RevokeAPIKey revokes one of the caller's API keys for the current
+// workspace. Revoked keys stop working immediately but stay listed.
+func (h *Handler) RevokeAPIKey(c echo.Context) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	id := c.Param("key")
+	revoked, err := h.Store.RevokeAPIKey(c.Request().Context(), sqlc.RevokeAPIKeyParams{ID: id, UserID: user.ID})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to revoke API key: "+err.Error())
+	}
+	if revoked == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "API key not found")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "revoked", "id": id})
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/auth"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeys(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	_, err := store.CreateUser(context.Background(), sqlc.CreateUserParams{ID: "ana", Name: "Ana", Email: "ana@example.com"})
+	require.NoError(t, err)
+	join(t, store, db.DefaultWorkspaceID, "ana", db.RoleOwner)
+	_, secret, err := store.IssueAPIKey(context.Background(), "ana", "laptop")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, auth.KeyPrefix))
+
+	// call runs a handler behind the middleware as the holder of key
+	call := func(required bool, key, method, body string, next echo.HandlerFunc, params ...string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(method, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+key)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if len(params) > 0 {
+			c.SetParamNames("key")
+			c.SetParamValues(params...)
+		}
+		return rec, h.Authenticate(required)(next)(c)
+	}
+	whoami := func(c echo.Context) error {
+		user, _ := auth.UserFromContext(c.Request().Context())
+		return c.JSON(http.StatusOK, user)
+	}
+
+	// A valid key identifies its user
+	rec, err := call(true, secret, http.MethodGet, "", whoami)
+	require.NoError(t, err)
+	var user models.User
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &user))
+	assert.Equal(t, "ana", user.ID)
+	assert.Equal(t, "ana@example.com", user.Email)
+
+	// Missing keys are only rejected when auth is required; bad keys always are
+	_, err = call(true, "", http.MethodGet, "", whoami)
+	assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+	_, err = call(false, "", http.MethodGet, "", whoami)
+	assert.NoError(t, err)
+	rec, err = call(false, "pk_wrong", http.MethodGet, "", whoami)
+	assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+	assert.Equal(t, "Bearer", rec.Header().Get(echo.HeaderWWWAuthenticate))
+
+	// Anonymous callers cannot manage keys
+	_, err = call(false, "", http.MethodGet, "", h.GetAPIKeys)
+	assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+
+	// Created keys are shown once and work straight away
+	rec, err = call(true, secret, http.MethodPost, `{"name":"ci"}`, h.CreateAPIKey)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var created models.APIKey
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+	_, err = call(true, created.Key, http.MethodGet, "", whoami)
+	assert.NoError(t, err)
+
+	_, err = call(true, secret, http.MethodPost, `{"name":"ci"}`, h.CreateAPIKey)
+	assert.Equal(t, http.StatusConflict, err.(*echo.HTTPError).Code)
+	_, err = call(true, secret, http.MethodPost, `{"name":" "}`, h.CreateAPIKey)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+
+	// Listing never returns keys or their hashes
+	rec, err = call(true, secret, http.MethodGet, "", h.GetAPIKeys)
+	require.NoError(t, err)
+	assert.NotContains(t, rec.Body.String(), created.Key)
+	assert.NotContains(t, rec.Body.String(), auth.HashKey(created.Key))
+	var keys struct {
+		Items []models.APIKey `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &keys))
+	assert.Len(t, keys.Items, 2)
+
+	// Revoked keys stop working; other users' keys cannot be revoked
+	_, err = store.CreateUser(context.Background(), sqlc.CreateUserParams{ID: "bo", Name: "Bo", Email: "bo@example.com"})
+	require.NoError(t, err)
+	join(t, store, db.DefaultWorkspaceID, "bo", db.RoleMember)
+	_, other, err := store.IssueAPIKey(context.Background(), "bo", "laptop")
+	require.NoError(t, err)
+	_, err = call(true, other, http.MethodDelete, "", h.RevokeAPIKey, created.ID)
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+
+	_, err = call(true, secret, http.MethodDelete, "", h.RevokeAPIKey, created.ID)
+	require.NoError(t, err)
+	_, err = call(true, created.Key, http.MethodGet, "", whoami)
+	assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+	_, err = call(true, secret, http.MethodDelete, "", h.RevokeAPIKey, created.ID)
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+
+	// The name of a revoked key can be reused
+	_, err = call(true, secret, http.MethodPost, `{"name":"ci"}`, h.CreateAPIKey)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// collectionRef checks that a collection referenced by a request exists.
+// An empty ID means no collection.
+func (h *Handler) collectionRef(ctx context.Context, id string) (sql.NullString, error) {
+	if id == "" {
+		return sql.NullString{}, nil
+	}
+
+	_, err := h.Store.GetCollection(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return sql.NullString{}, echo.NewHTTPError(http.StatusBadRequest, "Collection "+id+" does not exist")
+		}
+		return sql.NullString{}, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch collection: "+err.Error())
+	}
+	return sql.NullString{String: id, Valid: true}, nil
+}
+
+// GetCollections returns all collections. Clients build the tree from
+// each collection's parent_id.
+func (h *Handler) GetCollections(c echo.Context) error {
+	collections, err := h.Store.ListCollections(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch collections: "+err.Error())
+	}
+	if collections == nil {
+		collections = []sqlc.Collection{}
+	}
+
+	return c.JSON(http.StatusOK, models.ListResponse{Items: collections})
+}
+
+// CreateCollection creates a collection, optionally inside another one
+func (h *Handler) CreateCollection(c echo.Context) error {
+	var req models.CollectionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	// Validate required fields
+	if req.Name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Name is required")
+	}
+	parentID, err := h.collectionRef(c.Request().Context(), req.ParentID)
+	if err != nil {
+		return err
+	}
+	author, err := h.author(c, req.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	collection, err := h.Store.CreateCollection(c.Request().Context(), sqlc.CreateCollectionParams{
+		ID:          uuid.New().String(),
+		ParentID:    parentID,
+		Name:        req.Name,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+		CreatedBy:   sql.NullString{String: author.ID, Valid: author.ID != ""},
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrCollectionNameTaken) {
+			return echo.NewHTTPError(http.StatusConflict, "A collection with this name already exists here")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create collection: "+err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, collection)
+}
+
+// GetCollection returns a collection by ID
+func (h *Handler) GetCollection(c echo.Context) error {
+	collection, err := h.Store.GetCollection(c.Request().Context(), c.Param("collection"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "Collection not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch collection: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, collection)
+}
+
+// UpdateCollection renames a collection or moves it to another parent
+func (h *Handler) UpdateCollection(c echo.Context) error {
+	id := c.Param("collection")
+
+	var req models.CollectionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	// Validate required fields
+	if req.Name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Name is required")
+	}
+	parentID, err := h.collectionRef(c.Request().Context(), req.ParentID)
+	if err != nil {
+		return err
+	}
+
+	collection, err := h.Store.UpdateCollection(c.Request().Context(), sqlc.UpdateCollectionParams{
+		ID:          id,
+		ParentID:    parentID,
+		Name:        req.Name,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+	})
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			return echo.NewHTTPError(http.StatusNotFound, "Collection not found")
+		case errors.Is(err, db.ErrCollectionCycle):
+			return echo.NewHTTPError(http.StatusBadRequest, "A collection cannot be moved into itself or its children")
+		case errors.Is(err, db.ErrCollectionNameTaken):
+			return echo.NewHTTPError(http.StatusConflict, "A collection with this name already exists here")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update collection: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, collection)
+}
+
+// DeleteCollection deletes a collection. Its prompts and child collections
+// move up to its parent.
+func (h *Handler) DeleteCollection(c echo.Context) error {
+	id := c.Param("collection")
+
+	err := h.Store.DeleteCollection(c.Request().Context(), id)
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			return echo.NewHTTPError(http.StatusNotFound, "Collection not found")
+		case errors.Is(err, db.ErrCollectionNameTaken):
+			return echo.NewHTTPError(http.StatusConflict, "A child collection's name is already used in the parent collection")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete collection: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted", "id": id})
+}
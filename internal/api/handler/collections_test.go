@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCollection struct {
+	ID       string         `json:"id"`
+	Name     string         `json:"name"`
+	ParentID sql.NullString `json:"parent_id"`
+}
+
+func TestCollections(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	call := func(method string, fn echo.HandlerFunc, target, names, values, body string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if names != "" {
+			c.SetParamNames(names)
+			c.SetParamValues(values)
+		}
+		return rec, fn(c)
+	}
+	create := func(body string) (testCollection, error) {
+		var collection testCollection
+		rec, err := call(http.MethodPost, h.CreateCollection, "/collections", "", "", body)
+		if err != nil {
+			return collection, err
+		}
+		return collection, json.Unmarshal(rec.Body.Bytes(), &collection)
+	}
+	listIDs := func(query string) []string {
+		rec, err := call(http.MethodGet, h.GetPrompts, "/prompts?"+query, "", "", "")
+		require.NoError(t, err)
+		var page struct {
+			Items []testTaggedPrompt `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		ids := []string{}
+		for _, p := range page.Items {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	// Collections nest, with names unique among siblings
+	support, err := create(`{"name":"Support"}`)
+	require.NoError(t, err)
+	billing, err := create(`{"name":"Billing","parent_id":"` + support.ID + `"}`)
+	require.NoError(t, err)
+	assert.Equal(t, support.ID, billing.ParentID.String)
+	_, err = create(`{"name":"Billing","parent_id":"` + support.ID + `"}`)
+	assert.Equal(t, http.StatusConflict, err.(*echo.HTTPError).Code)
+	_, err = create(`{"name":"Billing","parent_id":"missing"}`)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	topBilling, err := create(`{"name":"Billing"}`)
+	require.NoError(t, err)
+
+	// A collection cannot move below itself
+	_, err = call(http.MethodPut, h.UpdateCollection, "/collections/"+support.ID, "collection", support.ID,
+		`{"name":"Support","parent_id":"`+billing.ID+`"}`)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+
+	// Prompts are placed in collections and filtered by them
+	rec, err := call(http.MethodPost, h.CreatePrompt, "/prompts", "", "",
+		`{"title":"Refunds","description":"d","collection_id":"`+billing.ID+`"}`)
+	require.NoError(t, err)
+	var refunds testTaggedPrompt
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &refunds))
+	assert.Equal(t, billing.ID, refunds.CollectionID.String)
+	_, err = call(http.MethodPost, h.CreatePrompt, "/prompts", "", "", `{"title":"Loose","description":"d"}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{refunds.ID}, listIDs("collection="+billing.ID))
+	assert.Empty(t, listIDs("collection="+support.ID))
+
+	// Moving a prompt keeps its collection unless one is given
+	_, err = call(http.MethodPut, h.UpdatePrompt, "/prompts/"+refunds.ID, "id", refunds.ID, `{"title":"Refunds","description":"d"}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{refunds.ID}, listIDs("collection="+billing.ID))
+	_, err = call(http.MethodPut, h.UpdatePrompt, "/prompts/"+refunds.ID, "id", refunds.ID,
+		`{"title":"Refunds","description":"d","collection_id":"`+support.ID+`"}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{refunds.ID}, listIDs("collection="+support.ID))
+
+	// Deleting a collection moves its contents up to the parent, unless a
+	// child's name is already taken there
+	_, err = call(http.MethodDelete, h.DeleteCollection, "/collections/"+support.ID, "collection", support.ID, "")
+	assert.Equal(t, http.StatusConflict, err.(*echo.HTTPError).Code)
+	_, err = call(http.MethodDelete, h.DeleteCollection, "/collections/"+topBilling.ID, "collection", topBilling.ID, "")
+	require.NoError(t, err)
+	_, err = call(http.MethodDelete, h.DeleteCollection, "/collections/"+support.ID, "collection", support.ID, "")
+	require.NoError(t, err)
+
+	rec, err = call(http.MethodGet, h.GetCollections, "/collections", "", "", "")
+	require.NoError(t, err)
+	var collections struct {
+		Items []testCollection `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &collections))
+	require.Len(t, collections.Items, 1)
+	assert.Equal(t, billing.ID, collections.Items[0].ID)
+	assert.False(t, collections.Items[0].ParentID.Valid)
+
+	rec, err = call(http.MethodGet, h.GetPrompt, "/prompts/"+refunds.ID, "id", refunds.ID, "")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &refunds))
+	assert.False(t, refunds.CollectionID.Valid)
+}
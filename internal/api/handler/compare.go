@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"math"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/eval"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// comparisonAlpha is the sign test p-value below which a comparison is
+// significant
+const comparisonAlpha = 0.05
+
+// Case outcomes, from the candidate's side
+const (
+	outcomeWin      = "win"
+	outcomeTie      = "tie"
+	outcomeLoss     = "loss"
+	outcomeUnscored = "unscored"
+)
+
+// CompareVersions compares two versions of a prompt on a dataset. Each
+// version reuses its latest eval run with the same dataset, model,
+// parameters and scorers, or starts a new one. While either run is
+// unfinished the comparison is returned as pending with 202; posting the
+// same request again picks up the same runs.
+func (h *Handler) CompareVersions(c echo.Context) error {
+	var req models.CompareRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+	if req.Baseline < 0 || req.Candidate < 0 || req.TieMargin < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Versions and tie margin must not be negative")
+	}
+	var err error
+	if req.CreatedBy, err = h.author(c, req.CreatedBy); err != nil {
+		return err
+	}
+
+	plan, err := h.planEvalRun(c.Request().Context(), req.EvalRunRequest)
+	if err != nil {
+		return err
+	}
+
+	// The candidate defaults to the latest version and the baseline to the
+	// version before it
+	candidate, err := h.findVersion(c.Request().Context(), c.Param("id"), req.Candidate)
+	if err != nil {
+		return err
+	}
+	if req.Baseline == 0 {
+		req.Baseline = candidate.Version - 1
+		if req.Baseline < 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, "No earlier version to compare against")
+		}
+	}
+	if req.Baseline == candidate.Version {
+		return echo.NewHTTPError(http.StatusBadRequest, "Cannot compare a version with itself")
+	}
+	baseline, err := h.findVersion(c.Request().Context(), c.Param("id"), req.Baseline)
+	if err != nil {
+		return err
+	}
+
+	baseRun, baseReused, err := h.comparisonRun(c.Request().Context(), baseline, plan, req.Rerun)
+	if err != nil {
+		return err
+	}
+	candRun, candReused, err := h.comparisonRun(c.Request().Context(), candidate, plan, req.Rerun)
+	if err != nil {
+		return err
+	}
+
+	comparison := models.Comparison{
+		Status:    db.EvalRunPending,
+		Baseline:  models.ComparedRun{Version: baseline.Version, EvalRunID: baseRun.ID, Status: baseRun.Status, Reused: baseReused},
+		Candidate: models.ComparedRun{Version: candidate.Version, EvalRunID: candRun.ID, Status: candRun.Status, Reused: candReused},
+	}
+	if baseRun.Status != db.EvalRunCompleted || candRun.Status != db.EvalRunCompleted {
+		return c.JSON(http.StatusAccepted, comparison)
+	}
+
+	comparison.Status = db.EvalRunCompleted
+	if err := h.compareEvalRuns(c.Request().Context(), &comparison, baseRun, candRun, plan.rows, req.TieMargin); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, comparison)
+}
+
+// comparisonRun returns the eval run of one side of a comparison, and
+// whether it was reused. Failed runs are never reused.
+func (h *Handler) comparisonRun(ctx context.Context, version sqlc.PromptVersion, plan *evalRunPlan, rerun bool) (sqlc.EvalRun, bool, error) {
+	if !rerun {
+		run, err := h.Store.GetReusableEvalRun(ctx, sqlc.GetReusableEvalRunParams{
+			PromptVersionID: version.ID,
+			DatasetID:       plan.req.DatasetID,
+			Model:           plan.req.Model,
+			Parameters:      plan.parameters,
+			Scorers:         plan.scorerSpecs,
+			TotalRows:       int64(len(plan.rows)),
+		})
+		if err == nil {
+			return run, true, nil
+		}
+		if err != sql.ErrNoRows {
+			return sqlc.EvalRun{}, false, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch eval runs: "+err.Error())
+		}
+	}
+
+	run, err := h.startEvalRun(ctx, version, plan)
+	return run, false, err
+}
+
+// compareEvalRuns pairs the results of two completed eval runs by dataset
+// row and fills in the comparison's cases and summary
+func (h *Handler) compareEvalRuns(ctx context.Context, comparison *models.Comparison, baseRun, candRun sqlc.EvalRun, rows []sqlc.DatasetRow, tieMargin float64) error {
+	baseResults, err := h.evalRunResultsByRow(ctx, baseRun.ID)
+	if err != nil {
+		return err
+	}
+	candResults, err := h.evalRunResultsByRow(ctx, candRun.ID)
+	if err != nil {
+		return err
+	}
+
+	summary := &models.ComparisonSummary{}
+	var baseSum, candSum float64
+	comparison.Cases = make([]models.CaseComparison, len(rows))
+	for i, row := range rows {
+		cc := models.CaseComparison{DatasetRowID: row.ID, Position: row.Position, Outcome: outcomeUnscored}
+		if err := json.Unmarshal([]byte(row.Variables), &cc.Variables); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to decode row variables: "+err.Error())
+		}
+		if row.Expected.Valid {
+			expected := row.Expected.String
+			cc.Expected = &expected
+		}
+
+		base, cand := baseResults[row.ID], candResults[row.ID]
+		cc.BaselineOutput, cc.BaselineError, cc.BaselineScore = resultFields(base)
+		cc.CandidateOutput, cc.CandidateError, cc.CandidateScore = resultFields(cand)
+
+		if cc.BaselineScore != nil && cc.CandidateScore != nil {
+			delta := *cc.CandidateScore - *cc.BaselineScore
+			cc.Delta = &delta
+			switch {
+			case math.Abs(delta) <= tieMargin:
+				cc.Outcome = outcomeTie
+				summary.Ties++
+			case delta > 0:
+				cc.Outcome = outcomeWin
+				summary.Wins++
+			default:
+				cc.Outcome = outcomeLoss
+				summary.Losses++
+			}
+			summary.Cases++
+			baseSum += *cc.BaselineScore
+			candSum += *cc.CandidateScore
+		} else {
+			summary.Unscored++
+		}
+		comparison.Cases[i] = cc
+	}
+
+	if summary.Cases > 0 {
+		baseMean := baseSum / float64(summary.Cases)
+		candMean := candSum / float64(summary.Cases)
+		delta := candMean - baseMean
+		summary.BaselineMean, summary.CandidateMean, summary.MeanDelta = &baseMean, &candMean, &delta
+	}
+
+	summary.PValue = eval.SignTest(summary.Wins, summary.Losses)
+	summary.Significant = summary.PValue < comparisonAlpha
+	switch {
+	case !summary.Significant:
+		summary.Verdict = "inconclusive"
+	case summary.Wins > summary.Losses:
+		summary.Verdict = "improved"
+	default:
+		summary.Verdict = "regressed"
+	}
+
+	if summary.Scorers, err = h.compareScorers(ctx, baseRun.ID, candRun.ID); err != nil {
+		return err
+	}
+	comparison.Summary = summary
+	return nil
+}
+
+// evalRunResultsByRow loads the results of an eval run keyed by dataset row
+func (h *Handler) evalRunResultsByRow(ctx context.Context, runID string) (map[string]sqlc.EvalRunResult, error) {
+	results, err := h.Store.ListEvalRunResults(ctx, runID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch eval run results: "+err.Error())
+	}
+
+	byRow := make(map[string]sqlc.EvalRunResult, len(results))
+	for _, result := range results {
+		byRow[result.DatasetRowID] = result
+	}
+	return byRow, nil
+}
+
+// resultFields returns the output, error and score of an eval run result,
+// each nil when unset
+func resultFields(result sqlc.EvalRunResult) (output, errText *string, score *float64) {
+	if result.Output.Valid {
+		output = &result.Output.String
+	}
+	if result.Error.Valid {
+		errText = &result.Error.String
+	}
+	return output, errText, floatPtr(result.Score)
+}
+
+// compareScorers compares the mean score of every scorer used in either
+// run
+func (h *Handler) compareScorers(ctx context.Context, baseRunID, candRunID string) ([]models.ScorerDelta, error) {
+	base, err := h.Store.ListEvalRunScorerSummaries(ctx, baseRunID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to summarize eval run: "+err.Error())
+	}
+	cand, err := h.Store.ListEvalRunScorerSummaries(ctx, candRunID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to summarize eval run: "+err.Error())
+	}
+
+	// Both runs use the same scorer specs, so their summaries usually line
+	// up; a scorer that never applied in one run has no mean there
+	deltas := []models.ScorerDelta{}
+	index := make(map[string]int)
+	for _, s := range base {
+		index[s.Scorer] = len(deltas)
+		deltas = append(deltas, models.ScorerDelta{Scorer: s.Scorer, BaselineMean: floatPtr(s.MeanScore)})
+	}
+	for _, s := range cand {
+		i, ok := index[s.Scorer]
+		if !ok {
+			i = len(deltas)
+			deltas = append(deltas, models.ScorerDelta{Scorer: s.Scorer})
+		}
+		deltas[i].CandidateMean = floatPtr(s.MeanScore)
+	}
+
+	for i, d := range deltas {
+		if d.BaselineMean != nil && d.CandidateMean != nil {
+			delta := *d.CandidateMean - *d.BaselineMean
+			deltas[i].Delta = &delta
+		}
+	}
+	return deltas, nil
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/llm"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+	h.Providers = llm.NewRegistry("")
+	h.Providers.Register(llm.NewFakeProvider())
+
+	_, err := store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{ID: "capitals", Title: "Capitals"})
+	require.NoError(t, err)
+	for i, content := range []string{
+		`[{"role":"user","content":"{{city}}"}]`,
+		`[{"role":"user","content":"{{city}}"},{"role":"user","content":"I do not know"}]`,
+	} {
+		_, err = store.CreateVersion(context.Background(), sqlc.CreateVersionParams{
+			ID:       "capitals-v" + string(rune('1'+i)),
+			PromptID: sql.NullString{String: "capitals", Valid: true},
+			Version:  int64(i + 1),
+			Content:  content,
+		})
+		require.NoError(t, err)
+	}
+
+	// Version 1 names the city and version 2 never does; the last row
+	// fails with both
+	rows := []sqlc.CreateDatasetRowParams{}
+	for _, city := range []string{"Paris", "Rome", "Lima", "Oslo", "Cairo", "Quito"} {
+		rows = append(rows, sqlc.CreateDatasetRowParams{ID: city, Variables: `{"city":"` + city + `"}`, Expected: sql.NullString{String: city, Valid: true}})
+	}
+	rows = append(rows, sqlc.CreateDatasetRowParams{ID: "wrong", Variables: `{"city":"Bern"}`, Expected: sql.NullString{String: "Zurich", Valid: true}})
+	dataset, _, err := store.CreateDataset(context.Background(), sqlc.CreateDatasetParams{ID: "cities", Name: "cities"}, rows)
+	require.NoError(t, err)
+
+	compare := func(body string) (int, models.Comparison, error) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("capitals")
+		var comparison models.Comparison
+		if err := h.CompareVersions(c); err != nil {
+			return 0, comparison, err
+		}
+		return rec.Code, comparison, json.Unmarshal(rec.Body.Bytes(), &comparison)
+	}
+	body := `{"dataset_id":"` + dataset.ID + `","model":"fake/echo","scorers":[{"type":"contains"}]}`
+
+	// The first request starts an eval run for each version
+	code, pending, err := compare(body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, code)
+	assert.Equal(t, "pending", pending.Status)
+	assert.Equal(t, int64(1), pending.Baseline.Version)
+	assert.Equal(t, int64(2), pending.Candidate.Version)
+	assert.False(t, pending.Baseline.Reused)
+	assert.Nil(t, pending.Summary)
+	require.NoError(t, h.WaitForEvalRuns(context.Background()))
+
+	// Asking again reuses the finished runs
+	code, comparison, err := compare(body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "completed", comparison.Status)
+	assert.Equal(t, pending.Baseline.EvalRunID, comparison.Baseline.EvalRunID)
+	assert.Equal(t, pending.Candidate.EvalRunID, comparison.Candidate.EvalRunID)
+	assert.True(t, comparison.Candidate.Reused)
+
+	summary := comparison.Summary
+	require.NotNil(t, summary)
+	assert.Equal(t, 7, summary.Cases)
+	assert.Equal(t, 0, summary.Wins)
+	assert.Equal(t, 1, summary.Ties)
+	assert.Equal(t, 6, summary.Losses)
+	assert.InDelta(t, 6.0/7, *summary.BaselineMean, 1e-9)
+	assert.InDelta(t, -6.0/7, *summary.MeanDelta, 1e-9)
+	assert.InDelta(t, 2.0/64, summary.PValue, 1e-9)
+	assert.True(t, summary.Significant)
+	assert.Equal(t, "regressed", summary.Verdict)
+	require.Len(t, summary.Scorers, 1)
+	assert.InDelta(t, -6.0/7, *summary.Scorers[0].Delta, 1e-9)
+
+	// Every case shows both outputs and how the candidate fared
+	require.Len(t, comparison.Cases, 7)
+	assert.Equal(t, "Paris", comparison.Cases[0].DatasetRowID)
+	assert.Equal(t, "echo: Paris", *comparison.Cases[0].BaselineOutput)
+	assert.Equal(t, "echo: I do not know", *comparison.Cases[0].CandidateOutput)
+	assert.Equal(t, -1.0, *comparison.Cases[0].Delta)
+	assert.Equal(t, "loss", comparison.Cases[0].Outcome)
+	assert.Equal(t, "tie", comparison.Cases[6].Outcome)
+	assert.Equal(t, "Zurich", *comparison.Cases[6].Expected)
+
+	// Reruns and changed run settings start new eval runs
+	_, rerun, err := compare(`{"dataset_id":"` + dataset.ID + `","model":"fake/echo","scorers":[{"type":"contains"}],"rerun":true}`)
+	require.NoError(t, err)
+	assert.NotEqual(t, comparison.Baseline.EvalRunID, rerun.Baseline.EvalRunID)
+	_, other, err := compare(`{"dataset_id":"` + dataset.ID + `","model":"fake/echo","baseline":2,"candidate":1}`)
+	require.NoError(t, err)
+	assert.False(t, other.Baseline.Reused)
+	assert.Equal(t, int64(2), other.Baseline.Version)
+	require.NoError(t, h.WaitForEvalRuns(context.Background()))
+
+	_, _, err = compare(`{"dataset_id":"` + dataset.ID + `","model":"fake/echo","baseline":2,"candidate":2}`)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	_, _, err = compare(`{"dataset_id":"` + dataset.ID + `","model":"fake/echo","baseline":9}`)
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+	_, _, err = compare(`{"dataset_id":"missing","model":"fake/echo"}`)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+}
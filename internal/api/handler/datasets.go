@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// datasetPayload is a dataset together with its rows
+type datasetPayload struct {
+	sqlc.Dataset
+	Rows []datasetRowPayload `json:"rows"`
+}
+
+// datasetRowPayload is a stored dataset row with its variables decoded
+type datasetRowPayload struct {
+	sqlc.DatasetRow
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// newDatasetRowPayloads decodes the variables of stored dataset rows
+func newDatasetRowPayloads(rows []sqlc.DatasetRow) []datasetRowPayload {
+	payloads := make([]datasetRowPayload, len(rows))
+	for i, row := range rows {
+		payloads[i] = datasetRowPayload{DatasetRow: row, Variables: map[string]interface{}{}}
+		_ = json.Unmarshal([]byte(row.Variables), &payloads[i].Variables)
+	}
+	return payloads
+}
+
+// toDatasetRowParams encodes request rows for storage
+func toDatasetRowParams(rows []models.DatasetRow) []sqlc.CreateDatasetRowParams {
+	params := make([]sqlc.CreateDatasetRowParams, len(rows))
+	for i, row := range rows {
+		variables := row.Variables
+		if variables == nil {
+			variables = map[string]interface{}{}
+		}
+		variablesJSON, _ := json.Marshal(variables)
+
+		params[i] = sqlc.CreateDatasetRowParams{
+			ID:        uuid.New().String(),
+			Variables: string(variablesJSON),
+			Expected:  sql.NullString{String: row.Expected, Valid: row.Expected != ""},
+		}
+	}
+	return params
+}
+
+// GetDatasets returns all datasets without their rows
+func (h *Handler) GetDatasets(c echo.Context) error {
+	datasets, err := h.Store.ListDatasets(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch datasets: "+err.Error())
+	}
+	if datasets == nil {
+		datasets = []sqlc.Dataset{}
+	}
+
+	return c.JSON(http.StatusOK, models.ListResponse{Items: datasets})
+}
+
+// CreateDataset creates a dataset with its initial rows
+func (h *Handler) CreateDataset(c echo.Context) error {
+	var req models.DatasetRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	// Validate required fields
+	if req.Name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Name is required")
+	}
+	author, err := h.author(c, req.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	dataset, rows, err := h.Store.CreateDataset(c.Request().Context(), sqlc.CreateDatasetParams{
+		ID:          uuid.New().String(),
+		Name:        req.Name,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+		CreatedBy:   sql.NullString{String: author.ID, Valid: author.ID != ""},
+	}, toDatasetRowParams(req.Rows))
+	if err != nil {
+		if errors.Is(err, db.ErrDatasetNameTaken) {
+			return echo.NewHTTPError(http.StatusConflict, "A dataset with this name already exists")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create dataset: "+err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, datasetPayload{Dataset: dataset, Rows: newDatasetRowPayloads(rows)})
+}
+
+// GetDataset returns a dataset with all of its rows
+func (h *Handler) GetDataset(c echo.Context) error {
+	id := c.Param("dataset")
+
+	dataset, err := h.Store.GetDataset(c.Request().Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "Dataset not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch dataset: "+err.Error())
+	}
+
+	rows, err := h.Store.ListDatasetRows(c.Request().Context(), id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch dataset rows: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, datasetPayload{Dataset: dataset, Rows: newDatasetRowPayloads(rows)})
+}
+
+// UpdateDataset renames a dataset or changes its description
+func (h *Handler) UpdateDataset(c echo.Context) error {
+	var req models.DatasetRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	// Validate required fields
+	if req.Name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Name is required")
+	}
+
+	dataset, err := h.Store.UpdateDataset(c.Request().Context(), sqlc.UpdateDatasetParams{
+		ID:          c.Param("dataset"),
+		Name:        req.Name,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+	})
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			return echo.NewHTTPError(http.StatusNotFound, "Dataset not found")
+		case errors.Is(err, db.ErrDatasetNameTaken):
+			return echo.NewHTTPError(http.StatusConflict, "A dataset with this name already exists")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update dataset: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, dataset)
+}
+
+// DeleteDataset deletes a dataset that no eval run refers to
+func (h *Handler) DeleteDataset(c echo.Context) error {
+	id := c.Param("dataset")
+
+	err := h.Store.DeleteDataset(c.Request().Context(), id)
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			return echo.NewHTTPError(http.StatusNotFound, "Dataset not found")
+		case errors.Is(err, db.ErrDatasetInUse):
+			return echo.NewHTTPError(http.StatusConflict, "Dataset is used by eval runs")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete dataset: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted", "id": id})
+}
+
+// AddDatasetRows appends rows to a dataset
+func (h *Handler) AddDatasetRows(c echo.Context) error {
+	var req models.DatasetRowsRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	// Validate required fields
+	if len(req.Rows) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Rows are required")
+	}
+
+	rows, err := h.Store.AddDatasetRows(c.Request().Context(), c.Param("dataset"), toDatasetRowParams(req.Rows))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "Dataset not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to add dataset rows: "+err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, models.ListResponse{Items: newDatasetRowPayloads(rows)})
+}
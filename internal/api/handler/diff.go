@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/internal/diff"
+)
+
+// versionDiff is the difference between two versions of a prompt
+type versionDiff struct {
+	PromptID string `json:"prompt_id"`
+	From     int64  `json:"from"`
+	To       int64  `json:"to"`
+	diff.Result
+}
+
+// parseVersionParam reads an optional positive version number from the
+// query string. A missing parameter yields 0.
+func parseVersionParam(c echo.Context, name string) (int64, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return 0, nil
+	}
+	number, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || number < 1 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "Invalid "+name+" version")
+	}
+	return number, nil
+}
+
+// GetVersionDiff compares the messages of two versions of a prompt. "to"
+// defaults to the latest version and "from" to the version before it.
+func (h *Handler) GetVersionDiff(c echo.Context) error {
+	promptID := c.Param("id")
+
+	fromNumber, err := parseVersionParam(c, "from")
+	if err != nil {
+		return err
+	}
+	toNumber, err := parseVersionParam(c, "to")
+	if err != nil {
+		return err
+	}
+
+	to, err := h.findVersion(c.Request().Context(), promptID, toNumber)
+	if err != nil {
+		return err
+	}
+	if fromNumber == 0 {
+		fromNumber = to.Version - 1
+		if fromNumber < 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, "No earlier version to compare against")
+		}
+	}
+	from, err := h.findVersion(c.Request().Context(), promptID, fromNumber)
+	if err != nil {
+		return err
+	}
+
+	fromMsgs, err := fromDBMessages(from.Content)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to decode messages: "+err.Error())
+	}
+	toMsgs, err := fromDBMessages(to.Content)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to decode messages: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, versionDiff{
+		PromptID: promptID,
+		From:     from.Version,
+		To:       to.Version,
+		Result:   diff.Messages(fromMsgs, toMsgs),
+	})
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVersionDiff(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	// Create a test prompt with three versions
+	_, err := store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{ID: "test-prompt", Title: "Test Prompt"})
+	require.NoError(t, err)
+	for i, content := range []string{
+		`[{"role":"system","content":"Be brief."},{"role":"user","content":"Hi"}]`,
+		`[{"role":"system","content":"Be thorough."},{"role":"user","content":"Hi"}]`,
+		`[{"role":"system","content":"Be thorough."},{"role":"user","content":"Hi"},{"role":"user","content":"Bye"}]`,
+	} {
+		_, err = store.CreateVersion(context.Background(), sqlc.CreateVersionParams{
+			ID:       fmt.Sprintf("test-version-%d", i+1),
+			PromptID: sql.NullString{String: "test-prompt", Valid: true},
+			Version:  int64(i + 1),
+			Content:  content,
+		})
+		require.NoError(t, err)
+	}
+
+	call := func(query string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, "/prompts/test-prompt/diff?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/prompts/:id/diff")
+		c.SetParamNames("id")
+		c.SetParamValues("test-prompt")
+		return rec, h.GetVersionDiff(c)
+	}
+
+	// Test GET /prompts/:id/diff?from=1&to=3
+	rec, err := call("from=1&to=3")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var result versionDiff
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, int64(1), result.From)
+	assert.Equal(t, int64(3), result.To)
+	assert.Equal(t, 1, result.Summary.Changed)
+	assert.Equal(t, 1, result.Summary.Added)
+	assert.Equal(t, 1, result.Summary.Unchanged)
+	assert.Equal(t, "@@ -1,1 +1,1 @@\n-Be brief.\n+Be thorough.\n", result.Messages[0].Unified)
+
+	// Without parameters the latest version is compared with the one before
+	rec, err = call("")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, int64(2), result.From)
+	assert.Equal(t, int64(3), result.To)
+	assert.Equal(t, 1, result.Summary.Added)
+	assert.Equal(t, 0, result.Summary.Changed)
+
+	// Test invalid and missing versions
+	_, err = call("from=abc")
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	_, err = call("from=1&to=9")
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+)
+
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+// promptETag identifies the current revision of a prompt
+func promptETag(prompt sqlc.Prompt) string {
+	return fmt.Sprintf(`"%s.%d"`, prompt.ID, prompt.Revision)
+}
+
+// versionETag identifies a version. Versions never change after creation.
+func versionETag(version sqlc.PromptVersion) string {
+	return `"` + version.ID + `"`
+}
+
+// etagMatches reports whether an If-Match or If-None-Match header matches
+// etag. Weak comparison ignores the W/ prefix; strong comparison never
+// matches weak tags.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified sets the ETag header and reports whether the request's
+// If-None-Match already has it, in which case the caller should reply 304
+func notModified(c echo.Context, etag string) bool {
+	c.Response().Header().Set(headerETag, etag)
+	header := c.Request().Header.Get(headerIfNoneMatch)
+	return header != "" && etagMatches(header, etag, true)
+}
+
+// checkIfMatch evaluates the request's If-Match header against the
+// prompt. It returns the revision a conditional write must apply to, which
+// is invalid for unconditional requests, or a 412 error on mismatch.
+func checkIfMatch(c echo.Context, prompt sqlc.Prompt) (sql.NullInt64, error) {
+	header := c.Request().Header.Get(headerIfMatch)
+	if header == "" {
+		return sql.NullInt64{}, nil
+	}
+	if !etagMatches(header, promptETag(prompt), false) {
+		return sql.NullInt64{}, echo.NewHTTPError(http.StatusPreconditionFailed, "Prompt has been modified")
+	}
+	return sql.NullInt64{Int64: prompt.Revision, Valid: true}, nil
+}
+
+// missedWrite is the error for a prompt write that matched no row after
+// the prompt was read: a 412 if the write was conditional, otherwise a 404
+// because the prompt has been deleted in the meantime
+func missedWrite(ifRevision sql.NullInt64) error {
+	if ifRevision.Valid {
+		return echo.NewHTTPError(http.StatusPreconditionFailed, "Prompt has been modified")
+	}
+	return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
+}
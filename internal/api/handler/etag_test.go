@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETags(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	_, err := store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{ID: "test-prompt", Title: "Test Prompt"})
+	require.NoError(t, err)
+	_, err = store.CreateVersion(context.Background(), sqlc.CreateVersionParams{
+		ID:       "test-version",
+		PromptID: sql.NullString{String: "test-prompt", Valid: true},
+		Version:  1,
+		Content:  `[{"role":"user","content":"hi"}]`,
+	})
+	require.NoError(t, err)
+
+	call := func(method string, fn echo.HandlerFunc, header, value, body string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(method, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/prompts/:id/versions/:version")
+		c.SetParamNames("id", "version")
+		c.SetParamValues("test-prompt", "1")
+		return rec, fn(c)
+	}
+
+	// Test GET /prompts/:id returns an ETag and honors If-None-Match
+	rec, err := call(http.MethodGet, h.GetPrompt, "", "", "")
+	require.NoError(t, err)
+	etag := rec.Header().Get("ETag")
+	assert.Equal(t, `"test-prompt.1"`, etag)
+
+	rec, err = call(http.MethodGet, h.GetPrompt, "If-None-Match", "W/"+etag, "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	// Test PUT /prompts/:id with a current and then a stale If-Match
+	rec, err = call(http.MethodPut, h.UpdatePrompt, "If-Match", etag, `{"title":"Renamed","description":"d"}`)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	newETag := rec.Header().Get("ETag")
+	assert.Equal(t, `"test-prompt.2"`, newETag)
+
+	_, err = call(http.MethodPut, h.UpdatePrompt, "If-Match", etag, `{"title":"Clobbered","description":"d"}`)
+	assert.Equal(t, http.StatusPreconditionFailed, err.(*echo.HTTPError).Code)
+
+	var prompt testPrompt
+	rec, err = call(http.MethodGet, h.GetPrompt, "If-None-Match", etag, "")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &prompt))
+	assert.Equal(t, "Renamed", prompt.Title)
+
+	// Creating a version requires the current revision and changes it
+	_, err = call(http.MethodPost, h.CreateVersion, "If-Match", etag, `{"messages":[{"role":"user","content":"v2"}]}`)
+	assert.Equal(t, http.StatusPreconditionFailed, err.(*echo.HTTPError).Code)
+	rec, err = call(http.MethodPost, h.CreateVersion, "If-Match", newETag, `{"messages":[{"role":"user","content":"v2"}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	// Test GET /prompts/:id/versions/:version
+	rec, err = call(http.MethodGet, h.GetVersion, "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, `"test-version"`, rec.Header().Get("ETag"))
+	rec, err = call(http.MethodGet, h.GetVersion, "If-None-Match", `"other", "test-version"`, "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	// Test PUT /prompts/:id/slug with a stale and then a current If-Match
+	_, err = call(http.MethodPut, h.UpdatePromptSlug, "If-Match", newETag, `{"slug":"renamed"}`)
+	assert.Equal(t, http.StatusPreconditionFailed, err.(*echo.HTTPError).Code)
+	rec, err = call(http.MethodPut, h.UpdatePromptSlug, "If-Match", `"test-prompt.3"`, `{"slug":"renamed"}`)
+	require.NoError(t, err)
+	assert.Equal(t, `"test-prompt.4"`, rec.Header().Get("ETag"))
+
+	// Test DELETE /prompts/:id with a stale If-Match
+	_, err = call(http.MethodDelete, h.DeletePrompt, "If-Match", newETag, "")
+	assert.Equal(t, http.StatusPreconditionFailed, err.(*echo.HTTPError).Code)
+	_, err = call(http.MethodDelete, h.DeletePrompt, "If-Match", `"test-prompt.4"`, "")
+	require.NoError(t, err)
+}
+
+func TestMissedWrite(t *testing.T) {
+	// A conditional write that matched nothing lost the race to a writer
+	err := missedWrite(sql.NullInt64{Int64: 3, Valid: true})
+	assert.Equal(t, http.StatusPreconditionFailed, err.(*echo.HTTPError).Code)
+
+	// An unconditional one can only have lost the prompt to a delete
+	err = missedWrite(sql.NullInt64{})
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+}
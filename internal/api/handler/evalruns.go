@@ -0,0 +1,410 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/eval"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// defaultScorers are used by eval runs started without scorers
+var defaultScorers = []json.RawMessage{json.RawMessage(`{"type":"exact_match"}`)}
+
+// evalRunPayload is an eval run with its aggregate results and, when
+// requested, the result of every dataset row
+type evalRunPayload struct {
+	sqlc.EvalRun
+	Scorers json.RawMessage        `json:"scorers"`
+	Summary models.EvalRunSummary  `json:"summary"`
+	Results []evalRunResultPayload `json:"results,omitempty"`
+}
+
+// evalRunResultPayload is the result of one dataset row with its scorer
+// evaluations
+type evalRunResultPayload struct {
+	sqlc.EvalRunResult
+	Evaluations []sqlc.Evaluation `json:"evaluations"`
+}
+
+// newEvalRunPayload loads the summary of an eval run
+func (h *Handler) newEvalRunPayload(ctx context.Context, run sqlc.EvalRun) (evalRunPayload, error) {
+	summary, err := h.Store.GetEvalRunSummary(ctx, run.ID)
+	if err != nil {
+		return evalRunPayload{}, echo.NewHTTPError(http.StatusInternalServerError, "Failed to summarize eval run: "+err.Error())
+	}
+
+	scorers, err := h.Store.ListEvalRunScorerSummaries(ctx, run.ID)
+	if err != nil {
+		return evalRunPayload{}, echo.NewHTTPError(http.StatusInternalServerError, "Failed to summarize eval run: "+err.Error())
+	}
+
+	payload := evalRunPayload{
+		EvalRun: run,
+		Scorers: json.RawMessage(run.Scorers),
+		Summary: models.EvalRunSummary{
+			Rows:          run.TotalRows,
+			Completed:     summary.Results,
+			Errors:        summary.Errors,
+			Scored:        summary.Scored,
+			TotalTokens:   summary.TotalTokens,
+			MeanScore:     floatPtr(summary.MeanScore),
+			MeanLatencyMs: floatPtr(summary.MeanLatencyMs),
+		},
+	}
+
+	payload.Summary.Scorers = make([]models.ScorerSummary, len(scorers))
+	for i, scorer := range scorers {
+		payload.Summary.Scorers[i] = models.ScorerSummary{
+			Scorer:      scorer.Scorer,
+			Evaluations: scorer.Evaluations,
+			Passed:      scorer.Passed,
+			Failed:      scorer.Checked - scorer.Passed,
+			MeanScore:   floatPtr(scorer.MeanScore),
+		}
+	}
+	return payload, nil
+}
+
+// versionParam loads the prompt version named by the :id and :version
+// path parameters
+func (h *Handler) versionParam(c echo.Context) (sqlc.PromptVersion, error) {
+	versionNum, err := strconv.ParseInt(c.Param("version"), 10, 64)
+	if err != nil || versionNum <= 0 {
+		return sqlc.PromptVersion{}, echo.NewHTTPError(http.StatusBadRequest, "Invalid version number")
+	}
+	return h.findVersion(c.Request().Context(), c.Param("id"), versionNum)
+}
+
+// CreateEvalRun starts running a prompt version against every row of a
+// dataset in the background and returns the pending eval run
+func (h *Handler) CreateEvalRun(c echo.Context) error {
+	var req models.EvalRunRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+	var err error
+	if req.CreatedBy, err = h.author(c, req.CreatedBy); err != nil {
+		return err
+	}
+
+	plan, err := h.planEvalRun(c.Request().Context(), req)
+	if err != nil {
+		return err
+	}
+	version, err := h.versionParam(c)
+	if err != nil {
+		return err
+	}
+
+	run, err := h.startEvalRun(c.Request().Context(), version, plan)
+	if err != nil {
+		return err
+	}
+
+	payload, err := h.newEvalRunPayload(c.Request().Context(), run)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusAccepted, payload)
+}
+
+// evalRunPlan is a validated eval run request with the scorers and dataset
+// rows it runs
+type evalRunPlan struct {
+	req     models.EvalRunRequest
+	scorers []eval.Scorer
+	rows    []sqlc.DatasetRow
+
+	// parameters and scorerSpecs are stored on the run as JSON
+	parameters  string
+	scorerSpecs string
+}
+
+// planEvalRun validates an eval run request and loads its dataset rows
+func (h *Handler) planEvalRun(ctx context.Context, req models.EvalRunRequest) (*evalRunPlan, error) {
+	// Validate required fields
+	if req.DatasetID == "" || req.Model == "" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Dataset and model are required")
+	}
+	if _, _, err := h.Providers.Resolve(req.Model); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if len(req.Scorers) == 0 {
+		req.Scorers = defaultScorers
+	}
+	scorers, err := h.Scorers.NewAll(ctx, req.Scorers)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	rows, err := h.Store.ListDatasetRows(ctx, req.DatasetID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch dataset rows: "+err.Error())
+	}
+	if len(rows) == 0 {
+		if _, err := h.Store.GetDataset(ctx, req.DatasetID); err == sql.ErrNoRows {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Dataset "+req.DatasetID+" does not exist")
+		}
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Dataset has no rows")
+	}
+
+	params, _ := json.Marshal(runParameters{
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		TopP:        req.TopP,
+	})
+	scorerSpecs, _ := json.Marshal(req.Scorers)
+	return &evalRunPlan{
+		req:         req,
+		scorers:     scorers,
+		rows:        rows,
+		parameters:  string(params),
+		scorerSpecs: string(scorerSpecs),
+	}, nil
+}
+
+// startEvalRun records a pending eval run of the version and executes it
+// in the background
+func (h *Handler) startEvalRun(ctx context.Context, version sqlc.PromptVersion, plan *evalRunPlan) (sqlc.EvalRun, error) {
+	run, err := h.Store.CreateEvalRun(ctx, sqlc.CreateEvalRunParams{
+		ID:              uuid.New().String(),
+		PromptID:        version.PromptID.String,
+		PromptVersionID: version.ID,
+		DatasetID:       plan.req.DatasetID,
+		Model:           plan.req.Model,
+		Parameters:      plan.parameters,
+		Scorers:         plan.scorerSpecs,
+		TotalRows:       int64(len(plan.rows)),
+		CreatedBy:       sql.NullString{String: plan.req.CreatedBy.ID, Valid: plan.req.CreatedBy.ID != ""},
+	})
+	if err != nil {
+		return sqlc.EvalRun{}, echo.NewHTTPError(http.StatusInternalServerError, "Failed to create eval run: "+err.Error())
+	}
+
+	// The run outlives the request that started it
+	ctx = context.WithoutCancel(ctx)
+	h.evalRuns.Add(1)
+	go func() {
+		defer h.evalRuns.Done()
+		h.executeEvalRun(ctx, run, version, plan.req, plan.scorers, plan.rows)
+	}()
+	return run, nil
+}
+
+// GetEvalRuns returns the eval runs of a prompt version, newest first
+func (h *Handler) GetEvalRuns(c echo.Context) error {
+	version, err := h.versionParam(c)
+	if err != nil {
+		return err
+	}
+
+	runs, err := h.Store.ListEvalRunsByVersion(c.Request().Context(), version.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch eval runs: "+err.Error())
+	}
+
+	payloads := make([]evalRunPayload, len(runs))
+	for i, run := range runs {
+		if payloads[i], err = h.newEvalRunPayload(c.Request().Context(), run); err != nil {
+			return err
+		}
+	}
+
+	return c.JSON(http.StatusOK, models.ListResponse{Items: payloads})
+}
+
+// GetEvalRun returns an eval run with its aggregate results and the
+// result of every dataset row
+func (h *Handler) GetEvalRun(c echo.Context) error {
+	version, err := h.versionParam(c)
+	if err != nil {
+		return err
+	}
+
+	run, err := h.Store.GetEvalRun(c.Request().Context(), c.Param("run"))
+	if err == sql.ErrNoRows || (err == nil && run.PromptVersionID != version.ID) {
+		return echo.NewHTTPError(http.StatusNotFound, "Eval run not found")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch eval run: "+err.Error())
+	}
+
+	payload, err := h.newEvalRunPayload(c.Request().Context(), run)
+	if err != nil {
+		return err
+	}
+	results, err := h.Store.ListEvalRunResults(c.Request().Context(), run.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch eval run results: "+err.Error())
+	}
+	evals, err := h.Store.ListEvaluationsByEvalRun(c.Request().Context(), run.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch eval run evaluations: "+err.Error())
+	}
+
+	byResult := make(map[int64][]sqlc.Evaluation)
+	for _, e := range evals {
+		byResult[e.EvalRunResultID.Int64] = append(byResult[e.EvalRunResultID.Int64], e)
+	}
+	payload.Results = make([]evalRunResultPayload, len(results))
+	for i, result := range results {
+		payload.Results[i] = evalRunResultPayload{EvalRunResult: result, Evaluations: byResult[result.ID]}
+		if payload.Results[i].Evaluations == nil {
+			payload.Results[i].Evaluations = []sqlc.Evaluation{}
+		}
+	}
+
+	return c.JSON(http.StatusOK, payload)
+}
+
+// WaitForEvalRuns blocks until every eval run started by this handler has
+// finished, or returns ctx's error if ctx is done first
+func (h *Handler) WaitForEvalRuns(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.evalRuns.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// executeEvalRun runs the version against each dataset row in order and
+// records the outcome of every row. Rows that fail to render or run are
+// recorded with their error; the eval run only fails if results cannot be
+// stored.
+func (h *Handler) executeEvalRun(ctx context.Context, run sqlc.EvalRun, version sqlc.PromptVersion, req models.EvalRunRequest, scorers []eval.Scorer, rows []sqlc.DatasetRow) {
+	if err := h.Store.StartEvalRun(ctx, run.ID); err != nil {
+		log.Printf("Failed to start eval run %s: %v", run.ID, err)
+	}
+
+	status, runErr := db.EvalRunCompleted, sql.NullString{}
+	for _, row := range rows {
+		if err := h.evalRow(ctx, run, version, req, scorers, row); err != nil {
+			status, runErr = db.EvalRunFailed, sql.NullString{String: err.Error(), Valid: true}
+			break
+		}
+	}
+
+	err := h.Store.FinishEvalRun(ctx, sqlc.FinishEvalRunParams{ID: run.ID, Status: status, Error: runErr})
+	if err != nil {
+		log.Printf("Failed to finish eval run %s: %v", run.ID, err)
+	}
+}
+
+// evalRow runs the version with one dataset row's variables, scores the
+// output and stores the result
+func (h *Handler) evalRow(ctx context.Context, run sqlc.EvalRun, version sqlc.PromptVersion, req models.EvalRunRequest, scorers []eval.Scorer, row sqlc.DatasetRow) error {
+	result := sqlc.CreateEvalRunResultParams{EvalRunID: run.ID, DatasetRowID: row.ID}
+	var evals []sqlc.CreateEvaluationParams
+
+	c, runID, err := h.runRow(ctx, version, req, row)
+	if err != nil {
+		result.Error = sql.NullString{String: errorMessage(err), Valid: true}
+	} else {
+		evals = scoreCase(ctx, scorers, c, version.ID, req.CreatedBy)
+		result.Output = sql.NullString{String: c.Output, Valid: true}
+		result.Score = meanScore(evals)
+	}
+	result.RunID = sql.NullString{String: runID, Valid: runID != ""}
+
+	_, err = h.Store.RecordEvalRunResult(ctx, result, evals)
+	return err
+}
+
+// runRow renders and runs the version for one dataset row through the
+// regular run pipeline, returning the test case to score and the recorded
+// run's ID
+func (h *Handler) runRow(ctx context.Context, version sqlc.PromptVersion, req models.EvalRunRequest, row sqlc.DatasetRow) (eval.Case, string, error) {
+	c := eval.Case{}
+	if err := json.Unmarshal([]byte(row.Variables), &c.Variables); err != nil {
+		return c, "", fmt.Errorf("invalid row variables: %w", err)
+	}
+	if c.Variables == nil {
+		c.Variables = map[string]interface{}{}
+	}
+	if row.Expected.Valid {
+		c.Expected = &row.Expected.String
+	}
+
+	runReq := models.RunPromptRequest{
+		PromptID:    version.PromptID.String,
+		Version:     int(version.Version),
+		Variables:   c.Variables,
+		Model:       req.Model,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		TopP:        req.TopP,
+		CreatedBy:   req.CreatedBy,
+	}
+	prepared, err := h.prepareRun(ctx, runReq)
+	if err != nil {
+		return c, "", err
+	}
+	c.Input = formatInput(prepared.request.Messages)
+
+	start := time.Now()
+	response, runErr := h.complete(ctx, prepared)
+	runID, err := h.storeRun(ctx, runReq, prepared, response, runErr, time.Since(start))
+	if err != nil {
+		log.Printf("Failed to record run: %v", err)
+	}
+	if runErr != nil {
+		return c, runID, runErr
+	}
+	c.Output = response.Content
+	return c, runID, nil
+}
+
+// formatInput renders messages as the text of a test case's input. A
+// single message is used as is; several are labelled with their roles.
+func formatInput(msgs []models.Message) string {
+	if len(msgs) == 1 {
+		return msgs[0].Content
+	}
+
+	parts := make([]string, len(msgs))
+	for i, msg := range msgs {
+		parts[i] = string(msg.Role) + ": " + msg.Content
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// errorMessage returns the client-facing message of an HTTP error, or the
+// error text of any other error
+func errorMessage(err error) string {
+	var httpErr *echo.HTTPError
+	if !errors.As(err, &httpErr) {
+		return err.Error()
+	}
+
+	switch msg := httpErr.Message.(type) {
+	case models.ValidationErrorResponse:
+		fields := make([]string, len(msg.Fields))
+		for i, f := range msg.Fields {
+			fields[i] = f.Field + ": " + f.Message
+		}
+		return msg.Message + ": " + strings.Join(fields, "; ")
+	default:
+		return fmt.Sprint(msg)
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/llm"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEvalRun struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	Summary struct {
+		Rows      int64    `json:"rows"`
+		Completed int64    `json:"completed"`
+		Errors    int64    `json:"errors"`
+		Scored    int64    `json:"scored"`
+		MeanScore *float64 `json:"mean_score"`
+	} `json:"summary"`
+	Results []struct {
+		RunID  sql.NullString  `json:"run_id"`
+		Output sql.NullString  `json:"output"`
+		Score  sql.NullFloat64 `json:"score"`
+		Error  sql.NullString  `json:"error"`
+	} `json:"results"`
+}
+
+func TestEvalRuns(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+	h.Providers = llm.NewRegistry("")
+	h.Providers.Register(llm.NewFakeProvider())
+
+	_, err := store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{
+		ID:    "test-prompt",
+		Title: "Test Prompt",
+	})
+	require.NoError(t, err)
+	_, err = store.CreateVersion(context.Background(), sqlc.CreateVersionParams{
+		ID:       "test-version",
+		PromptID: sql.NullString{String: "test-prompt", Valid: true},
+		Version:  1,
+		Content:  `[{"role":"user","content":"Hello {{name}}"}]`,
+	})
+	require.NoError(t, err)
+
+	call := func(method string, fn echo.HandlerFunc, target string, names []string, values []string, body string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames(names...)
+		c.SetParamValues(values...)
+		return rec, fn(c)
+	}
+
+	// Datasets hold rows of variables with optional expected outputs
+	rec, err := call(http.MethodPost, h.CreateDataset, "/datasets", nil, nil, `{
+		"name": "greetings",
+		"rows": [
+			{"variables": {"name": "Ada"}, "expected": "echo: Hello Ada"},
+			{"variables": {"name": "Bob"}, "expected": "Hi Bob"}
+		]
+	}`)
+	require.NoError(t, err)
+	var dataset struct {
+		ID   string `json:"id"`
+		Rows []struct {
+			Position  int64                  `json:"position"`
+			Variables map[string]interface{} `json:"variables"`
+		} `json:"rows"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dataset))
+	require.Len(t, dataset.Rows, 2)
+	assert.Equal(t, "Ada", dataset.Rows[0].Variables["name"])
+
+	_, err = call(http.MethodPost, h.CreateDataset, "/datasets", nil, nil, `{"name":"greetings"}`)
+	assert.Equal(t, http.StatusConflict, err.(*echo.HTTPError).Code)
+
+	_, err = call(http.MethodPost, h.AddDatasetRows, "/datasets/"+dataset.ID+"/rows", []string{"dataset"}, []string{dataset.ID},
+		`{"rows":[{"variables":{"name":"Cy"}},{"variables":{}}]}`)
+	require.NoError(t, err)
+
+	// Eval runs execute the version over every row in the background
+	versionParams := []string{"id", "version"}
+	versionValues := []string{"test-prompt", "1"}
+	_, err = call(http.MethodPost, h.CreateEvalRun, "/eval-runs", versionParams, versionValues, `{"dataset_id":"missing","model":"fake/echo"}`)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	_, err = call(http.MethodPost, h.CreateEvalRun, "/eval-runs", versionParams, versionValues, `{"dataset_id":"`+dataset.ID+`","model":"nope/model"}`)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+
+	rec, err = call(http.MethodPost, h.CreateEvalRun, "/eval-runs", versionParams, versionValues, `{"dataset_id":"`+dataset.ID+`","model":"fake/echo"}`)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	var started testEvalRun
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &started))
+	assert.Equal(t, int64(4), started.Summary.Rows)
+	require.NoError(t, h.WaitForEvalRuns(context.Background()))
+
+	rec, err = call(http.MethodGet, h.GetEvalRun, "/eval-runs/"+started.ID,
+		[]string{"id", "version", "run"}, []string{"test-prompt", "1", started.ID}, "")
+	require.NoError(t, err)
+	var evalRun testEvalRun
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &evalRun))
+	assert.Equal(t, "completed", evalRun.Status)
+	assert.Equal(t, int64(4), evalRun.Summary.Completed)
+	assert.Equal(t, int64(1), evalRun.Summary.Errors)
+	assert.Equal(t, int64(2), evalRun.Summary.Scored)
+	require.NotNil(t, evalRun.Summary.MeanScore)
+	assert.Equal(t, 0.5, *evalRun.Summary.MeanScore)
+
+	// Results follow the dataset's row order
+	require.Len(t, evalRun.Results, 4)
+	assert.Equal(t, "echo: Hello Ada", evalRun.Results[0].Output.String)
+	assert.Equal(t, 1.0, evalRun.Results[0].Score.Float64)
+	assert.Equal(t, 0.0, evalRun.Results[1].Score.Float64)
+	assert.True(t, evalRun.Results[1].RunID.Valid)
+	assert.False(t, evalRun.Results[2].Score.Valid)
+	assert.Contains(t, evalRun.Results[3].Error.String, "name")
+
+	// Datasets used by eval runs cannot be deleted
+	_, err = call(http.MethodDelete, h.DeleteDataset, "/datasets/"+dataset.ID, []string{"dataset"}, []string{dataset.ID}, "")
+	assert.Equal(t, http.StatusConflict, err.(*echo.HTTPError).Code)
+
+	// Eval runs belong to the version they ran
+	_, err = call(http.MethodGet, h.GetEvalRun, "/eval-runs/"+started.ID,
+		[]string{"id", "version", "run"}, []string{"test-prompt", "1", "missing"}, "")
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+}
+
+func TestWaitForEvalRuns(t *testing.T) {
+	h := &Handler{}
+
+	// A run still going when the deadline passes is reported
+	h.evalRuns.Add(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, h.WaitForEvalRuns(ctx), context.DeadlineExceeded)
+
+	h.evalRuns.Done()
+	assert.NoError(t, h.WaitForEvalRuns(context.Background()))
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// flatSlope is the largest change in mean score per version that still
+// counts as a flat trend
+const flatSlope = 0.01
+
+// GetEvalSummary returns score statistics for every version of a prompt
+// and the trend across versions. ?scorer= limits it to one scorer's
+// evaluations, such as "manual".
+func (h *Handler) GetEvalSummary(c echo.Context) error {
+	promptID := c.Param("id")
+	scorer := c.QueryParam("scorer")
+
+	// Check if prompt exists
+	_, err := h.Store.GetPrompt(c.Request().Context(), promptID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompt: "+err.Error())
+	}
+
+	stats, err := h.Store.ListVersionScoreStats(c.Request().Context(), sqlc.ListVersionScoreStatsParams{
+		PromptID: sql.NullString{String: promptID, Valid: true},
+		Scorer:   sql.NullString{String: scorer, Valid: scorer != ""},
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to summarize evaluations: "+err.Error())
+	}
+
+	summary := models.EvalSummary{
+		PromptID: promptID,
+		Scorer:   scorer,
+		Versions: make([]models.VersionScoreStats, len(stats)),
+	}
+	var scored []models.VersionScoreStats
+	for i, row := range stats {
+		v := models.VersionScoreStats{
+			Version:   row.Version,
+			CreatedAt: row.CreatedAt.Time,
+			Count:     row.Count,
+			Mean:      floatPtr(row.Mean),
+			Median:    floatPtr(row.Median),
+			P10:       floatPtr(row.P10),
+			P90:       floatPtr(row.P90),
+			StdDev:    floatPtr(row.Stddev),
+		}
+		if v.Mean != nil {
+			if len(scored) > 0 {
+				delta := *v.Mean - *scored[len(scored)-1].Mean
+				v.Delta = &delta
+			}
+			scored = append(scored, v)
+		}
+		summary.Versions[i] = v
+	}
+	summary.Trend = scoreTrend(scored)
+
+	return c.JSON(http.StatusOK, summary)
+}
+
+// scoreTrend fits a line through the mean scores of versions with scores
+func scoreTrend(scored []models.VersionScoreStats) models.ScoreTrend {
+	trend := models.ScoreTrend{Versions: len(scored)}
+	if len(scored) < 2 {
+		return trend
+	}
+
+	n := float64(len(scored))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, v := range scored {
+		x, y := float64(v.Version), *v.Mean
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	change := *scored[len(scored)-1].Mean - *scored[0].Mean
+	trend.Slope, trend.Change = &slope, &change
+
+	switch {
+	case slope > flatSlope:
+		trend.Direction = "improving"
+	case slope < -flatSlope:
+		trend.Direction = "declining"
+	default:
+		trend.Direction = "flat"
+	}
+	return trend
+}
+
+// floatPtr returns a pointer to a valid float, or nil
+func floatPtr(f sql.NullFloat64) *float64 {
+	if !f.Valid {
+		return nil
+	}
+	return &f.Float64
+}
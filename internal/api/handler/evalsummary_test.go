@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEvalSummary(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	_, err := store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{ID: "test-prompt", Title: "Test Prompt"})
+	require.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		_, err = store.CreateVersion(context.Background(), sqlc.CreateVersionParams{
+			ID:       fmt.Sprintf("test-version-%d", i),
+			PromptID: sql.NullString{String: "test-prompt", Valid: true},
+			Version:  int64(i),
+			Content:  `[{"role":"user","content":"Hello"}]`,
+		})
+		require.NoError(t, err)
+	}
+
+	evaluate := func(version int, scorer string, score sql.NullFloat64) {
+		_, err := store.CreateEvaluation(context.Background(), sqlc.CreateEvaluationParams{
+			ID:              fmt.Sprintf("eval-%d-%s-%v", version, scorer, score.Float64),
+			PromptVersionID: sql.NullString{String: fmt.Sprintf("test-version-%d", version), Valid: true},
+			Score:           score,
+			Scorer:          scorer,
+		})
+		require.NoError(t, err)
+	}
+	for i := 10; i >= 1; i-- {
+		evaluate(1, "manual", sql.NullFloat64{Float64: float64(i) / 10, Valid: true})
+	}
+	evaluate(3, "manual", sql.NullFloat64{Float64: 0.8, Valid: true})
+	evaluate(3, "exact_match", sql.NullFloat64{Float64: 1, Valid: true})
+	evaluate(3, "manual", sql.NullFloat64{})
+
+	summarize := func(query string) (models.EvalSummary, error) {
+		req := httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("test-prompt")
+		var summary models.EvalSummary
+		if err := h.GetEvalSummary(c); err != nil {
+			return summary, err
+		}
+		return summary, json.Unmarshal(rec.Body.Bytes(), &summary)
+	}
+
+	// Every version is listed, with statistics for those with scores
+	summary, err := summarize("")
+	require.NoError(t, err)
+	require.Len(t, summary.Versions, 3)
+
+	v1 := summary.Versions[0]
+	assert.Equal(t, int64(10), v1.Count)
+	assert.InDelta(t, 0.55, *v1.Mean, 1e-9)
+	assert.InDelta(t, 0.55, *v1.Median, 1e-9)
+	assert.InDelta(t, 0.19, *v1.P10, 1e-9)
+	assert.InDelta(t, 0.91, *v1.P90, 1e-9)
+	assert.InDelta(t, 0.302765, *v1.StdDev, 1e-6)
+	assert.Nil(t, v1.Delta)
+
+	v2 := summary.Versions[1]
+	assert.Equal(t, int64(0), v2.Count)
+	assert.Nil(t, v2.Mean)
+	assert.Nil(t, v2.Median)
+
+	// Unscored evaluations are left out and deltas skip versions without
+	// scores
+	v3 := summary.Versions[2]
+	assert.Equal(t, int64(2), v3.Count)
+	assert.InDelta(t, 0.9, *v3.Median, 1e-9)
+	assert.InDelta(t, 0.35, *v3.Delta, 1e-9)
+
+	assert.Equal(t, 2, summary.Trend.Versions)
+	assert.InDelta(t, 0.175, *summary.Trend.Slope, 1e-9)
+	assert.Equal(t, "improving", summary.Trend.Direction)
+
+	// Summaries can be limited to one scorer
+	summary, err = summarize("scorer=exact_match")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), summary.Versions[0].Count)
+	assert.Equal(t, int64(1), summary.Versions[2].Count)
+	assert.Nil(t, summary.Versions[2].StdDev)
+	assert.Empty(t, summary.Trend.Direction)
+
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	c.SetParamNames("id")
+	c.SetParamValues("missing")
+	err = h.GetEvalSummary(c)
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+}
@@ -1,39 +1,69 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
 	"github.com/epuerta9/prompts.kitchenai/db"
 	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/eval"
+	"github.com/epuerta9/prompts.kitchenai/internal/llm"
 	"github.com/epuerta9/prompts.kitchenai/pkg/models"
 )
 
 // Handler contains the dependencies for the API handlers
 type Handler struct {
-	Store *db.Store
+	Store     *db.Store
+	Providers *llm.Registry
+	Scorers   *eval.Registry
+
+	// CompleteTimeout bounds each non-streaming model call, including
+	// reading the response body
+	CompleteTimeout time.Duration
+
+	// evalRuns tracks eval runs executing in the background
+	evalRuns sync.WaitGroup
 }
 
 // NewHandler creates a new handler with the given store
 func NewHandler(store *db.Store) *Handler {
-	return &Handler{
-		Store: store,
-	}
+	h := &Handler{
+		Store:           store,
+		Providers:       llm.NewRegistryFromEnv(),
+		Scorers:         eval.NewRegistry(),
+		CompleteTimeout: defaultCompleteTimeout,
+	}
+	h.Scorers.Register("llm_judge", h.newJudgeScorer)
+	return h
 }
 
-// GetPrompts returns all prompts
+// GetPrompts returns a page of prompts
 func (h *Handler) GetPrompts(c echo.Context) error {
-	prompts, err := h.Store.ListPrompts(c.Request().Context())
+	filter, page, err := parseListParams(c, db.SortCreatedAt, db.SortUpdatedAt, db.SortTitle)
+	if err != nil {
+		return err
+	}
+
+	prompts, next, err := h.Store.ListPromptsPage(c.Request().Context(), filter, page)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompts: "+err.Error())
 	}
+	payloads, err := h.newAuthors().prompts(c.Request().Context(), prompts)
+	if err != nil {
+		return err
+	}
 
-	return c.JSON(http.StatusOK, prompts)
+	return c.JSON(http.StatusOK, listResponse(payloads, page, next))
 }
 
 // GetPrompt returns a specific prompt by ID
@@ -48,7 +78,15 @@ func (h *Handler) GetPrompt(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompt: "+err.Error())
 	}
 
-	return c.JSON(http.StatusOK, prompt)
+	if notModified(c, promptETag(prompt)) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	payload, err := h.newPromptPayload(c.Request().Context(), prompt)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, payload)
 }
 
 // Convert models.Message to string JSON for storage
@@ -64,6 +102,39 @@ func toDBMessages(msgs []models.Message) string {
 	return string(messagesJSON)
 }
 
+// Convert stored message JSON back into models.Message
+func fromDBMessages(content string) ([]models.Message, error) {
+	var msgs []models.Message
+	if err := json.Unmarshal([]byte(content), &msgs); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// findVersion loads a prompt version by number, or the latest version
+// when number is zero
+func (h *Handler) findVersion(ctx context.Context, promptID string, number int64) (sqlc.PromptVersion, error) {
+	if number == 0 {
+		latest, err := h.Store.GetLatestVersionNumber(ctx, sql.NullString{String: promptID, Valid: true})
+		if err != nil {
+			return sqlc.PromptVersion{}, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch versions: "+err.Error())
+		}
+		number, _ = latest.(int64)
+	}
+
+	version, err := h.Store.GetVersionByPromptAndNumber(ctx, sqlc.GetVersionByPromptAndNumberParams{
+		PromptID: sql.NullString{String: promptID, Valid: true},
+		Version:  number,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return sqlc.PromptVersion{}, echo.NewHTTPError(http.StatusNotFound, "Version not found")
+		}
+		return sqlc.PromptVersion{}, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch version: "+err.Error())
+	}
+	return version, nil
+}
+
 // CreatePrompt creates a new prompt
 func (h *Handler) CreatePrompt(c echo.Context) error {
 	var req models.PromptRequest
@@ -75,13 +146,53 @@ func (h *Handler) CreatePrompt(c echo.Context) error {
 	if req.Title == "" || req.Description == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "Title and description are required")
 	}
+	if err := validateSchema(req.Inputs, req.Messages); err != nil {
+		return err
+	}
+	author, err := h.author(c, req.CreatedBy)
+	if err != nil {
+		return err
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return err
+	}
+	var collectionID sql.NullString
+	if req.CollectionID != nil {
+		if collectionID, err = h.collectionRef(c.Request().Context(), *req.CollectionID); err != nil {
+			return err
+		}
+	}
+
+	// Pick a slug, deriving one from the title if none was requested
+	slug := req.Slug
+	if slug != "" {
+		if err := validateSlug(slug, req.Namespace); err != nil {
+			return err
+		}
+		_, err = h.Store.GetPromptBySlug(c.Request().Context(), sqlc.GetPromptBySlugParams{Namespace: req.Namespace, Slug: slug})
+		if err == nil {
+			return echo.NewHTTPError(http.StatusConflict, "Slug is already in use")
+		}
+	} else {
+		if err := validateSlug(slugify(req.Title), req.Namespace); err != nil {
+			return err
+		}
+		slug, err = h.uniqueSlug(c.Request().Context(), req.Namespace, slugify(req.Title))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create prompt: "+err.Error())
+		}
+	}
 
 	// Create a new prompt
 	prompt := sqlc.CreatePromptParams{
-		ID:          uuid.New().String(),
-		Title:       req.Title,
-		Description: sql.NullString{String: req.Description, Valid: true},
-		CreatedBy:   sql.NullString{String: req.CreatedBy.ID, Valid: true},
+		ID:           uuid.New().String(),
+		Title:        req.Title,
+		Description:  sql.NullString{String: req.Description, Valid: true},
+		Slug:         slug,
+		Namespace:    req.Namespace,
+		CollectionID: collectionID,
+		CreatedBy:    sql.NullString{String: author.ID, Valid: author.ID != ""},
 	}
 
 	// Save to database
@@ -97,7 +208,8 @@ func (h *Handler) CreatePrompt(c echo.Context) error {
 			PromptID:  sql.NullString{String: result.ID, Valid: true},
 			Version:   1,
 			Content:   toDBMessages(req.Messages),
-			CreatedBy: sql.NullString{String: req.CreatedBy.ID, Valid: true},
+			Inputs:    encodeInputs(req.Inputs),
+			CreatedBy: sql.NullString{String: author.ID, Valid: author.ID != ""},
 		}
 
 		_, err := h.Store.CreateVersion(c.Request().Context(), version)
@@ -107,7 +219,18 @@ func (h *Handler) CreatePrompt(c echo.Context) error {
 		}
 	}
 
-	return c.JSON(http.StatusCreated, result)
+	payload := promptPayload{authoredPrompt: authoredPrompt{Prompt: result}, Tags: tags}
+	if author.ID != "" {
+		payload.CreatedBy = &author
+	}
+	if len(tags) > 0 {
+		payload.Tags, err = h.Store.SetPromptTags(c.Request().Context(), result.ID, tags)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to set tags: "+err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusCreated, payload)
 }
 
 // UpdatePrompt updates an existing prompt
@@ -128,28 +251,74 @@ func (h *Handler) UpdatePrompt(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompt: "+err.Error())
 	}
 
+	ifRevision, err := checkIfMatch(c, existingPrompt)
+	if err != nil {
+		return err
+	}
+
+	var tags []string
+	if req.Tags != nil {
+		if tags, err = normalizeTags(req.Tags); err != nil {
+			return err
+		}
+	}
+	collectionID := existingPrompt.CollectionID
+	if req.CollectionID != nil {
+		if collectionID, err = h.collectionRef(c.Request().Context(), *req.CollectionID); err != nil {
+			return err
+		}
+	}
+
 	// Update fields
 	params := sqlc.UpdatePromptParams{
-		ID:          existingPrompt.ID,
-		Title:       req.Title,
-		Description: sql.NullString{String: req.Description, Valid: true},
+		ID:           existingPrompt.ID,
+		Title:        req.Title,
+		Description:  sql.NullString{String: req.Description, Valid: true},
+		CollectionID: collectionID,
+		IfRevision:   ifRevision,
 	}
 
 	// Save to database
 	result, err := h.Store.UpdatePrompt(c.Request().Context(), params)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return missedWrite(ifRevision)
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update prompt: "+err.Error())
 	}
 
-	return c.JSON(http.StatusOK, result)
+	if req.Tags != nil {
+		if _, err := h.Store.SetPromptTags(c.Request().Context(), result.ID, tags); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to set tags: "+err.Error())
+		}
+	}
+	payload, err := h.newPromptPayload(c.Request().Context(), result)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(headerETag, promptETag(result))
+	return c.JSON(http.StatusOK, payload)
 }
 
-// DeletePrompt deletes a prompt
+// DeletePrompt moves a prompt to the trash, or permanently deletes it and
+// everything that belongs to it when called with ?hard=true
 func (h *Handler) DeletePrompt(c echo.Context) error {
 	id := c.Param("id")
 
-	// Check if prompt exists
-	_, err := h.Store.GetPrompt(c.Request().Context(), id)
+	hard := false
+	if raw := c.QueryParam("hard"); raw != "" {
+		var err error
+		if hard, err = strconv.ParseBool(raw); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid hard parameter")
+		}
+	}
+
+	// Check if prompt exists. Hard deletes also empty the trash.
+	prompt, err := h.Store.GetPrompt(c.Request().Context(), id)
+	if err == sql.ErrNoRows && hard {
+		prompt, err = h.Store.GetDeletedPrompt(c.Request().Context(), id)
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
@@ -157,21 +326,66 @@ func (h *Handler) DeletePrompt(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompt: "+err.Error())
 	}
 
-	// Delete from database
-	err = h.Store.DeletePrompt(c.Request().Context(), id)
+	ifRevision, err := checkIfMatch(c, prompt)
+	if err != nil {
+		return err
+	}
+
+	if hard {
+		err = h.Store.PurgePrompt(c.Request().Context(), id, ifRevision)
+		if err != nil {
+			if errors.Is(err, db.ErrRevisionMismatch) || err == sql.ErrNoRows {
+				return missedWrite(ifRevision)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete prompt: "+err.Error())
+		}
+		return c.JSON(http.StatusOK, map[string]string{"status": "purged", "id": id})
+	}
+
+	// Mark as deleted, keeping children until the prompt is purged
+	deleted, err := h.Store.SoftDeletePrompt(c.Request().Context(), sqlc.SoftDeletePromptParams{ID: id, IfRevision: ifRevision})
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete prompt: "+err.Error())
 	}
+	if deleted == 0 {
+		return missedWrite(ifRevision)
+	}
 
 	return c.JSON(http.StatusOK, map[string]string{"status": "deleted", "id": id})
 }
 
-// GetVersions returns all versions of a prompt
+// RestorePrompt brings a soft-deleted prompt back
+func (h *Handler) RestorePrompt(c echo.Context) error {
+	id := c.Param("id")
+
+	prompt, err := h.Store.RestorePrompt(c.Request().Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "Deleted prompt not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to restore prompt: "+err.Error())
+	}
+
+	payload, err := h.newAuthors().prompt(c.Request().Context(), prompt)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(headerETag, promptETag(prompt))
+	return c.JSON(http.StatusOK, payload)
+}
+
+// GetVersions returns a page of versions of a prompt
 func (h *Handler) GetVersions(c echo.Context) error {
 	promptID := c.Param("id")
 
+	filter, page, err := parseListParams(c, db.SortVersion)
+	if err != nil {
+		return err
+	}
+
 	// Check if prompt exists
-	_, err := h.Store.GetPrompt(c.Request().Context(), promptID)
+	_, err = h.Store.GetPrompt(c.Request().Context(), promptID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
@@ -180,12 +394,17 @@ func (h *Handler) GetVersions(c echo.Context) error {
 	}
 
 	// Get versions from database
-	versions, err := h.Store.ListVersions(c.Request().Context(), sql.NullString{String: promptID, Valid: true})
+	versions, next, err := h.Store.ListVersionsPage(c.Request().Context(), promptID, filter, page)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch versions: "+err.Error())
 	}
 
-	return c.JSON(http.StatusOK, versions)
+	payloads, err := h.newAuthors().versions(c.Request().Context(), versions)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, listResponse(payloads, page, next))
 }
 
 // GetVersion returns a specific version of a prompt
@@ -211,15 +430,27 @@ func (h *Handler) GetVersion(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch version: "+err.Error())
 	}
 
-	return c.JSON(http.StatusOK, version)
+	if notModified(c, versionETag(version)) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	payload, err := h.newAuthors().version(c.Request().Context(), version)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, payload)
 }
 
-// GetComments returns all comments for a prompt
+// GetComments returns a page of comments for a prompt
 func (h *Handler) GetComments(c echo.Context) error {
 	promptID := c.Param("id")
 
+	filter, page, err := parseListParams(c, db.SortCreatedAt)
+	if err != nil {
+		return err
+	}
+
 	// Check if prompt exists
-	_, err := h.Store.GetPrompt(c.Request().Context(), promptID)
+	_, err = h.Store.GetPrompt(c.Request().Context(), promptID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
@@ -228,12 +459,16 @@ func (h *Handler) GetComments(c echo.Context) error {
 	}
 
 	// Get comments from database
-	comments, err := h.Store.ListComments(c.Request().Context(), sql.NullString{String: promptID, Valid: true})
+	comments, next, err := h.Store.ListCommentsPage(c.Request().Context(), promptID, filter, page)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch comments: "+err.Error())
 	}
+	payloads, err := h.newAuthors().comments(c.Request().Context(), comments)
+	if err != nil {
+		return err
+	}
 
-	return c.JSON(http.StatusOK, comments)
+	return c.JSON(http.StatusOK, listResponse(payloads, page, next))
 }
 
 // CreateVersion creates a new version of a prompt
@@ -244,9 +479,16 @@ func (h *Handler) CreateVersion(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
 	}
+	if err := validateSchema(req.Inputs, req.Messages); err != nil {
+		return err
+	}
+	author, err := h.author(c, req.CreatedBy)
+	if err != nil {
+		return err
+	}
 
 	// Check if prompt exists
-	_, err := h.Store.GetPrompt(c.Request().Context(), promptID)
+	prompt, err := h.Store.GetPrompt(c.Request().Context(), promptID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
@@ -254,25 +496,21 @@ func (h *Handler) CreateVersion(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompt: "+err.Error())
 	}
 
-	// Get latest version number
-	latestVersion, err := h.Store.GetLatestVersionNumber(c.Request().Context(), sql.NullString{String: promptID, Valid: true})
+	ifRevision, err := checkIfMatch(c, prompt)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch versions: "+err.Error())
-	}
-
-	// Convert interface{} to int64
-	var nextVersion int64 = 1
-	if v, ok := latestVersion.(int64); ok {
-		nextVersion = v + 1
+		return err
 	}
 
-	// Create new version
-	version := sqlc.CreateVersionParams{
-		ID:        req.ID,
-		PromptID:  sql.NullString{String: promptID, Valid: true},
-		Version:   nextVersion,
-		Content:   toDBMessages(req.Messages),
-		CreatedBy: sql.NullString{String: req.CreatedBy.ID, Valid: true},
+	// Create new version, numbered after the latest one
+	version := db.CreateNextVersionParams{
+		CreateVersionParams: sqlc.CreateVersionParams{
+			ID:        req.ID,
+			PromptID:  sql.NullString{String: promptID, Valid: true},
+			Content:   toDBMessages(req.Messages),
+			Inputs:    encodeInputs(req.Inputs),
+			CreatedBy: sql.NullString{String: author.ID, Valid: author.ID != ""},
+		},
+		IfRevision: ifRevision,
 	}
 
 	// If no ID provided, generate one
@@ -281,12 +519,83 @@ func (h *Handler) CreateVersion(c echo.Context) error {
 	}
 
 	// Save to database
-	result, err := h.Store.CreateVersion(c.Request().Context(), version)
+	result, err := h.Store.CreateNextVersion(c.Request().Context(), version)
 	if err != nil {
+		if errors.Is(err, db.ErrRevisionMismatch) {
+			return echo.NewHTTPError(http.StatusPreconditionFailed, "Prompt has been modified")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create version: "+err.Error())
 	}
 
-	return c.JSON(http.StatusCreated, result)
+	payload := newVersionPayload(result)
+	if author.ID != "" {
+		payload.CreatedBy = &author
+	}
+
+	c.Response().Header().Set(headerETag, versionETag(result))
+	return c.JSON(http.StatusCreated, payload)
+}
+
+// RestoreVersion creates a new version that copies an earlier one
+func (h *Handler) RestoreVersion(c echo.Context) error {
+	promptID := c.Param("id")
+	versionNum, err := strconv.ParseInt(c.Param("version"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid version number")
+	}
+
+	var req models.RestoreRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	// If no ID provided, generate one
+	if req.ID == "" {
+		req.ID = uuid.New().String()
+	}
+	author, err := h.author(c, req.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	// Check if prompt exists
+	prompt, err := h.Store.GetPrompt(c.Request().Context(), promptID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompt: "+err.Error())
+	}
+
+	ifRevision, err := checkIfMatch(c, prompt)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.Store.RestoreVersion(c.Request().Context(), db.RestoreVersionParams{
+		ID:         req.ID,
+		PromptID:   promptID,
+		Version:    versionNum,
+		CreatedBy:  sql.NullString{String: author.ID, Valid: author.ID != ""},
+		IfRevision: ifRevision,
+	})
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			return echo.NewHTTPError(http.StatusNotFound, "Version not found")
+		case errors.Is(err, db.ErrRevisionMismatch):
+			return echo.NewHTTPError(http.StatusPreconditionFailed, "Prompt has been modified")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to restore version: "+err.Error())
+	}
+
+	payload := newVersionPayload(result)
+	if author.ID != "" {
+		payload.CreatedBy = &author
+	}
+
+	c.Response().Header().Set(headerETag, versionETag(result))
+	return c.JSON(http.StatusCreated, payload)
 }
 
 // AddComment adds a comment to a prompt
@@ -297,9 +606,13 @@ func (h *Handler) AddComment(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
 	}
+	author, err := h.author(c, req.CreatedBy)
+	if err != nil {
+		return err
+	}
 
 	// Check if prompt exists
-	_, err := h.Store.GetPrompt(c.Request().Context(), promptID)
+	_, err = h.Store.GetPrompt(c.Request().Context(), promptID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
@@ -312,7 +625,7 @@ func (h *Handler) AddComment(c echo.Context) error {
 		ID:        req.ID,
 		PromptID:  sql.NullString{String: promptID, Valid: true},
 		Content:   req.Content,
-		CreatedBy: sql.NullString{String: req.CreatedBy.ID, Valid: true},
+		CreatedBy: sql.NullString{String: author.ID, Valid: author.ID != ""},
 	}
 
 	// If no ID provided, generate one
@@ -326,7 +639,11 @@ func (h *Handler) AddComment(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create comment: "+err.Error())
 	}
 
-	return c.JSON(http.StatusCreated, result)
+	payload := authoredComment{Comment: result}
+	if author.ID != "" {
+		payload.CreatedBy = &author
+	}
+	return c.JSON(http.StatusCreated, payload)
 }
 
 // CreateEvaluation creates a new evaluation for a version
@@ -338,6 +655,10 @@ func (h *Handler) CreateEvaluation(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
 	}
+	author, err := h.author(c, req.CreatedBy)
+	if err != nil {
+		return err
+	}
 
 	// Parse version number
 	versionNum, err := strconv.ParseInt(versionStr, 10, 64)
@@ -363,7 +684,8 @@ func (h *Handler) CreateEvaluation(c echo.Context) error {
 		PromptVersionID: sql.NullString{String: version.ID, Valid: true},
 		Score:           sql.NullFloat64{Float64: req.Score, Valid: true},
 		Notes:           sql.NullString{String: req.Notes, Valid: true},
-		CreatedBy:       sql.NullString{String: req.CreatedBy.ID, Valid: true},
+		CreatedBy:       sql.NullString{String: author.ID, Valid: author.ID != ""},
+		Scorer:          eval.Manual,
 	}
 
 	// If no ID provided, generate one
@@ -377,10 +699,14 @@ func (h *Handler) CreateEvaluation(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create evaluation: "+err.Error())
 	}
 
-	return c.JSON(http.StatusCreated, result)
+	payload := authoredEvaluation{Evaluation: result}
+	if author.ID != "" {
+		payload.CreatedBy = &author
+	}
+	return c.JSON(http.StatusCreated, payload)
 }
 
-// GetEvaluations returns all evaluations for a prompt version
+// GetEvaluations returns a page of evaluations for a prompt version
 func (h *Handler) GetEvaluations(c echo.Context) error {
 	promptID := c.Param("id")
 	versionStr := c.Param("version")
@@ -391,6 +717,11 @@ func (h *Handler) GetEvaluations(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid version number")
 	}
 
+	filter, page, err := parseListParams(c, db.SortCreatedAt)
+	if err != nil {
+		return err
+	}
+
 	// Get version
 	version, err := h.Store.GetVersionByPromptAndNumber(c.Request().Context(), sqlc.GetVersionByPromptAndNumberParams{
 		PromptID: sql.NullString{String: promptID, Valid: true},
@@ -404,35 +735,53 @@ func (h *Handler) GetEvaluations(c echo.Context) error {
 	}
 
 	// Get evaluations from database
-	evals, err := h.Store.ListEvaluations(c.Request().Context(), sql.NullString{String: version.ID, Valid: true})
+	evals, next, err := h.Store.ListEvaluationsPage(c.Request().Context(), version.ID, filter, page)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch evaluations: "+err.Error())
 	}
+	payloads, err := h.newAuthors().evaluations(c.Request().Context(), evals)
+	if err != nil {
+		return err
+	}
 
-	return c.JSON(http.StatusOK, evals)
+	return c.JSON(http.StatusOK, listResponse(payloads, page, next))
 }
 
-// RunPrompt runs a prompt with a specific model
+// RunPrompt runs a prompt with a specific model. Clients that send
+// "Accept: text/event-stream" get the response streamed as server-sent
+// events, the same as RunPromptStream.
 func (h *Handler) RunPrompt(c echo.Context) error {
 	var req models.RunPromptRequest
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
 	}
+	var err error
+	if req.CreatedBy, err = h.author(c, req.CreatedBy); err != nil {
+		return err
+	}
+
+	if strings.Contains(c.Request().Header.Get(echo.HeaderAccept), mimeEventStream) {
+		return h.streamRun(c, req)
+	}
+
+	run, err := h.prepareRun(c.Request().Context(), req)
+	if err != nil {
+		return err
+	}
+
+	// Call the model
+	start := time.Now()
+	result, err := h.complete(c.Request().Context(), run)
+	runID := h.recordRun(c, req, run, result, err, time.Since(start))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadGateway, "Failed to run prompt: "+err.Error())
+	}
 
-	// In a real app, you would call the LLM API here
-	// For now, we'll just return a mock response
 	response := models.RunPromptResponse{
-		Response: "This is a mock response from the LLM API. In a real app, this would be the actual response from the model.",
+		RunID:    runID,
+		Response: result.Content,
 		Model:    req.Model,
-		Usage: struct {
-			PromptTokens     int `json:"prompt_tokens"`
-			CompletionTokens int `json:"completion_tokens"`
-			TotalTokens      int `json:"total_tokens"`
-		}{
-			PromptTokens:     150,
-			CompletionTokens: 200,
-			TotalTokens:      350,
-		},
+		Usage:    result.Usage,
 	}
 
 	return c.JSON(http.StatusOK, response)
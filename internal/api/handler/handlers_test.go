@@ -9,10 +9,16 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/epuerta9/prompts.kitchenai/db"
 	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/llm"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -23,25 +29,26 @@ type testPrompt struct {
 	ID          string          `json:"id"`
 	Title       string          `json:"title"`
 	Description sql.NullString  `json:"description"`
-	CreatedBy   sql.NullString  `json:"created_by"`
+	CreatedBy   *models.User    `json:"created_by"`
 	CreatedAt   json.RawMessage `json:"created_at"`
 	UpdatedAt   json.RawMessage `json:"updated_at"`
 }
 
 type testVersion struct {
-	ID        string          `json:"id"`
-	PromptID  sql.NullString  `json:"prompt_id"`
-	Version   int64           `json:"version"`
-	Content   string          `json:"content"`
-	CreatedBy sql.NullString  `json:"created_by"`
-	CreatedAt json.RawMessage `json:"created_at"`
+	ID           string          `json:"id"`
+	PromptID     sql.NullString  `json:"prompt_id"`
+	Version      int64           `json:"version"`
+	Content      string          `json:"content"`
+	RestoredFrom sql.NullInt64   `json:"restored_from"`
+	CreatedBy    *models.User    `json:"created_by"`
+	CreatedAt    json.RawMessage `json:"created_at"`
 }
 
 type testComment struct {
 	ID        string          `json:"id"`
 	PromptID  sql.NullString  `json:"prompt_id"`
 	Content   string          `json:"content"`
-	CreatedBy sql.NullString  `json:"created_by"`
+	CreatedBy *models.User    `json:"created_by"`
 	CreatedAt json.RawMessage `json:"created_at"`
 }
 
@@ -50,7 +57,7 @@ type testEval struct {
 	PromptVersionID sql.NullString  `json:"prompt_version_id"`
 	Score           sql.NullFloat64 `json:"score"`
 	Notes           sql.NullString  `json:"notes"`
-	CreatedBy       sql.NullString  `json:"created_by"`
+	CreatedBy       *models.User    `json:"created_by"`
 	CreatedAt       json.RawMessage `json:"created_at"`
 }
 
@@ -130,13 +137,106 @@ func TestGetPrompts(t *testing.T) {
 	require.NoError(t, h.GetPrompts(c))
 	assert.Equal(t, http.StatusOK, rec.Code)
 
-	var response []testPrompt
+	var response struct {
+		Items []testPrompt `json:"items"`
+	}
 	err = json.Unmarshal(rec.Body.Bytes(), &response)
 	require.NoError(t, err)
-	assert.Len(t, response, 1)
-	assert.Equal(t, prompt.ID, response[0].ID)
-	assert.Equal(t, prompt.Title, response[0].Title)
-	assert.Equal(t, prompt.Description.String, response[0].Description.String)
+	assert.Len(t, response.Items, 1)
+	assert.Equal(t, prompt.ID, response.Items[0].ID)
+	assert.Equal(t, prompt.Title, response.Items[0].Title)
+	assert.Equal(t, prompt.Description.String, response.Items[0].Description.String)
+}
+
+func TestDeletePrompt(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	ctx := context.Background()
+	for _, id := range []string{"keep-prompt", "purge-prompt"} {
+		_, err := store.CreatePrompt(ctx, sqlc.CreatePromptParams{ID: id, Title: id})
+		require.NoError(t, err)
+		_, err = store.CreateVersion(ctx, sqlc.CreateVersionParams{
+			ID:       id + "-v1",
+			PromptID: sql.NullString{String: id, Valid: true},
+			Version:  1,
+			Content:  `[{"role":"user","content":"hi"}]`,
+		})
+		require.NoError(t, err)
+		_, err = store.CreateComment(ctx, sqlc.CreateCommentParams{
+			ID:       id + "-c1",
+			PromptID: sql.NullString{String: id, Valid: true},
+			Content:  "looks good",
+		})
+		require.NoError(t, err)
+	}
+
+	call := func(method string, fn echo.HandlerFunc, id, query string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(method, "/prompts/"+id+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/prompts/:id/versions/:version")
+		c.SetParamNames("id", "version")
+		c.SetParamValues(id, "1")
+		return rec, fn(c)
+	}
+
+	// Test DELETE /prompts/:id moves the prompt to the trash
+	_, err := call(http.MethodDelete, h.DeletePrompt, "keep-prompt", "")
+	require.NoError(t, err)
+	_, err = call(http.MethodGet, h.GetPrompt, "keep-prompt", "")
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+	prompts, err := store.ListPrompts(ctx)
+	require.NoError(t, err)
+	assert.Len(t, prompts, 1)
+
+	// Nothing that belongs to a trashed prompt is served or changed
+	for _, fn := range []echo.HandlerFunc{h.GetVersion, h.RenderVersion, h.GetComments, h.CreateEvaluation, h.CreateVersion} {
+		_, err = call(http.MethodPost, fn, "keep-prompt", "")
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	}
+	_, err = store.GetVersion(ctx, "keep-prompt-v1")
+	assert.Equal(t, sql.ErrNoRows, err)
+
+	// Test POST /prompts/:id/restore brings it back with its children
+	rec, err := call(http.MethodPost, h.RestorePrompt, "keep-prompt", "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	versions, err := store.ListVersions(ctx, sql.NullString{String: "keep-prompt", Valid: true})
+	require.NoError(t, err)
+	assert.Len(t, versions, 1)
+	_, err = call(http.MethodPost, h.RestorePrompt, "keep-prompt", "")
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+
+	// Test DELETE /prompts/:id?hard=true removes the prompt and its children
+	_, err = call(http.MethodDelete, h.DeletePrompt, "purge-prompt", "?hard=true")
+	require.NoError(t, err)
+	_, err = store.GetDeletedPrompt(ctx, "purge-prompt")
+	assert.Equal(t, sql.ErrNoRows, err)
+	versions, err = store.ListVersions(ctx, sql.NullString{String: "purge-prompt", Valid: true})
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+	comments, err := store.ListComments(ctx, sql.NullString{String: "purge-prompt", Valid: true})
+	require.NoError(t, err)
+	assert.Empty(t, comments)
+
+	// Only tombstones older than the cutoff are purged
+	_, err = call(http.MethodDelete, h.DeletePrompt, "keep-prompt", "")
+	require.NoError(t, err)
+	purged, err := store.PurgeDeletedPrompts(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged)
+	purged, err = store.PurgeDeletedPrompts(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	_, err = store.GetDeletedPrompt(ctx, "keep-prompt")
+	assert.Equal(t, sql.ErrNoRows, err)
 }
 
 func TestGetVersions(t *testing.T) {
@@ -184,12 +284,128 @@ func TestGetVersions(t *testing.T) {
 	require.NoError(t, h.GetVersions(c))
 	assert.Equal(t, http.StatusOK, rec.Code)
 
-	var response []testVersion
+	var response struct {
+		Items []testVersion `json:"items"`
+	}
 	err = json.Unmarshal(rec.Body.Bytes(), &response)
 	require.NoError(t, err)
-	assert.Len(t, response, 1)
-	assert.Equal(t, version.ID, response[0].ID)
-	assert.Equal(t, version.Version, response[0].Version)
+	assert.Len(t, response.Items, 1)
+	assert.Equal(t, version.ID, response.Items[0].ID)
+	assert.Equal(t, version.Version, response.Items[0].Version)
+}
+
+func TestCreateVersionConcurrent(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	_, err := store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{ID: "test-prompt", Title: "Test Prompt"})
+	require.NoError(t, err)
+
+	// Test POST /prompts/:id/versions from many goroutines at once
+	const writers = 25
+	versions := make(chan int64, writers)
+	errs := make(chan error, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"messages":[{"role":"user","content":"v%d"}]}`, i)
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/prompts/:id/versions")
+			c.SetParamNames("id")
+			c.SetParamValues("test-prompt")
+			if err := h.CreateVersion(c); err != nil {
+				errs <- err
+				return
+			}
+
+			var version testVersion
+			if err := json.Unmarshal(rec.Body.Bytes(), &version); err != nil {
+				errs <- err
+				return
+			}
+			versions <- version.Version
+		}(i)
+	}
+	wg.Wait()
+	close(versions)
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	// Every writer gets its own number and the numbers have no gaps
+	var got []int64
+	for v := range versions {
+		got = append(got, v)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	require.Len(t, got, writers)
+	for i, v := range got {
+		assert.Equal(t, int64(i+1), v)
+	}
+}
+
+func TestRestoreVersion(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	// Create a test prompt with two versions
+	_, err := store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{ID: "test-prompt", Title: "Test Prompt"})
+	require.NoError(t, err)
+	for i := 1; i <= 2; i++ {
+		_, err = store.CreateVersion(context.Background(), sqlc.CreateVersionParams{
+			ID:       fmt.Sprintf("test-version-%d", i),
+			PromptID: sql.NullString{String: "test-prompt", Valid: true},
+			Version:  int64(i),
+			Content:  fmt.Sprintf(`[{"role":"user","content":"v%d"}]`, i),
+			Inputs:   "[]",
+		})
+		require.NoError(t, err)
+	}
+
+	restore := func(version, body string) (*httptest.ResponseRecorder, error) {
+		req := withUser(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)), "alice")
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/prompts/:id/versions/:version/restore")
+		c.SetParamNames("id", "version")
+		c.SetParamValues("test-prompt", version)
+		return rec, h.RestoreVersion(c)
+	}
+
+	// Test POST /prompts/:id/versions/:version/restore
+	rec, err := restore("1", `{"created_by":{"id":"alice"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var restored testVersion
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &restored))
+	assert.Equal(t, int64(3), restored.Version)
+	assert.Equal(t, `[{"role":"user","content":"v1"}]`, restored.Content)
+	assert.Equal(t, sql.NullInt64{Int64: 1, Valid: true}, restored.RestoredFrom)
+	assert.Equal(t, "alice", restored.CreatedBy.ID)
+	assert.Equal(t, "alice@example.com", restored.CreatedBy.Email)
+
+	// Test restoring a missing version
+	_, err = restore("9", "")
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+	_, err = restore("latest", "")
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
 }
 
 func TestGetComments(t *testing.T) {
@@ -231,12 +447,14 @@ func TestGetComments(t *testing.T) {
 	require.NoError(t, h.GetComments(c))
 	assert.Equal(t, http.StatusOK, rec.Code)
 
-	var response []testComment
+	var response struct {
+		Items []testComment `json:"items"`
+	}
 	err = json.Unmarshal(rec.Body.Bytes(), &response)
 	require.NoError(t, err)
-	assert.Len(t, response, 1)
-	assert.Equal(t, comment.ID, response[0].ID)
-	assert.Equal(t, comment.Content, response[0].Content)
+	assert.Len(t, response.Items, 1)
+	assert.Equal(t, comment.ID, response.Items[0].ID)
+	assert.Equal(t, comment.Content, response.Items[0].Content)
 }
 
 func TestGetEvaluations(t *testing.T) {
@@ -294,11 +512,59 @@ func TestGetEvaluations(t *testing.T) {
 	require.NoError(t, h.GetEvaluations(c))
 	assert.Equal(t, http.StatusOK, rec.Code)
 
-	var response []testEval
+	var response struct {
+		Items []testEval `json:"items"`
+	}
 	err = json.Unmarshal(rec.Body.Bytes(), &response)
 	require.NoError(t, err)
-	assert.Len(t, response, 1)
-	assert.Equal(t, eval.ID, response[0].ID)
-	assert.Equal(t, eval.Score.Float64, response[0].Score.Float64)
-	assert.Equal(t, eval.Notes.String, response[0].Notes.String)
+	assert.Len(t, response.Items, 1)
+	assert.Equal(t, eval.ID, response.Items[0].ID)
+	assert.Equal(t, eval.Score.Float64, response.Items[0].Score.Float64)
+	assert.Equal(t, eval.Notes.String, response.Items[0].Notes.String)
+}
+
+func TestRunPrompt(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Stand in for an OpenAI-compatible API
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"gpt-4o","choices":[{"message":{"role":"assistant","content":"Hi there"}}],
+			"usage":{"prompt_tokens":7,"completion_tokens":2,"total_tokens":9}}`))
+	}))
+	defer upstream.Close()
+
+	e := echo.New()
+	h := NewHandler(store)
+	h.Providers = llm.NewRegistry("")
+	h.Providers.Register(llm.NewOpenAIProvider(upstream.URL, "test-key"))
+
+	// Test POST /run
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"Hello"}],"temperature":0.5}`
+	req := httptest.NewRequest(http.MethodPost, "/run", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Assertions
+	require.NoError(t, h.RunPrompt(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response models.RunPromptResponse
+	err := json.Unmarshal(rec.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi there", response.Response)
+	assert.Equal(t, "gpt-4o", response.Model)
+	assert.Equal(t, 9, response.Usage.TotalTokens)
+
+	// Unknown providers are rejected
+	req = httptest.NewRequest(http.MethodPost, "/run", strings.NewReader(`{"model":"mystery","messages":[{"role":"user","content":"Hello"}]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c = e.NewContext(req, httptest.NewRecorder())
+
+	err = h.RunPrompt(c)
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusBadRequest, httpErr.Code)
 }
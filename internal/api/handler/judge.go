@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/eval"
+	"github.com/epuerta9/prompts.kitchenai/internal/render"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// judgeConfig is the spec of an llm_judge scorer
+type judgeConfig struct {
+	// Prompt is the ID or "[namespace:]slug" of the rubric prompt. Its
+	// version is pinned when the scorer is built: Version if set, else the
+	// version Label points at, else the latest version.
+	Prompt  string `json:"prompt"`
+	Version int64  `json:"version"`
+	Label   string `json:"label"`
+
+	Model       string   `json:"model"`
+	Temperature *float64 `json:"temperature"`
+	MaxTokens   int      `json:"max_tokens"`
+
+	// The judge's score is read on the MinScore..MaxScore scale and
+	// passes at PassThreshold on the normalized 0..1 scale
+	MinScore      *float64 `json:"min_score"`
+	MaxScore      *float64 `json:"max_score"`
+	PassThreshold *float64 `json:"pass_threshold"`
+}
+
+// judgeScorer grades outputs by running a rubric prompt version on a
+// judge model. The rubric can use {{input}}, {{output}}, {{reference}},
+// {{variables}} (all case variables as JSON) and {{variables.<name>}}.
+type judgeScorer struct {
+	h       *Handler
+	version sqlc.PromptVersion
+	uses    []string
+	config  judgeConfig
+
+	minScore, maxScore, passThreshold float64
+}
+
+// newJudgeScorer builds an llm_judge scorer
+func (h *Handler) newJudgeScorer(ctx context.Context, config json.RawMessage) (eval.Scorer, error) {
+	var cfg judgeConfig
+	if err := json.Unmarshal(config, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Prompt == "" || cfg.Model == "" {
+		return nil, errors.New("prompt and model are required")
+	}
+	if _, _, err := h.Providers.Resolve(cfg.Model); err != nil {
+		return nil, err
+	}
+
+	s := &judgeScorer{h: h, config: cfg, minScore: 0, maxScore: 10, passThreshold: 0.5}
+	if cfg.MinScore != nil {
+		s.minScore = *cfg.MinScore
+	}
+	if cfg.MaxScore != nil {
+		s.maxScore = *cfg.MaxScore
+	}
+	if cfg.PassThreshold != nil {
+		s.passThreshold = *cfg.PassThreshold
+	}
+	if s.minScore >= s.maxScore {
+		return nil, errors.New("min_score must be below max_score")
+	}
+
+	version, err := h.judgeRubric(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.version = version
+
+	msgs, err := fromDBMessages(version.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode rubric messages: %w", err)
+	}
+	s.uses = render.Variables(msgs)
+	for _, name := range s.uses {
+		switch {
+		case name == "input", name == "output", name == "reference", name == "variables":
+		case strings.HasPrefix(name, "variables."):
+		default:
+			return nil, fmt.Errorf("rubric uses unsupported variable %q", name)
+		}
+	}
+	return s, nil
+}
+
+// judgeRubric finds the rubric prompt version a judge spec refers to
+func (h *Handler) judgeRubric(ctx context.Context, cfg judgeConfig) (sqlc.PromptVersion, error) {
+	prompt, _, err := h.findPrompt(ctx, cfg.Prompt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sqlc.PromptVersion{}, fmt.Errorf("rubric prompt %q not found", cfg.Prompt)
+		}
+		return sqlc.PromptVersion{}, err
+	}
+
+	number := cfg.Version
+	if number == 0 && cfg.Label != "" {
+		label, err := h.Store.GetLabel(ctx, sqlc.GetLabelParams{PromptID: prompt.ID, Name: cfg.Label})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return sqlc.PromptVersion{}, fmt.Errorf("rubric label %q not found", cfg.Label)
+			}
+			return sqlc.PromptVersion{}, err
+		}
+		number = label.Version
+	}
+
+	version, err := h.findVersion(ctx, prompt.ID, number)
+	if err != nil {
+		return sqlc.PromptVersion{}, fmt.Errorf("rubric %s: %s", cfg.Prompt, errorMessage(err))
+	}
+	return version, nil
+}
+
+func (s *judgeScorer) Name() string { return "llm_judge" }
+
+// Score renders the rubric with the case, asks the judge model for a
+// verdict and records the exchange as a run of the rubric version
+func (s *judgeScorer) Score(ctx context.Context, c eval.Case) (eval.Result, error) {
+	vars := make(map[string]interface{}, len(s.uses))
+	for _, name := range s.uses {
+		switch {
+		case name == "input":
+			vars[name] = c.Input
+		case name == "output":
+			vars[name] = c.Output
+		case name == "reference":
+			if c.Expected == nil {
+				return eval.Result{}, eval.ErrNotApplicable
+			}
+			vars[name] = *c.Expected
+		case name == "variables":
+			vars[name] = c.Variables
+		default:
+			value, ok := c.Variables[strings.TrimPrefix(name, "variables.")]
+			if !ok {
+				return eval.Result{}, eval.ErrNotApplicable
+			}
+			vars[name] = value
+		}
+	}
+
+	req := models.RunPromptRequest{
+		PromptID:    s.version.PromptID.String,
+		Version:     int(s.version.Version),
+		Variables:   vars,
+		Model:       s.config.Model,
+		Temperature: s.config.Temperature,
+		MaxTokens:   s.config.MaxTokens,
+	}
+	prepared, err := s.h.prepareRun(ctx, req)
+	if err != nil {
+		return eval.Result{}, errors.New(errorMessage(err))
+	}
+
+	start := time.Now()
+	response, runErr := s.h.complete(ctx, prepared)
+	runID, err := s.h.storeRun(ctx, req, prepared, response, runErr, time.Since(start))
+	if err != nil {
+		return eval.Result{}, fmt.Errorf("failed to record judge run: %w", err)
+	}
+	if runErr != nil {
+		return eval.Result{}, fmt.Errorf("judge model failed: %w", runErr)
+	}
+
+	raw, rationale, err := eval.ParseJudgeReply(response.Content)
+	if err != nil {
+		return eval.Result{}, err
+	}
+
+	score := math.Min(1, math.Max(0, (raw-s.minScore)/(s.maxScore-s.minScore)))
+	return eval.Result{
+		Passed:         score >= s.passThreshold,
+		Score:          score,
+		Reason:         fmt.Sprintf("%g/%g: %s", raw, s.maxScore, rationale),
+		JudgeVersionID: s.version.ID,
+		JudgeRunID:     runID,
+	}, nil
+}
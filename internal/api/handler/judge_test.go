@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/llm"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// judgeProvider grades an answer by whether it names Paris
+type judgeProvider struct{}
+
+func (judgeProvider) Name() string { return "judge" }
+
+func (judgeProvider) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	if strings.Contains(req.Messages[len(req.Messages)-1].Content, "Answer: Paris") {
+		return &llm.Response{Content: "Score: 9\nRationale: Names the capital correctly."}, nil
+	}
+	return &llm.Response{Content: `{"score": 2, "rationale": "Wrong city."}`}, nil
+}
+
+func TestJudgeScorer(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+	h.Providers = llm.NewRegistry("")
+	h.Providers.Register(judgeProvider{})
+
+	for _, id := range []string{"target", "rubric"} {
+		_, err := store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{ID: id, Title: id})
+		require.NoError(t, err)
+	}
+	_, err := store.CreateVersion(context.Background(), sqlc.CreateVersionParams{
+		ID:       "target-v1",
+		PromptID: sql.NullString{String: "target", Valid: true},
+		Version:  1,
+		Content:  `[{"role":"user","content":"What is the capital of France?"}]`,
+	})
+	require.NoError(t, err)
+	_, err = store.CreateVersion(context.Background(), sqlc.CreateVersionParams{
+		ID:       "rubric-v1",
+		PromptID: sql.NullString{String: "rubric", Valid: true},
+		Version:  1,
+		Content: `[{"role":"system","content":"Grade the answer from 0 to 10."},` +
+			`{"role":"user","content":"Question: {{input}}\nReference: {{reference}}\nAnswer: {{output}}"}]`,
+	})
+	require.NoError(t, err)
+
+	score := func(body string) ([]testEvaluation, error) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id", "version")
+		c.SetParamValues("target", "1")
+		if err := h.ScoreOutput(c); err != nil {
+			return nil, err
+		}
+		var page struct {
+			Items []testEvaluation `json:"items"`
+		}
+		return page.Items, json.Unmarshal(rec.Body.Bytes(), &page)
+	}
+	judge := `{"type":"llm_judge","prompt":"rubric","model":"judge/grader"}`
+
+	// The judge's verdict is normalized and linked to the rubric version
+	evals, err := score(`{"input":"What is the capital of France?","output":"Paris","expected":"Paris","scorers":[` + judge + `]}`)
+	require.NoError(t, err)
+	require.Len(t, evals, 1)
+	assert.Equal(t, "llm_judge", evals[0].Scorer)
+	assert.Equal(t, 0.9, evals[0].Score.Float64)
+	assert.True(t, evals[0].Passed.Bool)
+	assert.Equal(t, "9/10: Names the capital correctly.", evals[0].Notes.String)
+	assert.Equal(t, "rubric-v1", evals[0].JudgePromptVersionID.String)
+
+	// The judge call is recorded as a run of the rubric
+	run, err := store.GetRun(context.Background(), evals[0].JudgeRunID.String)
+	require.NoError(t, err)
+	assert.Equal(t, "rubric-v1", run.PromptVersionID.String)
+	assert.Contains(t, run.Messages, "Reference: Paris")
+
+	evals, err = score(`{"output":"Lyon","expected":"Paris","scorers":[` + judge + `]}`)
+	require.NoError(t, err)
+	require.Len(t, evals, 1)
+	assert.Equal(t, 0.2, evals[0].Score.Float64)
+	assert.False(t, evals[0].Passed.Bool)
+
+	// Purging the rubric unlinks the evaluations graded with it
+	require.NoError(t, store.PurgePrompt(context.Background(), "rubric", sql.NullInt64{}))
+	stored, err := store.ListEvaluations(context.Background(), sql.NullString{String: "target-v1", Valid: true})
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.False(t, stored[0].JudgePromptVersionID.Valid)
+	assert.False(t, stored[0].JudgeRunID.Valid)
+	_, err = store.CreateVersion(context.Background(), sqlc.CreateVersionParams{
+		ID:       "target-v2",
+		PromptID: sql.NullString{String: "target", Valid: true},
+		Version:  2,
+		Content:  `[{"role":"user","content":"{{reference}} {{output}}"}]`,
+	})
+	require.NoError(t, err)
+	judge = `{"type":"llm_judge","prompt":"target","version":2,"model":"judge/grader"}`
+
+	// Rubrics using the reference skip cases without one
+	evals, err = score(`{"output":"Paris","scorers":[` + judge + `]}`)
+	require.NoError(t, err)
+	assert.Empty(t, evals)
+
+	// Judge specs are checked before anything is scored
+	for _, spec := range []string{
+		`{"type":"llm_judge","prompt":"missing","model":"judge/grader"}`,
+		`{"type":"llm_judge","prompt":"rubric","model":"judge/grader"}`,
+		`{"type":"llm_judge","prompt":"target","model":"nope/model"}`,
+		`{"type":"llm_judge","prompt":"target","label":"production","model":"judge/grader"}`,
+		`{"type":"llm_judge","prompt":"target","version":3,"model":"judge/grader"}`,
+		`{"type":"llm_judge","prompt":"target","model":"judge/grader","min_score":5,"max_score":5}`,
+	} {
+		_, err = score(`{"output":"Paris","scorers":[` + spec + `]}`)
+		assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code, spec)
+	}
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"regexp"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// labelName matches valid label names such as "production" or "canary-eu"
+var labelName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// GetLabels returns the deployment labels of a prompt
+func (h *Handler) GetLabels(c echo.Context) error {
+	promptID := c.Param("id")
+
+	// Check if prompt exists
+	_, err := h.Store.GetPrompt(c.Request().Context(), promptID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompt: "+err.Error())
+	}
+
+	// Get labels from database
+	labels, err := h.Store.ListLabels(c.Request().Context(), promptID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch labels: "+err.Error())
+	}
+
+	payloads, err := h.newAuthors().labels(c.Request().Context(), labels)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.ListResponse{Items: payloads})
+}
+
+// resolvedLabel is the version a label points at together with the label
+type resolvedLabel struct {
+	versionPayload
+	Label authoredLabel `json:"label"`
+}
+
+// ResolveLabel returns the version a label currently points at
+func (h *Handler) ResolveLabel(c echo.Context) error {
+	promptID := c.Param("id")
+	name := c.Param("label")
+
+	label, err := h.Store.GetLabel(c.Request().Context(), sqlc.GetLabelParams{PromptID: promptID, Name: name})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "Label not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch label: "+err.Error())
+	}
+
+	version, err := h.findVersion(c.Request().Context(), promptID, label.Version)
+	if err != nil {
+		return err
+	}
+
+	a := h.newAuthors()
+	payload := resolvedLabel{}
+	if payload.versionPayload, err = a.version(c.Request().Context(), version); err != nil {
+		return err
+	}
+	if payload.Label, err = a.label(c.Request().Context(), label); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, payload)
+}
+
+// MoveLabel points a label at a version, creating the label if needed
+func (h *Handler) MoveLabel(c echo.Context) error {
+	promptID := c.Param("id")
+	name := c.Param("label")
+
+	var req models.LabelRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	// Validate required fields
+	if !labelName.MatchString(name) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Label names must be lowercase letters, digits, - or _")
+	}
+	if req.Version <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Version is required")
+	}
+	mover, err := h.author(c, req.MovedBy)
+	if err != nil {
+		return err
+	}
+
+	// Check the version exists
+	if _, err := h.findVersion(c.Request().Context(), promptID, int64(req.Version)); err != nil {
+		return err
+	}
+
+	label, err := h.Store.MoveLabel(c.Request().Context(), db.MoveLabelParams{
+		PromptID: promptID,
+		Name:     name,
+		Version:  int64(req.Version),
+		MovedBy:  sql.NullString{String: mover.ID, Valid: mover.ID != ""},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to move label: "+err.Error())
+	}
+
+	payload, err := h.newAuthors().label(c.Request().Context(), label)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, payload)
+}
+
+// RollbackLabel moves a label back to the version it pointed at before
+// its most recent move
+func (h *Handler) RollbackLabel(c echo.Context) error {
+	promptID := c.Param("id")
+	name := c.Param("label")
+
+	var req models.LabelRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	mover, err := h.author(c, req.MovedBy)
+	if err != nil {
+		return err
+	}
+
+	label, err := h.Store.RollbackLabel(c.Request().Context(), promptID, name,
+		sql.NullString{String: mover.ID, Valid: mover.ID != ""})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return echo.NewHTTPError(http.StatusNotFound, "Label not found")
+		case errors.Is(err, db.ErrNothingToRollback):
+			return echo.NewHTTPError(http.StatusConflict, "Label has no previous version")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to roll back label: "+err.Error())
+	}
+
+	payload, err := h.newAuthors().label(c.Request().Context(), label)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, payload)
+}
+
+// GetLabelHistory returns every move of a label, most recent first
+func (h *Handler) GetLabelHistory(c echo.Context) error {
+	promptID := c.Param("id")
+	name := c.Param("label")
+
+	history, err := h.Store.ListLabelHistory(c.Request().Context(), sqlc.ListLabelHistoryParams{PromptID: promptID, Name: name})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch label history: "+err.Error())
+	}
+	if len(history) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "Label not found")
+	}
+
+	payloads, err := h.newAuthors().labelEvents(c.Request().Context(), history)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.ListResponse{Items: payloads})
+}
+
+// DeleteLabel removes a label. Its history is kept and records the delete,
+// so rolling the label back restores it.
+func (h *Handler) DeleteLabel(c echo.Context) error {
+	promptID := c.Param("id")
+	name := c.Param("label")
+
+	deleter, err := h.author(c, models.User{})
+	if err != nil {
+		return err
+	}
+
+	err = h.Store.DeleteLabel(c.Request().Context(), promptID, name,
+		sql.NullString{String: deleter.ID, Valid: deleter.ID != ""})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, "Label not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete label: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted", "label": name})
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLabel struct {
+	PromptID  string       `json:"prompt_id"`
+	Name      string       `json:"name"`
+	Version   int64        `json:"version"`
+	UpdatedBy *models.User `json:"updated_by"`
+}
+
+type testLabelEvent struct {
+	Version         sql.NullInt64 `json:"version"`
+	PreviousVersion sql.NullInt64 `json:"previous_version"`
+	MovedBy         *models.User  `json:"moved_by"`
+}
+
+func TestLabels(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	// Create a test prompt with two versions
+	_, err := store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{ID: "test-prompt", Title: "Test Prompt"})
+	require.NoError(t, err)
+	for i := 1; i <= 2; i++ {
+		_, err = store.CreateVersion(context.Background(), sqlc.CreateVersionParams{
+			ID:       fmt.Sprintf("test-version-%d", i),
+			PromptID: sql.NullString{String: "test-prompt", Valid: true},
+			Version:  int64(i),
+			Content:  fmt.Sprintf(`[{"role":"user","content":"v%d"}]`, i),
+		})
+		require.NoError(t, err)
+	}
+
+	var caller string
+	call := func(method string, fn echo.HandlerFunc, label, body string) (*httptest.ResponseRecorder, error) {
+		req := withUser(httptest.NewRequest(method, "/", strings.NewReader(body)), caller)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/prompts/:id/labels/:label")
+		c.SetParamNames("id", "label")
+		c.SetParamValues("test-prompt", label)
+		return rec, fn(c)
+	}
+
+	// Test PUT /prompts/:id/labels/:label
+	caller = "alice"
+	_, err = call(http.MethodPut, h.MoveLabel, "production", `{"version":1,"moved_by":{"id":"alice"}}`)
+	require.NoError(t, err)
+	caller = "bob"
+	rec, err := call(http.MethodPut, h.MoveLabel, "production", `{"version":2}`)
+	require.NoError(t, err)
+
+	var label testLabel
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &label))
+	assert.Equal(t, int64(2), label.Version)
+	require.NotNil(t, label.UpdatedBy)
+	assert.Equal(t, "bob", label.UpdatedBy.ID)
+
+	// Unknown versions and bad names are rejected
+	_, err = call(http.MethodPut, h.MoveLabel, "production", `{"version":3}`)
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+	_, err = call(http.MethodPut, h.MoveLabel, "Prod Env", `{"version":1}`)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+
+	// Test GET /prompts/:id/labels/:label
+	rec, err = call(http.MethodGet, h.ResolveLabel, "production", "")
+	require.NoError(t, err)
+	var resolved struct {
+		testVersion
+		Label testLabel `json:"label"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resolved))
+	assert.Equal(t, "test-version-2", resolved.ID)
+	assert.Equal(t, "production", resolved.Label.Name)
+	require.NotNil(t, resolved.Label.UpdatedBy)
+	assert.Equal(t, "Bob", resolved.Label.UpdatedBy.Name)
+
+	// Test POST /prompts/:id/labels/:label/rollback
+	caller = "carol"
+	rec, err = call(http.MethodPost, h.RollbackLabel, "production", `{}`)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &label))
+	assert.Equal(t, int64(1), label.Version)
+
+	// Test GET /prompts/:id/labels/:label/history
+	rec, err = call(http.MethodGet, h.GetLabelHistory, "production", "")
+	require.NoError(t, err)
+	var history struct {
+		Items []testLabelEvent `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &history))
+	require.Len(t, history.Items, 3)
+	assert.Equal(t, sql.NullInt64{Int64: 1, Valid: true}, history.Items[0].Version)
+	assert.Equal(t, sql.NullInt64{Int64: 2, Valid: true}, history.Items[0].PreviousVersion)
+	require.NotNil(t, history.Items[0].MovedBy)
+	assert.Equal(t, "Carol", history.Items[0].MovedBy.Name)
+	assert.False(t, history.Items[2].PreviousVersion.Valid)
+
+	// A label that was only ever set once has nothing to roll back to
+	_, err = call(http.MethodPut, h.MoveLabel, "staging", `{"version":2}`)
+	require.NoError(t, err)
+	_, err = call(http.MethodPost, h.RollbackLabel, "staging", "")
+	assert.Equal(t, http.StatusConflict, err.(*echo.HTTPError).Code)
+
+	// Test DELETE /prompts/:id/labels/:label records the delete, and
+	// rolling back restores the label
+	caller = "dave"
+	_, err = call(http.MethodDelete, h.DeleteLabel, "staging", "")
+	require.NoError(t, err)
+	_, err = call(http.MethodDelete, h.DeleteLabel, "staging", "")
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+	_, err = call(http.MethodGet, h.ResolveLabel, "staging", "")
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+
+	rec, err = call(http.MethodGet, h.GetLabelHistory, "staging", "")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &history))
+	require.Len(t, history.Items, 2)
+	assert.False(t, history.Items[0].Version.Valid)
+	assert.Equal(t, sql.NullInt64{Int64: 2, Valid: true}, history.Items[0].PreviousVersion)
+	require.NotNil(t, history.Items[0].MovedBy)
+	assert.Equal(t, "dave", history.Items[0].MovedBy.ID)
+
+	rec, err = call(http.MethodPost, h.RollbackLabel, "staging", "")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &label))
+	assert.Equal(t, int64(2), label.Version)
+
+	// Test GET /prompts/:id/labels
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/prompts/:id/labels")
+	c.SetParamNames("id")
+	c.SetParamValues("test-prompt")
+	require.NoError(t, h.GetLabels(c))
+
+	var labels struct {
+		Items []testLabel `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &labels))
+	require.Len(t, labels.Items, 2)
+	assert.Equal(t, "production", labels.Items[0].Name)
+	assert.Equal(t, "staging", labels.Items[1].Name)
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// pageCursor is the decoded form of the opaque cursor handed to clients.
+// It remembers the sort so a cursor cannot be replayed against another.
+type pageCursor struct {
+	Sort string `json:"s"`
+	Desc bool   `json:"d,omitempty"`
+	Key  string `json:"k"`
+	ID   string `json:"i"`
+}
+
+// encodeCursor returns the opaque cursor for the page after next, or ""
+// when there are no more pages
+func encodeCursor(page db.PageParams, next *db.Cursor) string {
+	if next == nil {
+		return ""
+	}
+	data, _ := json.Marshal(pageCursor{Sort: page.Sort, Desc: page.Desc, Key: next.Key, ID: next.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// listResponse wraps one page of items with the cursor of the next page
+func listResponse(items interface{}, page db.PageParams, next *db.Cursor) models.ListResponse {
+	return models.ListResponse{Items: items, NextCursor: encodeCursor(page, next)}
+}
+
+// parseListParams reads the pagination, sorting and filtering query
+// parameters shared by the list endpoints:
+//
+//	?limit=, ?cursor=, ?sort=<one of sorts>, ?order=asc|desc,
+//	?created_by=, ?from=, ?to=, ?updated_from=, ?updated_to= (RFC 3339)
+//	?title_prefix=, ?collection= and ?tag=
+//
+// The first of sorts is the default, in descending order.
+func parseListParams(c echo.Context, sorts ...string) (db.ListFilter, db.PageParams, error) {
+	var filter db.ListFilter
+	page := db.PageParams{Sort: sorts[0], Desc: true, Limit: defaultPageLimit}
+
+	if sort := c.QueryParam("sort"); sort != "" {
+		valid := false
+		for _, s := range sorts {
+			valid = valid || s == sort
+		}
+		if !valid {
+			return filter, page, echo.NewHTTPError(http.StatusBadRequest, "Invalid sort")
+		}
+		page.Sort = sort
+	}
+
+	switch c.QueryParam("order") {
+	case "", "desc":
+	case "asc":
+		page.Desc = false
+	default:
+		return filter, page, echo.NewHTTPError(http.StatusBadRequest, "Invalid order, expected asc or desc")
+	}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || n <= 0 {
+			return filter, page, echo.NewHTTPError(http.StatusBadRequest, "Invalid limit")
+		}
+		if n > maxPageLimit {
+			n = maxPageLimit
+		}
+		page.Limit = n
+	}
+
+	if raw := c.QueryParam("cursor"); raw != "" {
+		var cursor pageCursor
+		data, err := base64.RawURLEncoding.DecodeString(raw)
+		if err == nil {
+			err = json.Unmarshal(data, &cursor)
+		}
+		if err != nil {
+			return filter, page, echo.NewHTTPError(http.StatusBadRequest, "Invalid cursor")
+		}
+		if cursor.Sort != page.Sort || cursor.Desc != page.Desc {
+			return filter, page, echo.NewHTTPError(http.StatusBadRequest, "Cursor does not match the requested sort")
+		}
+		page.After = &db.Cursor{Key: cursor.Key, ID: cursor.ID}
+	}
+
+	if createdBy := c.QueryParam("created_by"); createdBy != "" {
+		filter.CreatedBy = sql.NullString{String: createdBy, Valid: true}
+	}
+	filter.TitlePrefix = c.QueryParam("title_prefix")
+	if collection := c.QueryParam("collection"); collection != "" {
+		filter.CollectionID = sql.NullString{String: collection, Valid: true}
+	}
+	if tag := c.QueryParam("tag"); tag != "" {
+		filter.Tag = sql.NullString{String: strings.ToLower(tag), Valid: true}
+	}
+
+	for param, dst := range map[string]*sql.NullTime{
+		"from":         &filter.CreatedAfter,
+		"to":           &filter.CreatedBefore,
+		"updated_from": &filter.UpdatedAfter,
+		"updated_to":   &filter.UpdatedBefore,
+	} {
+		value := c.QueryParam(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, page, echo.NewHTTPError(http.StatusBadRequest, "Invalid "+param+" time, expected RFC 3339")
+		}
+		*dst = sql.NullTime{Time: t.UTC(), Valid: true}
+	}
+
+	return filter, page, nil
+}
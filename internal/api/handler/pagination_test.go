@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPage struct {
+	Items []struct {
+		ID      string `json:"id"`
+		Title   string `json:"title"`
+		Version int64  `json:"version"`
+	} `json:"items"`
+	NextCursor string `json:"next_cursor"`
+}
+
+func TestPagination(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	// Create prompts with distinct titles and authors, and versions for one
+	ctx := context.Background()
+	for i, title := range []string{"beta", "alpha", "delta", "gamma", "al_pha"} {
+		author := "alice"
+		if i%2 == 1 {
+			author = "bob"
+		}
+		_, err := store.CreatePrompt(ctx, sqlc.CreatePromptParams{
+			ID:        fmt.Sprintf("prompt-%d", i),
+			Title:     title,
+			CreatedBy: sql.NullString{String: author, Valid: true},
+		})
+		require.NoError(t, err)
+	}
+	for i := 1; i <= 5; i++ {
+		_, err := store.CreateVersion(ctx, sqlc.CreateVersionParams{
+			ID:       fmt.Sprintf("version-%d", i),
+			PromptID: sql.NullString{String: "prompt-0", Valid: true},
+			Version:  int64(i),
+			Content:  `[{"role":"user","content":"hi"}]`,
+		})
+		require.NoError(t, err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		_, err := store.CreateComment(ctx, sqlc.CreateCommentParams{
+			ID:       fmt.Sprintf("comment-%d", i),
+			PromptID: sql.NullString{String: "prompt-0", Valid: true},
+			Content:  "note",
+		})
+		require.NoError(t, err)
+	}
+
+	list := func(fn echo.HandlerFunc, query url.Values) (testPage, error) {
+		req := httptest.NewRequest(http.MethodGet, "/?"+query.Encode(), nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("prompt-0")
+		var page testPage
+		if err := fn(c); err != nil {
+			return page, err
+		}
+		return page, json.Unmarshal(rec.Body.Bytes(), &page)
+	}
+
+	// walk follows the cursors of GET /prompts and returns every title
+	walk := func(query url.Values) []string {
+		var titles []string
+		for pages := 0; ; pages++ {
+			require.Less(t, pages, 5)
+			page, err := list(h.GetPrompts, query)
+			require.NoError(t, err)
+			for _, item := range page.Items {
+				titles = append(titles, item.Title)
+			}
+			if page.NextCursor == "" {
+				return titles
+			}
+			query.Set("cursor", page.NextCursor)
+		}
+	}
+
+	// Test GET /prompts walks every page in each sort order. The prompts
+	// share timestamps or were created in ID order, so ties fall back to ID.
+	inserted := []string{"beta", "alpha", "delta", "gamma", "al_pha"}
+	reversed := []string{"al_pha", "gamma", "delta", "alpha", "beta"}
+	for _, tt := range []struct {
+		sort, order string
+		want        []string
+	}{
+		{"title", "asc", []string{"al_pha", "alpha", "beta", "delta", "gamma"}},
+		{"title", "desc", []string{"gamma", "delta", "beta", "alpha", "al_pha"}},
+		{"created_at", "asc", inserted},
+		{"created_at", "desc", reversed},
+		{"updated_at", "asc", inserted},
+		{"updated_at", "desc", reversed},
+	} {
+		query := url.Values{"sort": {tt.sort}, "order": {tt.order}, "limit": {"2"}}
+		assert.Equal(t, tt.want, walk(query), tt.sort+" "+tt.order)
+	}
+	query := url.Values{"sort": {"title"}, "order": {"asc"}, "limit": {"2"}}
+	page, err := list(h.GetPrompts, query)
+	require.NoError(t, err)
+	query.Set("cursor", page.NextCursor)
+
+	// A cursor is tied to the sort it was issued for
+	query.Set("order", "desc")
+	_, err = list(h.GetPrompts, query)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	_, err = list(h.GetPrompts, url.Values{"cursor": {"not-a-cursor"}})
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	_, err = list(h.GetPrompts, url.Values{"sort": {"score"}})
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+
+	// Filters combine; _ in a prefix is matched literally
+	page, err = list(h.GetPrompts, url.Values{"title_prefix": {"al_"}})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "al_pha", page.Items[0].Title)
+
+	page, err = list(h.GetPrompts, url.Values{"created_by": {"bob"}, "sort": {"title"}})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "gamma", page.Items[0].Title)
+	assert.Equal(t, "alpha", page.Items[1].Title)
+
+	page, err = list(h.GetPrompts, url.Values{"from": {"2000-01-01T00:00:00Z"}, "to": {"2001-01-01T00:00:00Z"}})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+
+	// Test GET /prompts/:id/versions pages newest first by default
+	page, err = list(h.GetVersions, url.Values{"limit": {"3"}})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 3)
+	assert.Equal(t, int64(5), page.Items[0].Version)
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = list(h.GetVersions, url.Values{"limit": {"3"}, "cursor": {page.NextCursor}})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, int64(2), page.Items[0].Version)
+	assert.Empty(t, page.NextCursor)
+
+	// Test GET /prompts/:id/comments pages through rows sharing a timestamp
+	var ids []string
+	query = url.Values{"limit": {"1"}}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 4)
+		page, err := list(h.GetComments, query)
+		require.NoError(t, err)
+		for _, item := range page.Items {
+			ids = append(ids, item.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Set("cursor", page.NextCursor)
+	}
+	assert.Equal(t, []string{"comment-3", "comment-2", "comment-1"}, ids)
+}
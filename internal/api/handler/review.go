@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/auth"
+	"github.com/epuerta9/prompts.kitchenai/internal/eval"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// Pairwise judgment outcomes
+const (
+	winnerLeft  = "left"
+	winnerRight = "right"
+	winnerTie   = "tie"
+)
+
+// GetNextReview returns a pair of outputs from two versions of a prompt
+// for the same dataset row, without saying which version produced which.
+// ?prompt= limits the queue to one prompt. Pairs the caller has already
+// judged are skipped; ?reviewer= may only name the caller. Returns 204
+// when there is nothing to review.
+func (h *Handler) GetNextReview(c echo.Context) error {
+	reviewer := c.QueryParam("reviewer")
+	user, ok := auth.UserFromContext(c.Request().Context())
+	if reviewer != "" && (!ok || reviewer != user.ID) {
+		return echo.NewHTTPError(http.StatusForbidden, "Reviews can only be queued for the authenticated user")
+	}
+	if ok {
+		reviewer = user.ID
+	}
+	params := sqlc.GetReviewPairParams{
+		CreatedBy: sql.NullString{String: reviewer, Valid: reviewer != ""},
+	}
+	if ref := c.QueryParam("prompt"); ref != "" {
+		prompt, _, err := h.findPrompt(c.Request().Context(), ref)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompt: "+err.Error())
+		}
+		params.PromptID = sql.NullString{String: prompt.ID, Valid: true}
+	}
+
+	pair, err := h.Store.GetReviewPair(c.Request().Context(), params)
+	if err == sql.ErrNoRows {
+		return c.NoContent(http.StatusNoContent)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch review pair: "+err.Error())
+	}
+
+	item := models.ReviewItem{
+		PromptID:     pair.PromptID,
+		DatasetRowID: pair.DatasetRowID,
+		Left:         models.ReviewOutput{ResultID: pair.FirstResultID, Output: pair.FirstOutput.String},
+		Right:        models.ReviewOutput{ResultID: pair.SecondResultID, Output: pair.SecondOutput.String},
+	}
+	if err := json.Unmarshal([]byte(pair.Variables), &item.Variables); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to decode row variables: "+err.Error())
+	}
+	if pair.Expected.Valid {
+		item.Expected = &pair.Expected.String
+	}
+
+	// The query puts the output it picked first, so shuffle the pair to
+	// keep it blind
+	if rand.Intn(2) == 1 {
+		item.Left, item.Right = item.Right, item.Left
+	}
+
+	return c.JSON(http.StatusOK, item)
+}
+
+// CreateJudgment records which of two outputs a reviewer preferred
+func (h *Handler) CreateJudgment(c echo.Context) error {
+	var req models.JudgmentRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	// Validate required fields
+	switch req.Winner {
+	case winnerLeft, winnerRight, winnerTie:
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "Winner must be left, right or tie")
+	}
+	if req.LeftResultID == req.RightResultID {
+		return echo.NewHTTPError(http.StatusBadRequest, "Left and right results must differ")
+	}
+
+	left, err := h.reviewResult(c, req.LeftResultID)
+	if err != nil {
+		return err
+	}
+	right, err := h.reviewResult(c, req.RightResultID)
+	if err != nil {
+		return err
+	}
+	if left.PromptID != right.PromptID || left.DatasetRowID != right.DatasetRowID {
+		return echo.NewHTTPError(http.StatusBadRequest, "Results must be outputs of the same prompt for the same dataset row")
+	}
+	if left.PromptVersionID == right.PromptVersionID {
+		return echo.NewHTTPError(http.StatusBadRequest, "Results must come from different versions")
+	}
+	author, err := h.author(c, req.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	judgment, err := h.Store.CreatePairwiseJudgment(c.Request().Context(), sqlc.CreatePairwiseJudgmentParams{
+		ID:             uuid.New().String(),
+		PromptID:       left.PromptID,
+		LeftResultID:   left.ID,
+		RightResultID:  right.ID,
+		LeftVersionID:  left.PromptVersionID,
+		RightVersionID: right.PromptVersionID,
+		Winner:         req.Winner,
+		Notes:          sql.NullString{String: req.Notes, Valid: req.Notes != ""},
+		CreatedBy:      sql.NullString{String: author.ID, Valid: author.ID != ""},
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create judgment: "+err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, judgment)
+}
+
+// reviewResult loads an eval run result that can be judged
+func (h *Handler) reviewResult(c echo.Context, id int64) (sqlc.GetReviewResultRow, error) {
+	result, err := h.Store.GetReviewResult(c.Request().Context(), id)
+	if err == sql.ErrNoRows {
+		return result, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Result %d does not exist", id))
+	}
+	if err != nil {
+		return result, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch result: "+err.Error())
+	}
+	if result.Error.Valid || !result.Output.Valid {
+		return result, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Result %d has no output", id))
+	}
+	return result, nil
+}
+
+// GetRatings returns the Bradley-Terry ratings of a prompt's versions from
+// its pairwise judgments, best first. Versions without judgments are left
+// out.
+func (h *Handler) GetRatings(c echo.Context) error {
+	promptID := c.Param("id")
+
+	// Check if prompt exists
+	_, err := h.Store.GetPrompt(c.Request().Context(), promptID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompt: "+err.Error())
+	}
+
+	counts, err := h.Store.ListPairwiseJudgmentCounts(c.Request().Context(), promptID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch judgments: "+err.Error())
+	}
+
+	tallies := make(map[int64]*models.VersionRating)
+	tally := func(version int64) *models.VersionRating {
+		if tallies[version] == nil {
+			tallies[version] = &models.VersionRating{Version: version}
+		}
+		return tallies[version]
+	}
+	matchups := make([]eval.Matchup, len(counts))
+	for i, count := range counts {
+		left, right, n := tally(count.LeftVersion), tally(count.RightVersion), int(count.Judgments)
+		left.Judgments += n
+		right.Judgments += n
+
+		matchups[i] = eval.Matchup{A: strconv.FormatInt(count.LeftVersion, 10), B: strconv.FormatInt(count.RightVersion, 10)}
+		switch count.Winner {
+		case winnerLeft:
+			matchups[i].WinsA = n
+			left.Wins += n
+			right.Losses += n
+		case winnerRight:
+			matchups[i].WinsB = n
+			right.Wins += n
+			left.Losses += n
+		default:
+			matchups[i].Ties = n
+			left.Ties += n
+			right.Ties += n
+		}
+	}
+
+	ratings := make([]models.VersionRating, 0, len(tallies))
+	for player, rating := range eval.BradleyTerry(matchups) {
+		version, _ := strconv.ParseInt(player, 10, 64)
+		tallies[version].Rating = rating
+		ratings = append(ratings, *tallies[version])
+	}
+	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].Rating != ratings[j].Rating {
+			return ratings[i].Rating > ratings[j].Rating
+		}
+		return ratings[i].Version < ratings[j].Version
+	})
+
+	return c.JSON(http.StatusOK, models.ListResponse{Items: ratings})
+}
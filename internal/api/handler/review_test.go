@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReview(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	_, err := store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{ID: "test-prompt", Title: "Test Prompt"})
+	require.NoError(t, err)
+	for i := 1; i <= 2; i++ {
+		_, err = store.CreateVersion(context.Background(), sqlc.CreateVersionParams{
+			ID:       fmt.Sprintf("test-version-%d", i),
+			PromptID: sql.NullString{String: "test-prompt", Valid: true},
+			Version:  int64(i),
+			Content:  `[{"role":"user","content":"{{question}}"}]`,
+		})
+		require.NoError(t, err)
+	}
+	_, _, err = store.CreateDataset(context.Background(), sqlc.CreateDatasetParams{ID: "questions", Name: "questions"},
+		[]sqlc.CreateDatasetRowParams{
+			{ID: "row-1", Variables: `{"question":"Capital of France?"}`},
+			{ID: "row-2", Variables: `{"question":"Capital of Peru?"}`},
+		})
+	require.NoError(t, err)
+
+	// Record eval run outputs; both versions answer row-2 identically
+	results := map[string]int64{}
+	record := func(run string, version int, outputs map[string]string) {
+		_, err := store.CreateEvalRun(context.Background(), sqlc.CreateEvalRunParams{
+			ID:              run,
+			PromptID:        "test-prompt",
+			PromptVersionID: fmt.Sprintf("test-version-%d", version),
+			DatasetID:       "questions",
+			Model:           "fake/echo",
+			Parameters:      "{}",
+			Scorers:         "[]",
+		})
+		require.NoError(t, err)
+		for row, output := range outputs {
+			result, err := store.RecordEvalRunResult(context.Background(), sqlc.CreateEvalRunResultParams{
+				EvalRunID:    run,
+				DatasetRowID: row,
+				Output:       sql.NullString{String: output, Valid: true},
+			}, nil)
+			require.NoError(t, err)
+			results[run+"/"+row] = result.ID
+		}
+	}
+	record("run-1", 1, map[string]string{"row-1": "Paris, I think", "row-2": "Lima"})
+	record("run-2", 2, map[string]string{"row-1": "Paris", "row-2": "Lima"})
+	record("run-3", 1, map[string]string{"row-1": "Paris?"})
+
+	next := func(query, reviewer string) (*httptest.ResponseRecorder, models.ReviewItem, error) {
+		req := withUser(httptest.NewRequest(http.MethodGet, "/api/review/next?"+query, nil), reviewer)
+		rec := httptest.NewRecorder()
+		var item models.ReviewItem
+		if err := h.GetNextReview(e.NewContext(req, rec)); err != nil {
+			return rec, item, err
+		}
+		if rec.Code == http.StatusOK {
+			return rec, item, json.Unmarshal(rec.Body.Bytes(), &item)
+		}
+		return rec, item, nil
+	}
+	judge := func(body string, reviewer string) (*httptest.ResponseRecorder, error) {
+		req := withUser(httptest.NewRequest(http.MethodPost, "/api/review/judgments", strings.NewReader(body)), reviewer)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		return rec, h.CreateJudgment(e.NewContext(req, rec))
+	}
+	prefer := func(item models.ReviewItem, output, reviewer string) {
+		winner := "left"
+		if item.Right.Output == output {
+			winner = "right"
+		}
+		_, err := judge(fmt.Sprintf(`{"left_result_id":%d,"right_result_id":%d,"winner":%q}`,
+			item.Left.ResultID, item.Right.ResultID, winner), reviewer)
+		require.NoError(t, err)
+	}
+
+	// Pairs are outputs of different versions for the same row; identical
+	// outputs are not worth reviewing
+	_, item, err := next("prompt=test-prompt", "ana")
+	require.NoError(t, err)
+	assert.Equal(t, "row-1", item.DatasetRowID)
+	assert.Equal(t, "Capital of France?", item.Variables["question"])
+	assert.Contains(t, []string{item.Left.Output, item.Right.Output}, "Paris")
+	prefer(item, "Paris", "ana")
+
+	// Each reviewer sees every pair once
+	_, item, err = next("", "ana")
+	require.NoError(t, err)
+	assert.Contains(t, []string{item.Left.Output, item.Right.Output}, "Paris")
+	prefer(item, "Paris", "ana")
+	rec, _, err := next("", "ana")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	_, _, err = next("reviewer=ana", "bo")
+	assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	_, item, err = next("reviewer=bo", "bo")
+	require.NoError(t, err)
+	_, err = judge(fmt.Sprintf(`{"left_result_id":%d,"right_result_id":%d,"winner":"tie","notes":"both fine"}`,
+		item.Left.ResultID, item.Right.ResultID), "bo")
+	require.NoError(t, err)
+
+	// Ratings come from every judgment of the prompt
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("test-prompt")
+	require.NoError(t, h.GetRatings(c))
+	var ratings struct {
+		Items []models.VersionRating `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &ratings))
+	require.Len(t, ratings.Items, 2)
+	assert.Equal(t, int64(2), ratings.Items[0].Version)
+	assert.Equal(t, 2, ratings.Items[0].Wins)
+	assert.Equal(t, 1, ratings.Items[0].Ties)
+	assert.Equal(t, 3, ratings.Items[0].Judgments)
+	assert.Equal(t, 2, ratings.Items[1].Losses)
+	assert.Greater(t, ratings.Items[0].Rating, 1000.0)
+	assert.InDelta(t, 2000, ratings.Items[0].Rating+ratings.Items[1].Rating, 1e-6)
+
+	// Judgments must compare different versions on the same row
+	for _, body := range []string{
+		fmt.Sprintf(`{"left_result_id":%d,"right_result_id":%d,"winner":"best"}`, results["run-1/row-1"], results["run-2/row-1"]),
+		fmt.Sprintf(`{"left_result_id":%d,"right_result_id":%d,"winner":"left"}`, results["run-1/row-1"], results["run-1/row-1"]),
+		fmt.Sprintf(`{"left_result_id":%d,"right_result_id":%d,"winner":"left"}`, results["run-1/row-1"], results["run-3/row-1"]),
+		fmt.Sprintf(`{"left_result_id":%d,"right_result_id":%d,"winner":"left"}`, results["run-1/row-1"], results["run-2/row-2"]),
+		fmt.Sprintf(`{"left_result_id":%d,"right_result_id":999,"winner":"left"}`, results["run-1/row-1"]),
+	} {
+		_, err = judge(body, "ana")
+		assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code, body)
+	}
+
+	_, _, err = next("prompt=missing", "")
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+
+	// Purging the prompt removes its judgments
+	require.NoError(t, store.PurgePrompt(context.Background(), "test-prompt", sql.NullInt64{}))
+	rec, _, err = next("", "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
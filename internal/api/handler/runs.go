@@ -0,0 +1,306 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/llm"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+const (
+	defaultRunLimit = 50
+	maxRunLimit     = 500
+
+	defaultCompleteTimeout = 2 * time.Minute
+)
+
+// preparedRun is a validated run request ready to send to a provider
+type preparedRun struct {
+	provider llm.Provider
+	request  llm.Request
+	version  *sqlc.PromptVersion
+}
+
+// runParameters is the sampling configuration stored with each run
+type runParameters struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	MaxTokens   int      `json:"max_tokens"`
+	TopP        *float64 `json:"top_p,omitempty"`
+}
+
+// prepareRun validates a run request, loads the prompt version it refers
+// to and picks the provider for its model
+func (h *Handler) prepareRun(ctx context.Context, req models.RunPromptRequest) (*preparedRun, error) {
+	run := &preparedRun{}
+	messages := req.Messages
+	templated := req.Variables != nil
+	var inputs []models.Input
+
+	if req.PromptID != "" {
+		prompt, _, err := h.findPrompt(ctx, req.PromptID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
+			}
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompt: "+err.Error())
+		}
+
+		version, err := h.findVersion(ctx, prompt.ID, int64(req.Version))
+		if err != nil {
+			return nil, err
+		}
+		run.version = &version
+
+		if len(messages) == 0 {
+			messages, err = fromDBMessages(version.Content)
+			if err != nil {
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to decode version messages: "+err.Error())
+			}
+			// Stored versions are always templates
+			templated = true
+			inputs, err = decodeInputs(version.Inputs)
+			if err != nil {
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to decode version inputs: "+err.Error())
+			}
+		}
+	}
+
+	if templated {
+		var err error
+		messages, err = renderMessages(inputs, messages, req.Variables)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate required fields
+	if req.Model == "" || len(messages) == 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Model and messages are required")
+	}
+
+	// Pick the provider for the requested model
+	provider, model, err := h.Providers.Resolve(req.Model)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	run.provider = provider
+	run.request = llm.Request{
+		Model:       model,
+		Messages:    messages,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		TopP:        req.TopP,
+	}
+	return run, nil
+}
+
+// complete sends a prepared run to its provider. Provider clients have no
+// overall timeout so that streams can run long; a completion that stalls
+// mid-body would otherwise hang forever, even in background eval runs
+// whose context is never cancelled.
+func (h *Handler) complete(ctx context.Context, run *preparedRun) (*llm.Response, error) {
+	if h.CompleteTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.CompleteTimeout)
+		defer cancel()
+	}
+	return run.provider.Complete(ctx, run.request)
+}
+
+// recordRun stores the outcome of a run and returns its ID. Failures to
+// record are logged rather than surfaced so they never hide the model's
+// response from the caller.
+func (h *Handler) recordRun(c echo.Context, req models.RunPromptRequest, run *preparedRun, result *llm.Response, runErr error, latency time.Duration) string {
+	// The run is recorded even if the client has already disconnected
+	ctx := context.WithoutCancel(c.Request().Context())
+	id, err := h.storeRun(ctx, req, run, result, runErr, latency)
+	if err != nil {
+		c.Logger().Errorf("Failed to record run: %v", err)
+		return ""
+	}
+	return id
+}
+
+// storeRun stores the outcome of a run and returns its ID
+func (h *Handler) storeRun(ctx context.Context, req models.RunPromptRequest, run *preparedRun, result *llm.Response, runErr error, latency time.Duration) (string, error) {
+	params, _ := json.Marshal(runParameters{
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		TopP:        req.TopP,
+	})
+
+	record := sqlc.CreateRunParams{
+		ID:         uuid.New().String(),
+		Model:      req.Model,
+		Parameters: string(params),
+		Messages:   toDBMessages(run.request.Messages),
+		LatencyMs:  latency.Milliseconds(),
+		CreatedBy:  sql.NullString{String: req.CreatedBy.ID, Valid: req.CreatedBy.ID != ""},
+	}
+	if run.version != nil {
+		record.PromptID = run.version.PromptID
+		record.PromptVersionID = sql.NullString{String: run.version.ID, Valid: true}
+	}
+	if runErr != nil {
+		record.Error = sql.NullString{String: runErr.Error(), Valid: true}
+	} else {
+		record.Response = sql.NullString{String: result.Content, Valid: true}
+		record.PromptTokens = int64(result.Usage.PromptTokens)
+		record.CompletionTokens = int64(result.Usage.CompletionTokens)
+		record.TotalTokens = int64(result.Usage.TotalTokens)
+	}
+
+	if _, err := h.Store.CreateRun(ctx, record); err != nil {
+		return "", err
+	}
+	return record.ID, nil
+}
+
+// runFilters holds the query parameters accepted by the run listings
+type runFilters struct {
+	Model         sql.NullString
+	Failed        sql.NullBool
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	Limit         int64
+}
+
+// parseRunFilters reads ?model=, ?status=ok|error, ?from=, ?to= (RFC 3339)
+// and ?limit= from the request
+func parseRunFilters(c echo.Context) (runFilters, error) {
+	f := runFilters{Limit: defaultRunLimit}
+
+	if model := c.QueryParam("model"); model != "" {
+		f.Model = sql.NullString{String: model, Valid: true}
+	}
+
+	switch c.QueryParam("status") {
+	case "":
+	case "ok":
+		f.Failed = sql.NullBool{Bool: false, Valid: true}
+	case "error":
+		f.Failed = sql.NullBool{Bool: true, Valid: true}
+	default:
+		return f, echo.NewHTTPError(http.StatusBadRequest, "Invalid status, expected ok or error")
+	}
+
+	for param, dst := range map[string]*sql.NullTime{"from": &f.CreatedAfter, "to": &f.CreatedBefore} {
+		value := c.QueryParam(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return f, echo.NewHTTPError(http.StatusBadRequest, "Invalid "+param+" time, expected RFC 3339")
+		}
+		*dst = sql.NullTime{Time: t.UTC(), Valid: true}
+	}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || n <= 0 {
+			return f, echo.NewHTTPError(http.StatusBadRequest, "Invalid limit")
+		}
+		if n > maxRunLimit {
+			n = maxRunLimit
+		}
+		f.Limit = n
+	}
+
+	return f, nil
+}
+
+// GetVersionRuns returns the recorded runs of a prompt version
+func (h *Handler) GetVersionRuns(c echo.Context) error {
+	promptID := c.Param("id")
+	versionStr := c.Param("version")
+
+	// Parse version number
+	versionNum, err := strconv.ParseInt(versionStr, 10, 64)
+	if err != nil || versionNum <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid version number")
+	}
+
+	filters, err := parseRunFilters(c)
+	if err != nil {
+		return err
+	}
+
+	version, err := h.findVersion(c.Request().Context(), promptID, versionNum)
+	if err != nil {
+		return err
+	}
+
+	// Get runs from database
+	runs, err := h.Store.ListRunsByVersion(c.Request().Context(), sqlc.ListRunsByVersionParams{
+		PromptVersionID: sql.NullString{String: version.ID, Valid: true},
+		Model:           filters.Model,
+		Failed:          filters.Failed,
+		CreatedAfter:    filters.CreatedAfter,
+		CreatedBefore:   filters.CreatedBefore,
+		Limit:           filters.Limit,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch runs: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, runs)
+}
+
+// GetPromptRuns returns the recorded runs of every version of a prompt
+func (h *Handler) GetPromptRuns(c echo.Context) error {
+	promptID := c.Param("id")
+
+	filters, err := parseRunFilters(c)
+	if err != nil {
+		return err
+	}
+
+	// Check if prompt exists
+	_, err = h.Store.GetPrompt(c.Request().Context(), promptID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompt: "+err.Error())
+	}
+
+	// Get runs from database
+	runs, err := h.Store.ListRunsByPrompt(c.Request().Context(), sqlc.ListRunsByPromptParams{
+		PromptID:      sql.NullString{String: promptID, Valid: true},
+		Model:         filters.Model,
+		Failed:        filters.Failed,
+		CreatedAfter:  filters.CreatedAfter,
+		CreatedBefore: filters.CreatedBefore,
+		Limit:         filters.Limit,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch runs: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, runs)
+}
+
+// GetRun returns a single recorded run
+func (h *Handler) GetRun(c echo.Context) error {
+	run, err := h.Store.GetRun(c.Request().Context(), c.Param("run"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "Run not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch run: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, run)
+}
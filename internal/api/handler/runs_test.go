@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/llm"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRun struct {
+	ID              string         `json:"id"`
+	PromptID        sql.NullString `json:"prompt_id"`
+	PromptVersionID sql.NullString `json:"prompt_version_id"`
+	Model           string         `json:"model"`
+	Parameters      string         `json:"parameters"`
+	Messages        string         `json:"messages"`
+	Response        sql.NullString `json:"response"`
+	TotalTokens     int64          `json:"total_tokens"`
+	Error           sql.NullString `json:"error"`
+}
+
+func TestRunHistory(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer upstream.Close()
+
+	e := echo.New()
+	h := NewHandler(store)
+	h.Providers = llm.NewRegistry("")
+	h.Providers.Register(llm.NewFakeProvider())
+	h.Providers.Register(llm.NewOpenAIProvider(upstream.URL, "test-key"))
+
+	// Create a test prompt and version
+	_, err := store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{
+		ID:    "test-prompt",
+		Title: "Test Prompt",
+	})
+	require.NoError(t, err)
+	_, err = store.CreateVersion(context.Background(), sqlc.CreateVersionParams{
+		ID:       "test-version",
+		PromptID: sql.NullString{String: "test-prompt", Valid: true},
+		Version:  1,
+		Content:  `[{"role":"user","content":"Summarize the refund policy"}]`,
+	})
+	require.NoError(t, err)
+
+	run := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/run", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		if err := h.RunPrompt(e.NewContext(req, rec)); err != nil {
+			return err.(*echo.HTTPError).Code
+		}
+		return rec.Code
+	}
+
+	// A successful run of the latest version using its stored messages
+	assert.Equal(t, http.StatusOK, run(`{"prompt_id":"test-prompt","model":"fake/echo","temperature":0.3,"top_p":0}`))
+	// A failing run of version 1
+	assert.Equal(t, http.StatusBadGateway, run(`{"prompt_id":"test-prompt","version":1,"model":"gpt-4o"}`))
+	// An ad-hoc run that is not tied to a prompt
+	assert.Equal(t, http.StatusOK, run(`{"model":"fake/echo","messages":[{"role":"user","content":"hi"}]}`))
+
+	list := func(query string) []testRun {
+		req := httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/prompts/:id/versions/:version/runs")
+		c.SetParamNames("id", "version")
+		c.SetParamValues("test-prompt", "1")
+		require.NoError(t, h.GetVersionRuns(c))
+
+		var runs []testRun
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &runs))
+		return runs
+	}
+
+	// Test GET /prompts/:id/versions/:version/runs
+	runs := list("")
+	assert.Len(t, runs, 2)
+
+	runs = list("status=ok")
+	require.Len(t, runs, 1)
+	assert.Equal(t, "fake/echo", runs[0].Model)
+	assert.Equal(t, "test-prompt", runs[0].PromptID.String)
+	assert.Equal(t, "test-version", runs[0].PromptVersionID.String)
+	assert.Equal(t, "echo: Summarize the refund policy", runs[0].Response.String)
+	assert.Equal(t, int64(9), runs[0].TotalTokens)
+	assert.JSONEq(t, `{"temperature":0.3,"max_tokens":0,"top_p":0}`, runs[0].Parameters)
+
+	var messages []models.Message
+	require.NoError(t, json.Unmarshal([]byte(runs[0].Messages), &messages))
+	assert.Equal(t, "Summarize the refund policy", messages[0].Content)
+
+	runs = list("status=error&model=gpt-4o")
+	require.Len(t, runs, 1)
+	assert.Contains(t, runs[0].Error.String, "503")
+	assert.False(t, runs[0].Response.Valid)
+
+	assert.Len(t, list("from=2999-01-01T00:00:00Z"), 0)
+	assert.Len(t, list("limit=1"), 1)
+
+	// Test GET /runs/:run
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/runs/:run")
+	c.SetParamNames("run")
+	c.SetParamValues(runs[0].ID)
+	require.NoError(t, h.GetRun(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRunTimeout(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Send the headers and part of the body, then stall
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"gpt-4o","choices":[`))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer upstream.Close()
+
+	e := echo.New()
+	h := NewHandler(store)
+	h.Providers = llm.NewRegistry("")
+	h.Providers.Register(llm.NewOpenAIProvider(upstream.URL, "test-key"))
+	h.CompleteTimeout = 50 * time.Millisecond
+
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"Hello"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/run", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	err := h.RunPrompt(e.NewContext(req, httptest.NewRecorder()))
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusBadGateway, httpErr.Code)
+	assert.Contains(t, httpErr.Message, "deadline exceeded")
+}
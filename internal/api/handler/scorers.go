@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/eval"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// scoreCase runs every scorer on a case and returns the evaluations to
+// record for the version. Scorers that do not apply to the case are
+// skipped; scorers that fail are recorded without a score.
+func scoreCase(ctx context.Context, scorers []eval.Scorer, c eval.Case, versionID string, createdBy models.User) []sqlc.CreateEvaluationParams {
+	evals := make([]sqlc.CreateEvaluationParams, 0, len(scorers))
+	for _, scorer := range scorers {
+		e := sqlc.CreateEvaluationParams{
+			ID:              uuid.New().String(),
+			PromptVersionID: sql.NullString{String: versionID, Valid: true},
+			CreatedBy:       sql.NullString{String: createdBy.ID, Valid: createdBy.ID != ""},
+			Scorer:          scorer.Name(),
+		}
+
+		result, err := scorer.Score(ctx, c)
+		switch {
+		case errors.Is(err, eval.ErrNotApplicable):
+			continue
+		case err != nil:
+			e.Notes = sql.NullString{String: "scorer failed: " + err.Error(), Valid: true}
+		default:
+			e.Score = sql.NullFloat64{Float64: result.Score, Valid: true}
+			e.Passed = sql.NullBool{Bool: result.Passed, Valid: true}
+			e.Notes = sql.NullString{String: result.Reason, Valid: true}
+			e.JudgePromptVersionID = sql.NullString{String: result.JudgeVersionID, Valid: result.JudgeVersionID != ""}
+			e.JudgeRunID = sql.NullString{String: result.JudgeRunID, Valid: result.JudgeRunID != ""}
+		}
+		evals = append(evals, e)
+	}
+	return evals
+}
+
+// meanScore averages the scores of evaluations, or is NULL when none has
+// a score
+func meanScore(evals []sqlc.CreateEvaluationParams) sql.NullFloat64 {
+	var sum float64
+	var n int
+	for _, e := range evals {
+		if e.Score.Valid {
+			sum += e.Score.Float64
+			n++
+		}
+	}
+	if n == 0 {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: sum / float64(n), Valid: true}
+}
+
+// GetScorers returns the scorer types that can be used in scorer specs
+func (h *Handler) GetScorers(c echo.Context) error {
+	return c.JSON(http.StatusOK, models.ListResponse{Items: h.Scorers.Types()})
+}
+
+// ScoreOutput checks one output of a prompt version with automatic scorers
+// and records an evaluation per scorer
+func (h *Handler) ScoreOutput(c echo.Context) error {
+	var req models.ScoreRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	// Validate required fields
+	if len(req.Scorers) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Scorers are required")
+	}
+	scorers, err := h.Scorers.NewAll(c.Request().Context(), req.Scorers)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	version, err := h.versionParam(c)
+	if err != nil {
+		return err
+	}
+	if req.CreatedBy, err = h.author(c, req.CreatedBy); err != nil {
+		return err
+	}
+
+	evals := scoreCase(c.Request().Context(), scorers, eval.Case{
+		Input:     req.Input,
+		Variables: req.Variables,
+		Output:    req.Output,
+		Expected:  req.Expected,
+	}, version.ID, req.CreatedBy)
+
+	created, err := h.Store.CreateEvaluations(c.Request().Context(), evals)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create evaluations: "+err.Error())
+	}
+	payloads, err := h.newAuthors().evaluations(c.Request().Context(), created)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, models.ListResponse{Items: payloads})
+}
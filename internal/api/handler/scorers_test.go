@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/llm"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEvaluation struct {
+	Scorer               string          `json:"scorer"`
+	Score                sql.NullFloat64 `json:"score"`
+	Passed               sql.NullBool    `json:"passed"`
+	Notes                sql.NullString  `json:"notes"`
+	JudgePromptVersionID sql.NullString  `json:"judge_prompt_version_id"`
+	JudgeRunID           sql.NullString  `json:"judge_run_id"`
+}
+
+func TestScorers(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+	h.Providers = llm.NewRegistry("")
+	h.Providers.Register(llm.NewFakeProvider())
+
+	_, err := store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{
+		ID:    "test-prompt",
+		Title: "Test Prompt",
+	})
+	require.NoError(t, err)
+	_, err = store.CreateVersion(context.Background(), sqlc.CreateVersionParams{
+		ID:       "test-version",
+		PromptID: sql.NullString{String: "test-prompt", Valid: true},
+		Version:  1,
+		Content:  `[{"role":"user","content":"{{answer}}"}]`,
+	})
+	require.NoError(t, err)
+
+	call := func(method string, fn echo.HandlerFunc, names []string, values []string, body string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(method, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames(names...)
+		c.SetParamValues(values...)
+		return rec, fn(c)
+	}
+	versionParams := []string{"id", "version"}
+	versionValues := []string{"test-prompt", "1"}
+
+	// A single output is checked by every applicable scorer
+	rec, err := call(http.MethodPost, h.ScoreOutput, versionParams, versionValues, `{
+		"output": "{\"total\": 42}",
+		"scorers": [
+			{"type": "json_schema", "schema": {"type": "object", "required": ["total"]}},
+			{"type": "contains", "value": "total", "name": "mentions_total"},
+			{"type": "exact_match"}
+		]
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var scored struct {
+		Items []testEvaluation `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &scored))
+	require.Len(t, scored.Items, 2)
+	assert.Equal(t, "json_schema", scored.Items[0].Scorer)
+	assert.True(t, scored.Items[0].Passed.Bool)
+	assert.Equal(t, "mentions_total", scored.Items[1].Scorer)
+
+	_, err = call(http.MethodPost, h.ScoreOutput, versionParams, versionValues, `{"output":"x","scorers":[{"type":"regex"}]}`)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	_, err = call(http.MethodPost, h.ScoreOutput, versionParams, versionValues, `{"output":"x","scorers":[]}`)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+
+	// Manual evaluations are recorded as such
+	rec, err = call(http.MethodPost, h.CreateEvaluation, versionParams, versionValues, `{"score":0.8,"notes":"good"}`)
+	require.NoError(t, err)
+	var manual testEvaluation
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &manual))
+	assert.Equal(t, "manual", manual.Scorer)
+	assert.False(t, manual.Passed.Valid)
+
+	// Eval runs record each scorer's verdict per row and aggregate them
+	dataset, _, err := store.CreateDataset(context.Background(), sqlc.CreateDatasetParams{ID: "numbers", Name: "numbers"},
+		[]sqlc.CreateDatasetRowParams{
+			{ID: "row-1", Variables: `{"answer":"12"}`, Expected: sql.NullString{String: "12", Valid: true}},
+			{ID: "row-2", Variables: `{"answer":"about 15 apples"}`, Expected: sql.NullString{String: "20", Valid: true}},
+		})
+	require.NoError(t, err)
+
+	rec, err = call(http.MethodPost, h.CreateEvalRun, versionParams, versionValues, `{
+		"dataset_id": "`+dataset.ID+`",
+		"model": "fake/echo",
+		"scorers": [{"type": "numeric", "tolerance": 1}, {"type": "length", "max": 3, "unit": "words"}]
+	}`)
+	require.NoError(t, err)
+	var started testEvalRun
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &started))
+	require.NoError(t, h.WaitForEvalRuns(context.Background()))
+
+	rec, err = call(http.MethodGet, h.GetEvalRun, []string{"id", "version", "run"}, []string{"test-prompt", "1", started.ID}, "")
+	require.NoError(t, err)
+	var evalRun struct {
+		Scorers []json.RawMessage `json:"scorers"`
+		Summary struct {
+			Scorers []struct {
+				Scorer string  `json:"scorer"`
+				Passed int64   `json:"passed"`
+				Failed int64   `json:"failed"`
+				Mean   float64 `json:"mean_score"`
+			} `json:"scorers"`
+		} `json:"summary"`
+		Results []struct {
+			Score       sql.NullFloat64  `json:"score"`
+			Evaluations []testEvaluation `json:"evaluations"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &evalRun))
+	assert.Len(t, evalRun.Scorers, 2)
+
+	require.Len(t, evalRun.Summary.Scorers, 2)
+	assert.Equal(t, "length", evalRun.Summary.Scorers[0].Scorer)
+	assert.Equal(t, int64(1), evalRun.Summary.Scorers[0].Passed)
+	assert.Equal(t, int64(1), evalRun.Summary.Scorers[0].Failed)
+	assert.Equal(t, "numeric", evalRun.Summary.Scorers[1].Scorer)
+	assert.Equal(t, int64(1), evalRun.Summary.Scorers[1].Passed)
+
+	// Each row's score is the mean of its scorers' scores
+	require.Len(t, evalRun.Results, 2)
+	require.Len(t, evalRun.Results[0].Evaluations, 2)
+	assert.Equal(t, 1.0, evalRun.Results[0].Score.Float64)
+	assert.InDelta(t, 0.375, evalRun.Results[1].Score.Float64, 1e-9)
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+
+	// Markers the search query puts around matches
+	matchStart = "\x02"
+	matchEnd   = "\x03"
+)
+
+// SearchPrompts handles GET /api/search?q=, returning live prompts whose
+// title, description or latest version match q, best match first
+func (h *Handler) SearchPrompts(c echo.Context) error {
+	query := db.MatchQuery(c.QueryParam("q"))
+	if query == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Search query is required")
+	}
+
+	limit := int64(defaultSearchLimit)
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid limit")
+		}
+		if n > maxSearchLimit {
+			n = maxSearchLimit
+		}
+		limit = n
+	}
+
+	rows, err := h.Store.SearchPrompts(c.Request().Context(), sqlc.SearchPromptsParams{
+		Query: query,
+		Limit: limit,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to search prompts: "+err.Error())
+	}
+
+	results := make([]models.SearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = models.SearchResult{
+			ID:          row.ID,
+			Slug:        row.Slug,
+			Namespace:   row.Namespace,
+			Title:       row.Title,
+			Description: row.Description.String,
+			Highlights: models.SearchHighlights{
+				Title:       highlightMatches(row.TitleHighlight),
+				Description: highlightMatches(row.DescriptionSnippet),
+				Content:     highlightMatches(row.ContentSnippet),
+			},
+			Score: row.Score,
+		}
+	}
+
+	return c.JSON(http.StatusOK, models.ListResponse{Items: results})
+}
+
+// highlightMatches escapes a marked fragment for HTML and wraps its
+// matches in <mark>. Fragments without a match are dropped.
+func highlightMatches(fragment string) string {
+	if !strings.Contains(fragment, matchStart) {
+		return ""
+	}
+	return strings.NewReplacer(matchStart, "<mark>", matchEnd, "</mark>").Replace(html.EscapeString(fragment))
+}
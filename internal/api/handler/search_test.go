@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+func TestMatchQuery(t *testing.T) {
+	assert.Equal(t, `"refund" "polic"*`, db.MatchQuery(`refund polic`))
+	assert.Equal(t, `"NOT" "a" "OR" "b"*`, db.MatchQuery(`NOT "a" OR-b`))
+	assert.Equal(t, "", db.MatchQuery(` -- * `))
+}
+
+func TestSearchPrompts(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	ctx := context.Background()
+	for _, p := range []struct{ id, title, description string }{
+		{"support", "Support reply", "Answers billing questions"},
+		{"escalation", "Refund escalation", "Hands off to a human"},
+		{"weather", "Weather bot", "Small talk"},
+	} {
+		_, err := store.CreatePrompt(ctx, sqlc.CreatePromptParams{
+			ID:          p.id,
+			Title:       p.title,
+			Description: sql.NullString{String: p.description, Valid: true},
+			Slug:        p.id,
+		})
+		require.NoError(t, err)
+	}
+	for i, content := range []string{
+		`[{"role":"system","content":"Talk about the weather"}]`,
+		`[{"role":"system","content":"Explain our <b>refund policy</b> politely"},{"role":"user","content":"{{question}}"}]`,
+	} {
+		_, err := store.CreateVersion(ctx, sqlc.CreateVersionParams{
+			ID:       "support-v" + string(rune('1'+i)),
+			PromptID: sql.NullString{String: "support", Valid: true},
+			Version:  int64(i + 1),
+			Content:  content,
+		})
+		require.NoError(t, err)
+	}
+
+	search := func(q string) ([]models.SearchResult, error) {
+		req := httptest.NewRequest(http.MethodGet, "/search?q="+url.QueryEscape(q), nil)
+		rec := httptest.NewRecorder()
+		if err := h.SearchPrompts(e.NewContext(req, rec)); err != nil {
+			return nil, err
+		}
+		var response struct {
+			Items []models.SearchResult `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response.Items, nil
+	}
+	ids := func(results []models.SearchResult) []string {
+		ids := []string{}
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	// The last word matches as a prefix and content matches are highlighted
+	results, err := search("refund polic")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"support"}, ids(results))
+	assert.Equal(t, "Explain our &lt;b&gt;<mark>refund</mark> <mark>policy</mark>&lt;/b&gt; politely\n{{question}}", results[0].Highlights.Content)
+	assert.Empty(t, results[0].Highlights.Title)
+
+	// Title matches rank above content matches
+	results, err = search("refund")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"escalation", "support"}, ids(results))
+	assert.Equal(t, "<mark>Refund</mark> escalation", results[0].Highlights.Title)
+
+	// Only the latest version is indexed
+	results, err = search("weather")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"weather"}, ids(results))
+
+	// Edits are reindexed and deleted prompts drop out
+	_, err = store.UpdatePrompt(ctx, sqlc.UpdatePromptParams{ID: "weather", Title: "Forecast bot"})
+	require.NoError(t, err)
+	_, err = store.SoftDeletePrompt(ctx, sqlc.SoftDeletePromptParams{ID: "escalation"})
+	require.NoError(t, err)
+	results, err = search("forecast")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"weather"}, ids(results))
+	results, err = search("refund")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"support"}, ids(results))
+
+	// Queries without words are rejected
+	_, err = search(`"*"`)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+}
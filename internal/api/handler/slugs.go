@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+const maxSlugLength = 64
+
+var (
+	// slugPattern matches valid slugs and namespaces, e.g. "support-reply"
+	slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	// slugSeparators matches runs of characters that are not allowed in slugs
+	slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// slugify derives a slug from a prompt title
+func slugify(title string) string {
+	slug := strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	if slug == "" {
+		slug = "prompt"
+	}
+	return slug
+}
+
+// validateSlug checks an explicitly requested slug and namespace
+func validateSlug(slug, namespace string) error {
+	if len(slug) > maxSlugLength || !slugPattern.MatchString(slug) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Slugs must be lowercase letters and digits separated by single hyphens")
+	}
+	if namespace != "" && (len(namespace) > maxSlugLength || !slugPattern.MatchString(namespace)) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Namespaces must be lowercase letters and digits separated by single hyphens")
+	}
+	return nil
+}
+
+// parsePromptRef splits a "namespace:slug" reference. References without a
+// namespace address the default namespace.
+func parsePromptRef(ref string) (namespace, slug string) {
+	if ns, s, ok := strings.Cut(ref, ":"); ok {
+		return ns, s
+	}
+	return "", ref
+}
+
+// formatPromptRef is the inverse of parsePromptRef
+func formatPromptRef(namespace, slug string) string {
+	if namespace == "" {
+		return slug
+	}
+	return namespace + ":" + slug
+}
+
+// uniqueSlug returns base, or base with a numeric suffix, such that no
+// prompt in the namespace uses it yet
+func (h *Handler) uniqueSlug(ctx context.Context, namespace, base string) (string, error) {
+	slug := base
+	for i := 2; ; i++ {
+		_, err := h.Store.GetPromptBySlug(ctx, sqlc.GetPromptBySlugParams{Namespace: namespace, Slug: slug})
+		if errors.Is(err, sql.ErrNoRows) {
+			return slug, nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		suffix := fmt.Sprintf("-%d", i)
+		if len(base)+len(suffix) > maxSlugLength {
+			base = strings.TrimRight(base[:maxSlugLength-len(suffix)], "-")
+		}
+		slug = base + suffix
+	}
+}
+
+// findPrompt looks up a prompt by ID, by "[namespace:]slug", or by a slug
+// it used before being renamed. redirected reports the last case so
+// callers can point clients at the current slug.
+func (h *Handler) findPrompt(ctx context.Context, ref string) (prompt sqlc.Prompt, redirected bool, err error) {
+	prompt, err = h.Store.GetPrompt(ctx, ref)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return prompt, false, err
+	}
+
+	namespace, slug := parsePromptRef(ref)
+	prompt, err = h.Store.GetPromptBySlug(ctx, sqlc.GetPromptBySlugParams{Namespace: namespace, Slug: slug})
+	if !errors.Is(err, sql.ErrNoRows) {
+		return prompt, false, err
+	}
+
+	redirect, err := h.Store.GetSlugRedirect(ctx, sqlc.GetSlugRedirectParams{Namespace: namespace, Slug: slug})
+	if err != nil {
+		return sqlc.Prompt{}, false, err
+	}
+	prompt, err = h.Store.GetPrompt(ctx, redirect.PromptID)
+	return prompt, err == nil, err
+}
+
+// ResolvePrompt is middleware for /prompts/:id routes. It lets :id be a
+// prompt ID or a "[namespace:]slug" by rewriting the parameter to the
+// prompt's ID, and redirects requests that use a renamed slug.
+func (h *Handler) ResolvePrompt(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ref := c.Param("id")
+
+		prompt, redirected, err := h.findPrompt(c.Request().Context(), ref)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// Let the handler report the missing prompt
+				return next(c)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompt: "+err.Error())
+		}
+
+		if redirected {
+			target := *c.Request().URL
+			current := url.PathEscape(formatPromptRef(prompt.Namespace, prompt.Slug))
+			target.Path = strings.Replace(target.Path, "/prompts/"+ref, "/prompts/"+current, 1)
+			target.RawPath = ""
+
+			status := http.StatusPermanentRedirect
+			if c.Request().Method == http.MethodGet || c.Request().Method == http.MethodHead {
+				status = http.StatusMovedPermanently
+			}
+			return c.Redirect(status, target.RequestURI())
+		}
+
+		values := c.ParamValues()
+		for i, name := range c.ParamNames() {
+			if name == "id" {
+				values[i] = prompt.ID
+			}
+		}
+		c.SetParamValues(values...)
+
+		return next(c)
+	}
+}
+
+// UpdatePromptSlug renames a prompt's slug and namespace. The old slug
+// keeps redirecting to the prompt.
+func (h *Handler) UpdatePromptSlug(c echo.Context) error {
+	id := c.Param("id")
+
+	var req models.SlugRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+	if err := validateSlug(req.Slug, req.Namespace); err != nil {
+		return err
+	}
+
+	existing, err := h.Store.GetPrompt(c.Request().Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompt: "+err.Error())
+	}
+	ifRevision, err := checkIfMatch(c, existing)
+	if err != nil {
+		return err
+	}
+
+	prompt, err := h.Store.RenamePromptSlug(c.Request().Context(), db.RenamePromptSlugParams{
+		ID:         id,
+		Slug:       req.Slug,
+		Namespace:  req.Namespace,
+		IfRevision: ifRevision,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return echo.NewHTTPError(http.StatusNotFound, "Prompt not found")
+		case errors.Is(err, db.ErrRevisionMismatch):
+			return echo.NewHTTPError(http.StatusPreconditionFailed, "Prompt has been modified")
+		case errors.Is(err, db.ErrSlugTaken):
+			return echo.NewHTTPError(http.StatusConflict, "Slug is already in use")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to rename prompt: "+err.Error())
+	}
+
+	payload, err := h.newAuthors().prompt(c.Request().Context(), prompt)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set(headerETag, promptETag(prompt))
+	return c.JSON(http.StatusOK, payload)
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSlugPrompt struct {
+	ID        string `json:"id"`
+	Slug      string `json:"slug"`
+	Namespace string `json:"namespace"`
+}
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "support-reply-v2", slugify("  Support Reply (v2)!"))
+	assert.Equal(t, "prompt", slugify("???"))
+	assert.Len(t, slugify(strings.Repeat("a", 100)), maxSlugLength)
+}
+
+func TestPromptSlugs(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	call := func(method string, fn echo.HandlerFunc, ref, body string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(method, "/prompts/"+ref+"?expand=1", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/prompts/:id")
+		c.SetParamNames("id")
+		c.SetParamValues(ref)
+		return rec, h.ResolvePrompt(fn)(c)
+	}
+	create := func(body string) (testSlugPrompt, error) {
+		req := httptest.NewRequest(http.MethodPost, "/prompts", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		var prompt testSlugPrompt
+		if err := h.CreatePrompt(e.NewContext(req, rec)); err != nil {
+			return prompt, err
+		}
+		return prompt, json.Unmarshal(rec.Body.Bytes(), &prompt)
+	}
+
+	// Slugs are derived from the title and made unique
+	first, err := create(`{"title":"Support Reply","description":"d","messages":[{"role":"user","content":"hi"}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, "support-reply", first.Slug)
+	second, err := create(`{"title":"Support reply","description":"d","messages":[{"role":"user","content":"hi"}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, "support-reply-2", second.Slug)
+
+	// Explicit slugs are validated and must be free within their namespace
+	_, err = create(`{"title":"x","description":"d","slug":"Bad Slug","messages":[{"role":"user","content":"hi"}]}`)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	_, err = create(`{"title":"x","description":"d","slug":"support-reply","messages":[{"role":"user","content":"hi"}]}`)
+	assert.Equal(t, http.StatusConflict, err.(*echo.HTTPError).Code)
+	billing, err := create(`{"title":"x","description":"d","slug":"support-reply","namespace":"billing","messages":[{"role":"user","content":"hi"}]}`)
+	require.NoError(t, err)
+
+	// Prompts resolve by ID, slug and namespaced slug
+	for ref, id := range map[string]string{
+		first.ID:                first.ID,
+		"support-reply":         first.ID,
+		"support-reply-2":       second.ID,
+		"billing:support-reply": billing.ID,
+	} {
+		rec, err := call(http.MethodGet, h.GetPrompt, ref, "")
+		require.NoError(t, err, ref)
+		var prompt testSlugPrompt
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &prompt))
+		assert.Equal(t, id, prompt.ID, ref)
+	}
+
+	// Unknown references fall through to the handler's 404
+	_, err = call(http.MethodGet, h.GetPrompt, "missing", "")
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+
+	// Test PUT /prompts/:id/slug
+	rec, err := call(http.MethodPut, h.UpdatePromptSlug, "support-reply", `{"slug":"customer-reply","namespace":"support"}`)
+	require.NoError(t, err)
+	var renamed testSlugPrompt
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &renamed))
+	assert.Equal(t, first.ID, renamed.ID)
+	assert.Equal(t, "customer-reply", renamed.Slug)
+	assert.Equal(t, "support", renamed.Namespace)
+
+	_, err = call(http.MethodPut, h.UpdatePromptSlug, "support-reply-2", `{"slug":"customer-reply","namespace":"support"}`)
+	assert.Equal(t, http.StatusConflict, err.(*echo.HTTPError).Code)
+
+	// The old slug redirects to the new one
+	rec, err = call(http.MethodGet, h.GetPrompt, "support-reply", "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/prompts/support:customer-reply?expand=1", rec.Header().Get(echo.HeaderLocation))
+
+	rec, err = call(http.MethodPut, h.UpdatePrompt, "support-reply", `{}`)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+
+	// Reclaiming a slug replaces its redirect
+	_, err = call(http.MethodPut, h.UpdatePromptSlug, "support-reply-2", `{"slug":"support-reply"}`)
+	require.NoError(t, err)
+	rec, err = call(http.MethodGet, h.GetPrompt, "support-reply", "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var reclaimed testSlugPrompt
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &reclaimed))
+	assert.Equal(t, second.ID, reclaimed.ID)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/internal/llm"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+const mimeEventStream = "text/event-stream"
+
+// streamDelta is the payload of a "delta" event
+type streamDelta struct {
+	Content string `json:"content"`
+}
+
+// streamError is the payload of an "error" event
+type streamError struct {
+	Message string `json:"message"`
+}
+
+// RunPromptStream runs a prompt and relays the generated text as
+// server-sent events. Each chunk is sent as a "delta" event and the stream
+// ends with a "done" event carrying the full RunPromptResponse, or an
+// "error" event if the provider fails mid-stream.
+func (h *Handler) RunPromptStream(c echo.Context) error {
+	var req models.RunPromptRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+	var err error
+	if req.CreatedBy, err = h.author(c, req.CreatedBy); err != nil {
+		return err
+	}
+
+	return h.streamRun(c, req)
+}
+
+// streamRun streams a bound run request. The upstream call shares the
+// request context, so it is cancelled as soon as the client disconnects.
+func (h *Handler) streamRun(c echo.Context, req models.RunPromptRequest) error {
+	run, err := h.prepareRun(c.Request().Context(), req)
+	if err != nil {
+		return err
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, mimeEventStream)
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	ctx := c.Request().Context()
+	start := time.Now()
+	result, err := llm.Stream(ctx, run.provider, run.request, func(delta string) error {
+		return writeEvent(res, "delta", streamDelta{Content: delta})
+	})
+	runID := h.recordRun(c, req, run, result, err, time.Since(start))
+	if err != nil {
+		if ctx.Err() != nil {
+			// The client went away; there is nobody left to tell
+			return nil
+		}
+		return writeEvent(res, "error", streamError{Message: "Failed to run prompt: " + err.Error()})
+	}
+
+	return writeEvent(res, "done", models.RunPromptResponse{
+		RunID:    runID,
+		Response: result.Content,
+		Model:    req.Model,
+		Usage:    result.Usage,
+	})
+}
+
+// writeEvent writes a single server-sent event and flushes it to the client
+func writeEvent(res *echo.Response, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/epuerta9/prompts.kitchenai/internal/llm"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPromptStream(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+	h.Providers = llm.NewRegistry("")
+	h.Providers.Register(llm.NewFakeProvider())
+
+	// Test POST /run/stream
+	body := `{"model":"fake/echo","messages":[{"role":"user","content":"Hello world"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/run/stream", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Assertions
+	require.NoError(t, h.RunPromptStream(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get(echo.HeaderContentType))
+
+	expected := "event: delta\ndata: {\"content\":\"echo:\"}\n\n" +
+		"event: delta\ndata: {\"content\":\" Hello\"}\n\n" +
+		"event: delta\ndata: {\"content\":\" world\"}\n\n" +
+		"event: done\ndata: "
+	assert.True(t, strings.HasPrefix(rec.Body.String(), expected), rec.Body.String())
+
+	var done models.RunPromptResponse
+	err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(rec.Body.String(), expected))), &done)
+	require.NoError(t, err)
+	assert.NotEmpty(t, done.RunID)
+	assert.Equal(t, "echo: Hello world", done.Response)
+	assert.Equal(t, models.Usage{PromptTokens: 2, CompletionTokens: 3, TotalTokens: 5}, done.Usage)
+
+	// The Accept header on POST /run selects the same streaming mode
+	req = httptest.NewRequest(http.MethodPost, "/run", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAccept, "text/event-stream")
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	require.NoError(t, h.RunPrompt(c))
+	assert.True(t, strings.HasPrefix(rec.Body.String(), expected), rec.Body.String())
+}
+
+func TestRunPromptStreamCancelsUpstream(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	upstreamCancelled := make(chan struct{})
+
+	// Stand in for an OpenAI-compatible API that streams until cancelled
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"partial\"}}]}\n\n"))
+		w.(http.Flusher).Flush()
+
+		// Simulate the browser going away after the first token
+		disconnect()
+
+		select {
+		case <-r.Context().Done():
+			close(upstreamCancelled)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer upstream.Close()
+
+	e := echo.New()
+	h := NewHandler(store)
+	h.Providers = llm.NewRegistry("")
+	h.Providers.Register(llm.NewOpenAIProvider(upstream.URL, "test-key"))
+
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"Hello"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/run/stream", strings.NewReader(body)).WithContext(ctx)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	require.NoError(t, h.RunPromptStream(c))
+
+	select {
+	case <-upstreamCancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("upstream request was not cancelled after the client disconnected")
+	}
+	assert.NotContains(t, rec.Body.String(), "event: done")
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// tagName matches valid tag names such as "billing" or "team/support"
+var tagName = regexp.MustCompile(`^[a-z0-9][a-z0-9_./-]{0,49}$`)
+
+// promptPayload is a stored prompt together with its author and tag names
+type promptPayload struct {
+	authoredPrompt
+	Tags []string `json:"tags"`
+}
+
+// newPromptPayload loads the author and tags of a prompt
+func (h *Handler) newPromptPayload(ctx context.Context, prompt sqlc.Prompt) (promptPayload, error) {
+	authored, err := h.newAuthors().prompt(ctx, prompt)
+	if err != nil {
+		return promptPayload{}, err
+	}
+	tags, err := h.Store.ListPromptTagNames(ctx, prompt.ID)
+	if err != nil {
+		return promptPayload{}, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch tags: "+err.Error())
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	return promptPayload{authoredPrompt: authored, Tags: tags}, nil
+}
+
+// normalizeTag lowercases a tag name and checks it is valid
+func normalizeTag(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !tagName.MatchString(name) {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "Tag names must be up to 50 lowercase letters, digits, -, _, . or /")
+	}
+	return name, nil
+}
+
+// normalizeTags normalizes, sorts and deduplicates tag names
+func normalizeTags(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		tag, err := normalizeTag(name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// findTag loads the tag named by the :tag path parameter
+func (h *Handler) findTag(c echo.Context) (sqlc.Tag, error) {
+	tag, err := h.Store.GetTagByName(c.Request().Context(), strings.ToLower(c.Param("tag")))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return tag, echo.NewHTTPError(http.StatusNotFound, "Tag not found")
+		}
+		return tag, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch tag: "+err.Error())
+	}
+	return tag, nil
+}
+
+// GetTags returns all tags with the number of live prompts using them
+func (h *Handler) GetTags(c echo.Context) error {
+	tags, err := h.Store.ListTags(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch tags: "+err.Error())
+	}
+	if tags == nil {
+		tags = []sqlc.ListTagsRow{}
+	}
+
+	return c.JSON(http.StatusOK, models.ListResponse{Items: tags})
+}
+
+// CreateTag creates a tag that is not used by any prompt yet
+func (h *Handler) CreateTag(c echo.Context) error {
+	var req models.TagRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	name, err := normalizeTag(req.Name)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.Store.GetTagByName(c.Request().Context(), name)
+	if err == nil {
+		return echo.NewHTTPError(http.StatusConflict, "Tag already exists")
+	}
+
+	tag, err := h.Store.UpsertTag(c.Request().Context(), name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create tag: "+err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, tag)
+}
+
+// GetTag returns a tag by name
+func (h *Handler) GetTag(c echo.Context) error {
+	tag, err := h.findTag(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, tag)
+}
+
+// RenameTag renames a tag on every prompt that uses it
+func (h *Handler) RenameTag(c echo.Context) error {
+	var req models.TagRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	name, err := normalizeTag(req.Name)
+	if err != nil {
+		return err
+	}
+
+	tag, err := h.findTag(c)
+	if err != nil {
+		return err
+	}
+
+	tag, err = h.Store.RenameTag(c.Request().Context(), tag.ID, name)
+	if err != nil {
+		if errors.Is(err, db.ErrTagTaken) {
+			return echo.NewHTTPError(http.StatusConflict, "Tag already exists")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to rename tag: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag removes a tag from all prompts and deletes it
+func (h *Handler) DeleteTag(c echo.Context) error {
+	tag, err := h.findTag(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Store.DeleteTag(c.Request().Context(), tag.ID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete tag: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted", "name": tag.Name})
+}
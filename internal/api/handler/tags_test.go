@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testTaggedPrompt struct {
+	ID           string         `json:"id"`
+	Revision     int64          `json:"revision"`
+	CollectionID sql.NullString `json:"collection_id"`
+	Tags         []string       `json:"tags"`
+}
+
+func TestPromptTags(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	call := func(method string, fn echo.HandlerFunc, target, names, values, body string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if names != "" {
+			c.SetParamNames(names)
+			c.SetParamValues(values)
+		}
+		return rec, fn(c)
+	}
+	listIDs := func(query string) []string {
+		rec, err := call(http.MethodGet, h.GetPrompts, "/prompts?"+query, "", "", "")
+		require.NoError(t, err)
+		var page struct {
+			Items []testTaggedPrompt `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		ids := []string{}
+		for _, p := range page.Items {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	// Tags are normalized, deduplicated and created on demand
+	rec, err := call(http.MethodPost, h.CreatePrompt, "/prompts", "", "",
+		`{"title":"Support","description":"d","tags":["Billing"," support ","billing"]}`)
+	require.NoError(t, err)
+	var support testTaggedPrompt
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &support))
+	assert.Equal(t, []string{"billing", "support"}, support.Tags)
+
+	rec, err = call(http.MethodPost, h.CreatePrompt, "/prompts", "", "", `{"title":"Sales","description":"d","tags":["sales"]}`)
+	require.NoError(t, err)
+	var sales testTaggedPrompt
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sales))
+
+	_, err = call(http.MethodPost, h.CreatePrompt, "/prompts", "", "", `{"title":"Bad","description":"d","tags":["no spaces"]}`)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+
+	// Prompts can be filtered by tag
+	assert.Equal(t, []string{support.ID}, listIDs("tag=billing"))
+	assert.Equal(t, []string{sales.ID}, listIDs("tag=Sales"))
+	assert.Empty(t, listIDs("tag=unknown"))
+
+	// Omitting tags on update keeps them, an empty list clears them
+	_, err = call(http.MethodPut, h.UpdatePrompt, "/prompts/"+sales.ID, "id", sales.ID, `{"title":"Sales v2","description":"d"}`)
+	require.NoError(t, err)
+	rec, err = call(http.MethodGet, h.GetPrompt, "/prompts/"+sales.ID, "id", sales.ID, "")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sales))
+	assert.Equal(t, []string{"sales"}, sales.Tags)
+
+	rec, err = call(http.MethodPut, h.UpdatePrompt, "/prompts/"+sales.ID, "id", sales.ID, `{"title":"Sales v2","description":"d","tags":[]}`)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sales))
+	assert.Equal(t, []string{}, sales.Tags)
+
+	// Tag CRUD
+	_, err = call(http.MethodPost, h.CreateTag, "/tags", "", "", `{"name":"billing"}`)
+	assert.Equal(t, http.StatusConflict, err.(*echo.HTTPError).Code)
+	_, err = call(http.MethodPost, h.CreateTag, "/tags", "", "", `{"name":"draft"}`)
+	require.NoError(t, err)
+
+	rec, err = call(http.MethodGet, h.GetTags, "/tags", "", "", "")
+	require.NoError(t, err)
+	var tags struct {
+		Items []struct {
+			Name        string `json:"name"`
+			PromptCount int64  `json:"prompt_count"`
+		} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tags))
+	require.Len(t, tags.Items, 4)
+	assert.Equal(t, "billing", tags.Items[0].Name)
+	assert.Equal(t, int64(1), tags.Items[0].PromptCount)
+	assert.Equal(t, "draft", tags.Items[1].Name)
+	assert.Equal(t, int64(0), tags.Items[1].PromptCount)
+
+	// Renaming a tag changes the prompts using it
+	_, err = call(http.MethodPut, h.RenameTag, "/tags/billing", "tag", "billing", `{"name":"sales"}`)
+	assert.Equal(t, http.StatusConflict, err.(*echo.HTTPError).Code)
+	_, err = call(http.MethodPut, h.RenameTag, "/tags/billing", "tag", "billing", `{"name":"payments"}`)
+	require.NoError(t, err)
+	rec, err = call(http.MethodGet, h.GetPrompt, "/prompts/"+support.ID, "id", support.ID, "")
+	require.NoError(t, err)
+	var renamed testTaggedPrompt
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &renamed))
+	assert.Equal(t, []string{"payments", "support"}, renamed.Tags)
+	assert.Greater(t, renamed.Revision, support.Revision)
+
+	// Deleting a tag removes it from prompts
+	_, err = call(http.MethodDelete, h.DeleteTag, "/tags/payments", "tag", "payments", "")
+	require.NoError(t, err)
+	_, err = call(http.MethodGet, h.GetTag, "/tags/payments", "tag", "payments", "")
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+	assert.Empty(t, listIDs("tag=payments"))
+	assert.Equal(t, []string{support.ID}, listIDs("tag=support"))
+}
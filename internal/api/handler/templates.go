@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/render"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// versionPayload is a stored version together with its decoded input
+// schema, the template variables detected in its messages and its author
+type versionPayload struct {
+	sqlc.PromptVersion
+	Inputs    []models.Input `json:"inputs"`
+	Variables []string       `json:"variables"`
+	CreatedBy *models.User   `json:"created_by"`
+}
+
+// newVersionPayload wraps a version with its inputs and detected
+// variables. Content that cannot be decoded is reported without them.
+func newVersionPayload(version sqlc.PromptVersion) versionPayload {
+	payload := versionPayload{
+		PromptVersion: version,
+		Inputs:        []models.Input{},
+		Variables:     []string{},
+	}
+	if inputs, err := decodeInputs(version.Inputs); err == nil && inputs != nil {
+		payload.Inputs = inputs
+	}
+	if msgs, err := fromDBMessages(version.Content); err == nil {
+		payload.Variables = render.Variables(msgs)
+	}
+	return payload
+}
+
+// encodeInputs converts an input schema to JSON for storage
+func encodeInputs(inputs []models.Input) string {
+	if inputs == nil {
+		inputs = []models.Input{}
+	}
+	inputsJSON, _ := json.Marshal(inputs)
+	return string(inputsJSON)
+}
+
+// decodeInputs converts a stored input schema back into models.Input. A
+// version without declared inputs yields nil.
+func decodeInputs(content string) ([]models.Input, error) {
+	var inputs []models.Input
+	if content == "" {
+		return nil, nil
+	}
+	if err := json.Unmarshal([]byte(content), &inputs); err != nil {
+		return nil, err
+	}
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+	return inputs, nil
+}
+
+// validateSchema checks a requested input schema against its messages.
+// Versions created without a schema are left untyped.
+func validateSchema(inputs []models.Input, msgs []models.Message) error {
+	if inputs == nil {
+		return nil
+	}
+	if err := render.ValidateSchema(inputs, msgs); err != nil {
+		return validationError("Invalid inputs", err)
+	}
+	return nil
+}
+
+// renderMessages substitutes variables into messages. When the messages
+// come from a version with declared inputs, the variables are validated
+// against them and defaults are applied first.
+func renderMessages(inputs []models.Input, msgs []models.Message, vars map[string]interface{}) ([]models.Message, error) {
+	if inputs != nil {
+		resolved, err := render.Resolve(inputs, vars)
+		if err != nil {
+			return nil, validationError("Invalid variables", err)
+		}
+
+		// Declared inputs that no message uses are not passed on
+		vars = make(map[string]interface{})
+		for _, name := range render.Variables(msgs) {
+			vars[name] = resolved[name]
+		}
+	}
+
+	rendered, err := render.Render(msgs, vars)
+	if err != nil {
+		return nil, validationError("Invalid variables", err)
+	}
+	return rendered, nil
+}
+
+// validationError turns template and schema errors into a 400 response
+// listing every rejected field
+func validationError(message string, err error) error {
+	var schemaErr *render.ValidationError
+	var renderErr *render.Error
+	switch {
+	case errors.As(err, &schemaErr):
+		return echo.NewHTTPError(http.StatusBadRequest, models.ValidationErrorResponse{Message: message, Fields: schemaErr.Fields})
+	case errors.As(err, &renderErr):
+		return echo.NewHTTPError(http.StatusBadRequest, models.ValidationErrorResponse{Message: message, Fields: renderErr.FieldErrors()})
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
+
+// RenderVersion substitutes the given variables into a version's messages
+func (h *Handler) RenderVersion(c echo.Context) error {
+	promptID := c.Param("id")
+	versionStr := c.Param("version")
+
+	var req models.RenderRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	// Parse version number
+	versionNum, err := strconv.ParseInt(versionStr, 10, 64)
+	if err != nil || versionNum <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid version number")
+	}
+
+	version, err := h.findVersion(c.Request().Context(), promptID, versionNum)
+	if err != nil {
+		return err
+	}
+
+	msgs, err := fromDBMessages(version.Content)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to decode version messages: "+err.Error())
+	}
+
+	inputs, err := decodeInputs(version.Inputs)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to decode version inputs: "+err.Error())
+	}
+
+	rendered, err := renderMessages(inputs, msgs, req.Variables)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.RenderResponse{Messages: rendered})
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderVersion(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	// Create a test prompt and templated version
+	_, err := store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{ID: "test-prompt", Title: "Test Prompt"})
+	require.NoError(t, err)
+	_, err = store.CreateVersion(context.Background(), sqlc.CreateVersionParams{
+		ID:       "test-version",
+		PromptID: sql.NullString{String: "test-prompt", Valid: true},
+		Version:  1,
+		Content:  `[{"role":"system","content":"You work at {{company}}."},{"role":"user","content":"{{ question }}"}]`,
+	})
+	require.NoError(t, err)
+
+	// Test GET /prompts/:id/versions/:version exposes the variables
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/prompts/:id/versions/:version")
+	c.SetParamNames("id", "version")
+	c.SetParamValues("test-prompt", "1")
+	require.NoError(t, h.GetVersion(c))
+
+	var version struct {
+		ID        string   `json:"id"`
+		Variables []string `json:"variables"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &version))
+	assert.Equal(t, "test-version", version.ID)
+	assert.Equal(t, []string{"company", "question"}, version.Variables)
+
+	renderVersion := func(body string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/prompts/:id/versions/:version/render")
+		c.SetParamNames("id", "version")
+		c.SetParamValues("test-prompt", "1")
+		return rec, h.RenderVersion(c)
+	}
+
+	// Test POST /prompts/:id/versions/:version/render
+	rec, err = renderVersion(`{"variables":{"company":"Acme","question":"Refunds?"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var rendered models.RenderResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rendered))
+	assert.Equal(t, []models.Message{
+		{Role: models.SystemRole, Content: "You work at Acme."},
+		{Role: models.UserRole, Content: "Refunds?"},
+	}, rendered.Messages)
+
+	// Missing and unknown variables are rejected
+	_, err = renderVersion(`{"variables":{"company":"Acme","tone":"dry"}}`)
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	assert.Equal(t, models.ValidationErrorResponse{
+		Message: "Invalid variables",
+		Fields: []models.FieldError{
+			{Field: "variables.question", Message: "is required"},
+			{Field: "variables.tone", Message: "is not used by any message"},
+		},
+	}, httpErr.Message)
+}
+
+func TestVersionInputSchema(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	_, err := store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{ID: "test-prompt", Title: "Test Prompt"})
+	require.NoError(t, err)
+
+	createVersion := func(body string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/prompts/:id/versions")
+		c.SetParamNames("id")
+		c.SetParamValues("test-prompt")
+		return rec, h.CreateVersion(c)
+	}
+
+	// Templates that use undeclared variables are rejected
+	_, err = createVersion(`{
+		"messages": [{"role": "user", "content": "Reply in {{language}} to {{question}}"}],
+		"inputs": [{"name": "question", "type": "string", "required": true}]
+	}`)
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	assert.Equal(t, []models.FieldError{
+		{Field: "inputs", Message: `variable "language" is used in messages but not declared`},
+	}, httpErr.Message.(models.ValidationErrorResponse).Fields)
+
+	// A fully declared schema is stored with the version
+	rec, err := createVersion(`{
+		"messages": [{"role": "user", "content": "Reply in {{language}} to {{question}} using at most {{max_words}} words"}],
+		"inputs": [
+			{"name": "question", "type": "string", "required": true, "description": "The customer question"},
+			{"name": "language", "type": "string", "default": "English", "enum": ["English", "Spanish"]},
+			{"name": "max_words", "type": "integer", "default": 50}
+		]
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var version struct {
+		Version int64          `json:"version"`
+		Inputs  []models.Input `json:"inputs"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &version))
+	require.Len(t, version.Inputs, 3)
+	assert.Equal(t, "The customer question", version.Inputs[0].Description)
+
+	renderVersion := func(body string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/prompts/:id/versions/:version/render")
+		c.SetParamNames("id", "version")
+		c.SetParamValues("test-prompt", fmt.Sprint(version.Version))
+		return rec, h.RenderVersion(c)
+	}
+
+	// Defaults fill in optional inputs
+	rec, err = renderVersion(`{"variables": {"question": "Where is my order?"}}`)
+	require.NoError(t, err)
+	var rendered models.RenderResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rendered))
+	assert.Equal(t, "Reply in English to Where is my order? using at most 50 words", rendered.Messages[0].Content)
+
+	// Every invalid field is reported
+	_, err = renderVersion(`{"variables": {"language": "French", "max_words": 2.5, "tone": "dry"}}`)
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	assert.Equal(t, []models.FieldError{
+		{Field: "variables.question", Message: "is required"},
+		{Field: "variables.language", Message: "must be one of English, Spanish"},
+		{Field: "variables.max_words", Message: "must be an integer"},
+		{Field: "variables.tone", Message: "is not a declared input"},
+	}, httpErr.Message.(models.ValidationErrorResponse).Fields)
+
+	// Runs of the version are validated the same way
+	req := httptest.NewRequest(http.MethodPost, "/run", strings.NewReader(`{"prompt_id":"test-prompt","model":"fake/echo","variables":{"max_words":"ten"}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	err = h.RunPrompt(e.NewContext(req, httptest.NewRecorder()))
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, []models.FieldError{
+		{Field: "variables.question", Message: "is required"},
+		{Field: "variables.max_words", Message: "must be an integer"},
+	}, httpErr.Message.(models.ValidationErrorResponse).Fields)
+}
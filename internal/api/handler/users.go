@@ -0,0 +1,397 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/auth"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// newUser converts a stored user
+func newUser(user sqlc.User) models.User {
+	return models.User{ID: user.ID, Name: user.Name, Email: user.Email, CreatedAt: user.CreatedAt.Time}
+}
+
+// author returns the user a record created by the request is attributed
+// to: the authenticated caller, who is recorded in the users table on
+// first sight. A request body may only name the caller as its author, and
+// anonymous callers create records without one.
+func (h *Handler) author(c echo.Context, claimed models.User) (models.User, error) {
+	user, ok := auth.UserFromContext(c.Request().Context())
+	if claimed.ID != "" && (!ok || claimed.ID != user.ID) {
+		return models.User{}, echo.NewHTTPError(http.StatusForbidden, "Records can only be created as the authenticated user")
+	}
+	if !ok {
+		return models.User{}, nil
+	}
+
+	err := h.Store.EnsureUser(c.Request().Context(), sqlc.EnsureUserParams{ID: user.ID, Name: user.Name, Email: user.Email})
+	if err != nil {
+		return models.User{}, echo.NewHTTPError(http.StatusInternalServerError, "Failed to record user: "+err.Error())
+	}
+	return user, nil
+}
+
+// authors resolves the created_by, updated_by and moved_by IDs of records
+// to users, looking each user up once
+type authors struct {
+	store *db.Store
+	users map[string]*models.User
+}
+
+func (h *Handler) newAuthors() *authors {
+	return &authors{store: h.Store, users: make(map[string]*models.User)}
+}
+
+// lookup returns the user with the given ID, or nil for records without an
+// author. IDs with no stored user resolve to a user with only an ID.
+func (a *authors) lookup(ctx context.Context, id sql.NullString) (*models.User, error) {
+	if !id.Valid || id.String == "" {
+		return nil, nil
+	}
+	if user, ok := a.users[id.String]; ok {
+		return user, nil
+	}
+
+	user := &models.User{ID: id.String}
+	stored, err := a.store.GetUser(ctx, id.String)
+	if err == nil {
+		*user = newUser(stored)
+	} else if err != sql.ErrNoRows {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch user: "+err.Error())
+	}
+	a.users[id.String] = user
+	return user, nil
+}
+
+// authoredPrompt is a stored prompt with its author
+type authoredPrompt struct {
+	sqlc.Prompt
+	CreatedBy *models.User `json:"created_by"`
+}
+
+func (a *authors) prompts(ctx context.Context, prompts []sqlc.Prompt) ([]authoredPrompt, error) {
+	payloads := make([]authoredPrompt, len(prompts))
+	for i, prompt := range prompts {
+		user, err := a.lookup(ctx, prompt.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+		payloads[i] = authoredPrompt{Prompt: prompt, CreatedBy: user}
+	}
+	return payloads, nil
+}
+
+func (a *authors) prompt(ctx context.Context, prompt sqlc.Prompt) (authoredPrompt, error) {
+	payloads, err := a.prompts(ctx, []sqlc.Prompt{prompt})
+	if err != nil {
+		return authoredPrompt{}, err
+	}
+	return payloads[0], nil
+}
+
+// versions wraps versions as payloads with their authors
+func (a *authors) versions(ctx context.Context, versions []sqlc.PromptVersion) ([]versionPayload, error) {
+	payloads := make([]versionPayload, len(versions))
+	for i, version := range versions {
+		payloads[i] = newVersionPayload(version)
+		user, err := a.lookup(ctx, version.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+		payloads[i].CreatedBy = user
+	}
+	return payloads, nil
+}
+
+func (a *authors) version(ctx context.Context, version sqlc.PromptVersion) (versionPayload, error) {
+	payloads, err := a.versions(ctx, []sqlc.PromptVersion{version})
+	if err != nil {
+		return versionPayload{}, err
+	}
+	return payloads[0], nil
+}
+
+// authoredComment is a stored comment with its author
+type authoredComment struct {
+	sqlc.Comment
+	CreatedBy *models.User `json:"created_by"`
+}
+
+func (a *authors) comments(ctx context.Context, comments []sqlc.Comment) ([]authoredComment, error) {
+	payloads := make([]authoredComment, len(comments))
+	for i, comment := range comments {
+		user, err := a.lookup(ctx, comment.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+		payloads[i] = authoredComment{Comment: comment, CreatedBy: user}
+	}
+	return payloads, nil
+}
+
+// authoredEvaluation is a stored evaluation with its author
+type authoredEvaluation struct {
+	sqlc.Evaluation
+	CreatedBy *models.User `json:"created_by"`
+}
+
+func (a *authors) evaluations(ctx context.Context, evals []sqlc.Evaluation) ([]authoredEvaluation, error) {
+	payloads := make([]authoredEvaluation, len(evals))
+	for i, e := range evals {
+		user, err := a.lookup(ctx, e.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+		payloads[i] = authoredEvaluation{Evaluation: e, CreatedBy: user}
+	}
+	return payloads, nil
+}
+
+// authoredLabel is a stored label with the user who last moved it
+type authoredLabel struct {
+	sqlc.PromptLabel
+	UpdatedBy *models.User `json:"updated_by"`
+}
+
+func (a *authors) labels(ctx context.Context, labels []sqlc.PromptLabel) ([]authoredLabel, error) {
+	payloads := make([]authoredLabel, len(labels))
+	for i, label := range labels {
+		user, err := a.lookup(ctx, label.UpdatedBy)
+		if err != nil {
+			return nil, err
+		}
+		payloads[i] = authoredLabel{PromptLabel: label, UpdatedBy: user}
+	}
+	return payloads, nil
+}
+
+func (a *authors) label(ctx context.Context, label sqlc.PromptLabel) (authoredLabel, error) {
+	payloads, err := a.labels(ctx, []sqlc.PromptLabel{label})
+	if err != nil {
+		return authoredLabel{}, err
+	}
+	return payloads[0], nil
+}
+
+// authoredLabelEvent is a label history entry with the user who made it
+type authoredLabelEvent struct {
+	sqlc.PromptLabelHistory
+	MovedBy *models.User `json:"moved_by"`
+}
+
+func (a *authors) labelEvents(ctx context.Context, events []sqlc.PromptLabelHistory) ([]authoredLabelEvent, error) {
+	payloads := make([]authoredLabelEvent, len(events))
+	for i, event := range events {
+		user, err := a.lookup(ctx, event.MovedBy)
+		if err != nil {
+			return nil, err
+		}
+		payloads[i] = authoredLabelEvent{PromptLabelHistory: event, MovedBy: user}
+	}
+	return payloads, nil
+}
+
+// bindUser reads and validates a user request body
+func bindUser(c echo.Context) (models.UserRequest, error) {
+	var req models.UserRequest
+	if err := c.Bind(&req); err != nil {
+		return req, echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	// Validate required fields
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return req, echo.NewHTTPError(http.StatusBadRequest, "Name is required")
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	if addr, err := mail.ParseAddress(req.Email); err != nil || addr.Address != req.Email {
+		return req, echo.NewHTTPError(http.StatusBadRequest, "Email must be a valid address such as name@example.com")
+	}
+	return req, nil
+}
+
+// findUser loads the user named by the :user path parameter. Only members
+// of the caller's workspace can be looked up.
+func (h *Handler) findUser(c echo.Context) (sqlc.User, error) {
+	user, err := h.Store.GetWorkspaceUser(c.Request().Context(), c.Param("user"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return user, echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		return user, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch user: "+err.Error())
+	}
+	return user, nil
+}
+
+// findSelf loads the user named by the :user path parameter, rejecting
+// requests that change a user other than the caller
+func (h *Handler) findSelf(c echo.Context) (sqlc.User, error) {
+	caller, err := currentUser(c)
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	if caller.ID != c.Param("user") {
+		return sqlc.User{}, echo.NewHTTPError(http.StatusForbidden, "Users can only be changed by themselves")
+	}
+
+	user, err := h.Store.GetUser(c.Request().Context(), caller.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return user, echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		return user, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch user: "+err.Error())
+	}
+	return user, nil
+}
+
+// GetMe returns the authenticated caller
+func (h *Handler) GetMe(c echo.Context) error {
+	caller, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.Store.GetUser(c.Request().Context(), caller.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch user: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, newUser(user))
+}
+
+// GetUsers returns the members of the caller's workspace ordered by name
+func (h *Handler) GetUsers(c echo.Context) error {
+	users, err := h.Store.ListWorkspaceUsers(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch users: "+err.Error())
+	}
+
+	payloads := make([]models.User, len(users))
+	for i, user := range users {
+		payloads[i] = newUser(user)
+	}
+	return c.JSON(http.StatusOK, models.ListResponse{Items: payloads})
+}
+
+// CreateUser adds a user to the caller's workspace. Only owners of the
+// workspace can add users.
+func (h *Handler) CreateUser(c echo.Context) error {
+	caller, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	member, err := h.Store.GetWorkspaceMember(ctx, sqlc.GetWorkspaceMemberParams{
+		WorkspaceID: db.WorkspaceFromContext(ctx),
+		UserID:      caller.ID,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch workspace: "+err.Error())
+	}
+	if err == sql.ErrNoRows || member.Role != db.RoleOwner {
+		return echo.NewHTTPError(http.StatusForbidden, "Only workspace owners can add users")
+	}
+
+	req, err := bindUser(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.Store.CreateWorkspaceUser(ctx, sqlc.CreateUserParams{
+		ID:    uuid.New().String(),
+		Name:  req.Name,
+		Email: req.Email,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrEmailTaken) {
+			return echo.NewHTTPError(http.StatusConflict, "A user with this email already exists")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create user: "+err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, newUser(user))
+}
+
+// GetUser returns a member of the caller's workspace by ID
+func (h *Handler) GetUser(c echo.Context) error {
+	user, err := h.findUser(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, newUser(user))
+}
+
+// UpdateUser changes the caller's name and email
+func (h *Handler) UpdateUser(c echo.Context) error {
+	user, err := h.findSelf(c)
+	if err != nil {
+		return err
+	}
+	req, err := bindUser(c)
+	if err != nil {
+		return err
+	}
+
+	user, err = h.Store.UpdateUser(c.Request().Context(), sqlc.UpdateUserParams{
+		ID:    user.ID,
+		Name:  req.Name,
+		Email: req.Email,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrEmailTaken) {
+			return echo.NewHTTPError(http.StatusConflict, "A user with this email already exists")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update user: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, newUser(user))
+}
+
+// DeleteUser deletes the caller along with their API keys. Their prompts
+// and other records are kept.
+func (h *Handler) DeleteUser(c echo.Context) error {
+	user, err := h.findSelf(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Store.DeleteUser(c.Request().Context(), user.ID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete user: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted", "id": user.ID})
+}
+
+// GetUserPrompts returns the live prompts a user created, newest first
+func (h *Handler) GetUserPrompts(c echo.Context) error {
+	user, err := h.findUser(c)
+	if err != nil {
+		return err
+	}
+
+	prompts, err := h.Store.ListPromptsByUser(c.Request().Context(), sql.NullString{String: user.ID, Valid: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch prompts: "+err.Error())
+	}
+
+	payloads, err := h.newAuthors().prompts(c.Request().Context(), prompts)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, models.ListResponse{Items: payloads})
+}
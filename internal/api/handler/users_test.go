@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/internal/auth"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withUser authenticates a request as the user with the given ID. An empty
+// ID leaves the request anonymous.
+func withUser(req *http.Request, id string) *http.Request {
+	if id == "" {
+		return req
+	}
+	user := models.User{ID: id, Name: strings.ToUpper(id[:1]) + id[1:], Email: id + "@example.com"}
+	return req.WithContext(auth.WithUser(req.Context(), user))
+}
+
+func TestAuthorFromCaller(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	post := func(fn echo.HandlerFunc, user, body string, params ...string) (*httptest.ResponseRecorder, error) {
+		req := withUser(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)), user)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if len(params) > 0 {
+			c.SetParamNames("id", "version")
+			c.SetParamValues(params...)
+		}
+		return rec, fn(c)
+	}
+
+	// Records are stamped with the caller, who is added to users on first
+	// sight, and embed the full user
+	rec, err := post(h.CreatePrompt, "alice", `{"title":"Greeting","description":"Says hi","messages":[{"role":"user","content":"Hi"}]}`)
+	require.NoError(t, err)
+	var prompt testPrompt
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &prompt))
+	require.NotNil(t, prompt.CreatedBy)
+	assert.Equal(t, models.User{ID: "alice", Name: "Alice", Email: "alice@example.com"}, *prompt.CreatedBy)
+
+	user, err := store.GetUser(context.Background(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", user.Email)
+	version, err := store.GetVersionByPromptAndNumber(context.Background(), sqlc.GetVersionByPromptAndNumberParams{
+		PromptID: sql.NullString{String: prompt.ID, Valid: true},
+		Version:  1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", version.CreatedBy.String)
+
+	rec, err = post(h.AddComment, "bob", `{"content":"Nice","created_by":{"id":"bob"}}`, prompt.ID)
+	require.NoError(t, err)
+	var comment testComment
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &comment))
+	assert.Equal(t, "Bob", comment.CreatedBy.Name)
+
+	rec, err = post(h.CreateEvaluation, "bob", `{"score":0.5}`, prompt.ID, "1")
+	require.NoError(t, err)
+	var evaluation testEval
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &evaluation))
+	assert.Equal(t, "bob", evaluation.CreatedBy.ID)
+
+	// Bodies cannot write as someone else, and anonymous records have no
+	// author
+	_, err = post(h.CreateVersion, "bob", `{"messages":[{"role":"user","content":"Hey"}],"created_by":{"id":"alice"}}`, prompt.ID)
+	assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	_, err = post(h.AddComment, "", `{"content":"Hi","created_by":{"id":"alice"}}`, prompt.ID)
+	assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+
+	rec, err = post(h.AddComment, "", `{"content":"Hi"}`, prompt.ID)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &comment))
+	assert.Nil(t, comment.CreatedBy)
+
+	// Listings embed authors too; IDs without a stored user keep just the ID
+	_, err = store.CreateComment(context.Background(), sqlc.CreateCommentParams{
+		ID:        "legacy-comment",
+		PromptID:  sql.NullString{String: prompt.ID, Valid: true},
+		Content:   "Old",
+		CreatedBy: sql.NullString{String: "legacy-user", Valid: true},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/?sort=created_at&order=asc", nil)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(prompt.ID)
+	require.NoError(t, h.GetComments(c))
+	var comments struct {
+		Items []testComment `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &comments))
+	authors := map[string]*models.User{}
+	for _, comment := range comments.Items {
+		authors[comment.Content] = comment.CreatedBy
+	}
+	assert.Equal(t, "bob@example.com", authors["Nice"].Email)
+	assert.Nil(t, authors["Hi"])
+	assert.Equal(t, &models.User{ID: "legacy-user"}, authors["Old"])
+}
+
+func TestUsers(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	call := func(method string, fn echo.HandlerFunc, caller, user, body string) (*httptest.ResponseRecorder, error) {
+		req := withUser(httptest.NewRequest(method, "/", strings.NewReader(body)), caller)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("user")
+		c.SetParamValues(user)
+		return rec, fn(c)
+	}
+
+	// Only members of the caller's workspace are listed and looked up
+	bg := context.Background()
+	for _, id := range []string{"ana", "bo", "cy"} {
+		_, err := store.CreateUser(bg, sqlc.CreateUserParams{ID: id, Name: strings.ToUpper(id[:1]) + id[1:], Email: id + "@example.com"})
+		require.NoError(t, err)
+	}
+	for id, role := range map[string]string{"ana": db.RoleOwner, "bo": db.RoleMember} {
+		_, err := store.SetWorkspaceMember(bg, sqlc.UpsertWorkspaceMemberParams{WorkspaceID: db.DefaultWorkspaceID, UserID: id, Role: role})
+		require.NoError(t, err)
+	}
+	_, err := store.CreateWorkspace(bg, sqlc.CreateWorkspaceParams{ID: "team-cy", Name: "Cy"}, "cy")
+	require.NoError(t, err)
+
+	rec, err := call(http.MethodGet, h.GetUsers, "", "", "")
+	require.NoError(t, err)
+	var users struct {
+		Items []models.User `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &users))
+	require.Len(t, users.Items, 2)
+	assert.Equal(t, "Ana", users.Items[0].Name)
+	assert.Equal(t, "Bo", users.Items[1].Name)
+
+	rec, err = call(http.MethodGet, h.GetUser, "", "ana", "")
+	require.NoError(t, err)
+	var ana models.User
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &ana))
+	assert.Equal(t, "ana@example.com", ana.Email)
+	for _, id := range []string{"cy", "missing"} {
+		_, err = call(http.MethodGet, h.GetUser, "", id, "")
+		assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code, id)
+	}
+
+	// Only owners add users, who join their workspace
+	_, err = call(http.MethodPost, h.CreateUser, "bo", "", `{"name":"Di","email":"di@example.com"}`)
+	assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	_, err = call(http.MethodPost, h.CreateUser, "cy", "", `{"name":"Di","email":"di@example.com"}`)
+	assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code, "cy owns another workspace")
+	for _, body := range []string{`{"name":"Di","email":"not-an-email"}`, `{"email":"di@example.com"}`} {
+		_, err = call(http.MethodPost, h.CreateUser, "ana", "", body)
+		assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code, body)
+	}
+	_, err = call(http.MethodPost, h.CreateUser, "ana", "", `{"name":"Cy","email":"cy@example.com"}`)
+	assert.Equal(t, http.StatusConflict, err.(*echo.HTTPError).Code)
+	rec, err = call(http.MethodPost, h.CreateUser, "ana", "", `{"name":"Di","email":" Di@Example.com "}`)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var di models.User
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &di))
+	assert.Equal(t, "di@example.com", di.Email)
+	_, err = call(http.MethodGet, h.GetUser, "", di.ID, "")
+	require.NoError(t, err)
+
+	// Test GET /me
+	rec, err = call(http.MethodGet, h.GetMe, "bo", "", "")
+	require.NoError(t, err)
+	var me models.User
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &me))
+	assert.Equal(t, "bo@example.com", me.Email)
+	_, err = call(http.MethodGet, h.GetMe, "", "", "")
+	assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+
+	// Users can only change themselves
+	_, err = call(http.MethodPut, h.UpdateUser, "bo", ana.ID, `{"name":"Ana B","email":"ana@example.com"}`)
+	assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	for _, body := range []string{
+		`{"name":"Bo","email":"not-an-email"}`,
+		`{"name":"Bo","email":"Bo <bo@example.com>"}`,
+		`{"name":"Bo"}`,
+		`{"email":"bo@example.com"}`,
+	} {
+		_, err = call(http.MethodPut, h.UpdateUser, "bo", "bo", body)
+		assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code, body)
+	}
+	_, err = call(http.MethodPut, h.UpdateUser, "bo", "bo", `{"name":"Bo","email":"ana@example.com"}`)
+	assert.Equal(t, http.StatusConflict, err.(*echo.HTTPError).Code)
+	rec, err = call(http.MethodPut, h.UpdateUser, "bo", "bo", `{"name":"Bo B","email":" Bo@Example.org "}`)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &me))
+	assert.Equal(t, "Bo B", me.Name)
+	assert.Equal(t, "bo@example.org", me.Email)
+
+	// Test GET /users/:user/prompts
+	for i, author := range []string{"bo", "bo", ana.ID} {
+		_, err = store.CreatePrompt(context.Background(), sqlc.CreatePromptParams{
+			ID:        fmt.Sprintf("prompt-%d", i),
+			Title:     fmt.Sprintf("Prompt %d", i),
+			Slug:      fmt.Sprintf("prompt-%d", i),
+			CreatedBy: sql.NullString{String: author, Valid: true},
+		})
+		require.NoError(t, err)
+	}
+	_, err = store.SoftDeletePrompt(context.Background(), sqlc.SoftDeletePromptParams{ID: "prompt-1"})
+	require.NoError(t, err)
+
+	rec, err = call(http.MethodGet, h.GetUserPrompts, "", "bo", "")
+	require.NoError(t, err)
+	var prompts struct {
+		Items []testPrompt `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &prompts))
+	require.Len(t, prompts.Items, 1)
+	assert.Equal(t, "prompt-0", prompts.Items[0].ID)
+	assert.Equal(t, "Bo B", prompts.Items[0].CreatedBy.Name)
+
+	// Deleting a user removes their keys but keeps their prompts
+	_, secret, err := store.IssueAPIKey(context.Background(), "bo", "laptop")
+	require.NoError(t, err)
+	_, err = call(http.MethodDelete, h.DeleteUser, ana.ID, "bo", "")
+	assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	_, err = call(http.MethodDelete, h.DeleteUser, "bo", "bo", "")
+	require.NoError(t, err)
+
+	_, err = store.GetAPIKeyUser(context.Background(), auth.HashKey(secret))
+	assert.Equal(t, sql.ErrNoRows, err)
+	prompt, err := store.GetPrompt(context.Background(), "prompt-0")
+	require.NoError(t, err)
+	assert.Equal(t, "bo", prompt.CreatedBy.String)
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// membership returns the caller's membership of the :workspace path
+// parameter. Workspaces the caller doesn't belong to are reported as not
+// found.
+func (h *Handler) membership(c echo.Context) (sqlc.WorkspaceMember, error) {
+	user, err := currentUser(c)
+	if err != nil {
+		return sqlc.WorkspaceMember{}, err
+	}
+
+	member, err := h.Store.GetWorkspaceMember(c.Request().Context(), sqlc.GetWorkspaceMemberParams{
+		WorkspaceID: c.Param("workspace"),
+		UserID:      user.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return member, echo.NewHTTPError(http.StatusNotFound, "Workspace not found")
+		}
+		return member, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch workspace: "+err.Error())
+	}
+	return member, nil
+}
+
+// GetWorkspaces returns the workspaces the caller belongs to
+func (h *Handler) GetWorkspaces(c echo.Context) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	workspaces, err := h.Store.ListWorkspacesByUser(c.Request().Context(), user.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch workspaces: "+err.Error())
+	}
+
+	payloads := make([]models.Workspace, len(workspaces))
+	for i, w := range workspaces {
+		payloads[i] = models.Workspace{ID: w.ID, Name: w.Name, Role: w.Role, CreatedAt: w.CreatedAt.Time}
+	}
+	return c.JSON(http.StatusOK, models.ListResponse{Items: payloads})
+}
+
+// CreateWorkspace creates a workspace owned by the caller
+func (h *Handler) CreateWorkspace(c echo.Context) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	var req models.WorkspaceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Name is required")
+	}
+
+	workspace, err := h.Store.CreateWorkspace(c.Request().Context(), sqlc.CreateWorkspaceParams{
+		ID:   uuid.New().String(),
+		Name: req.Name,
+	}, user.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create workspace: "+err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, models.Workspace{
+		ID:        workspace.ID,
+		Name:      workspace.Name,
+		Role:      db.RoleOwner,
+		CreatedAt: workspace.CreatedAt.Time,
+	})
+}
+
+// GetWorkspaceMembers returns the members of a workspace the caller
+// belongs to
+func (h *Handler) GetWorkspaceMembers(c echo.Context) error {
+	member, err := h.membership(c)
+	if err != nil {
+		return err
+	}
+
+	members, err := h.Store.ListWorkspaceMembers(c.Request().Context(), member.WorkspaceID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch members: "+err.Error())
+	}
+
+	payloads := make([]models.WorkspaceMember, len(members))
+	for i, m := range members {
+		payloads[i] = models.WorkspaceMember{ID: m.ID, Name: m.Name, Email: m.Email, Role: m.Role, JoinedAt: m.CreatedAt.Time}
+	}
+	return c.JSON(http.StatusOK, models.ListResponse{Items: payloads})
+}
+
+// SetWorkspaceMember adds a user to a workspace or changes their role.
+// Only owners manage members, and a workspace always keeps an owner.
+func (h *Handler) SetWorkspaceMember(c echo.Context) error {
+	member, err := h.membership(c)
+	if err != nil {
+		return err
+	}
+	if member.Role != db.RoleOwner {
+		return echo.NewHTTPError(http.StatusForbidden, "Only workspace owners can manage members")
+	}
+
+	var req models.WorkspaceMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+	if req.Role == "" {
+		req.Role = db.RoleMember
+	}
+	if req.Role != db.RoleOwner && req.Role != db.RoleMember {
+		return echo.NewHTTPError(http.StatusBadRequest, "Role must be owner or member")
+	}
+
+	// Owners add users by ID, so this looks beyond the workspace's members
+	user, err := h.Store.GetUser(c.Request().Context(), c.Param("user"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch user: "+err.Error())
+	}
+
+	updated, err := h.Store.SetWorkspaceMember(c.Request().Context(), sqlc.UpsertWorkspaceMemberParams{
+		WorkspaceID: member.WorkspaceID,
+		UserID:      user.ID,
+		Role:        req.Role,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrLastOwner) {
+			return echo.NewHTTPError(http.StatusConflict, "A workspace must keep at least one owner")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update member: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, models.WorkspaceMember{
+		ID:       user.ID,
+		Name:     user.Name,
+		Email:    user.Email,
+		Role:     updated.Role,
+		JoinedAt: updated.CreatedAt.Time,
+	})
+}
+
+// RemoveWorkspaceMember removes a user from a workspace. Owners can remove
+// anyone and members can leave; a workspace always keeps an owner.
+func (h *Handler) RemoveWorkspaceMember(c echo.Context) error {
+	member, err := h.membership(c)
+	if err != nil {
+		return err
+	}
+	userID := c.Param("user")
+	if member.Role != db.RoleOwner && member.UserID != userID {
+		return echo.NewHTTPError(http.StatusForbidden, "Only workspace owners can manage members")
+	}
+
+	err = h.Store.RemoveWorkspaceMember(c.Request().Context(), member.WorkspaceID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return echo.NewHTTPError(http.StatusNotFound, "Member not found")
+		}
+		if errors.Is(err, db.ErrLastOwner) {
+			return echo.NewHTTPError(http.StatusConflict, "A workspace must keep at least one owner")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to remove member: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "removed", "id": userID})
+}
@@ -0,0 +1,400 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// join adds a user to a workspace, creating the workspace if needed
+func join(t *testing.T, store *db.Store, workspaceID, userID, role string) {
+	_, err := store.GetWorkspace(context.Background(), workspaceID)
+	if err == sql.ErrNoRows {
+		_, err = store.CreateWorkspace(context.Background(), sqlc.CreateWorkspaceParams{ID: workspaceID, Name: workspaceID}, userID)
+	}
+	require.NoError(t, err)
+	_, err = store.SetWorkspaceMember(context.Background(), sqlc.UpsertWorkspaceMemberParams{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Role:        role,
+	})
+	require.NoError(t, err)
+}
+
+func TestWorkspaceIsolation(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	keys := map[string]string{}
+	for _, id := range []string{"ana", "bo"} {
+		_, err := store.CreateUser(context.Background(), sqlc.CreateUserParams{ID: id, Name: id, Email: id + "@example.com"})
+		require.NoError(t, err)
+		join(t, store, "team-"+id, id, db.RoleOwner)
+		_, keys[id], err = store.IssueAPIKey(db.WithWorkspace(context.Background(), "team-"+id), id, "laptop")
+		require.NoError(t, err)
+	}
+
+	// Ana's team has a prompt with a version, a comment and an evaluation
+	teamA := db.WithWorkspace(context.Background(), "team-ana")
+	_, err := store.CreatePrompt(teamA, sqlc.CreatePromptParams{ID: "prompt-a", Title: "Launch plan", Slug: "launch"})
+	require.NoError(t, err)
+	_, err = store.CreateVersion(teamA, sqlc.CreateVersionParams{
+		ID:       "version-a",
+		PromptID: sql.NullString{String: "prompt-a", Valid: true},
+		Version:  1,
+		Content:  `[{"role":"user","content":"Plan the launch"}]`,
+	})
+	require.NoError(t, err)
+	_, err = store.CreateComment(teamA, sqlc.CreateCommentParams{ID: "comment-a", PromptID: sql.NullString{String: "prompt-a", Valid: true}, Content: "Ship it"})
+	require.NoError(t, err)
+	_, err = store.CreateEvaluation(teamA, sqlc.CreateEvaluationParams{
+		ID:              "eval-a",
+		PromptVersionID: sql.NullString{String: "version-a", Valid: true},
+		Score:           sql.NullFloat64{Float64: 0.9, Valid: true},
+		Scorer:          "manual",
+	})
+	require.NoError(t, err)
+
+	// call runs a handler for a prompt route as the holder of key
+	call := func(key, method, body string, next echo.HandlerFunc, params ...string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(method, "/?q=launch", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+key)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		names := []string{"id", "version"}
+		c.SetParamNames(names[:len(params)]...)
+		c.SetParamValues(params...)
+		return rec, h.Authenticate(true)(h.ResolvePrompt(next))(c)
+	}
+	var list struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	count := func(rec *httptest.ResponseRecorder) int {
+		list.Items = nil
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+		return len(list.Items)
+	}
+
+	// Ana's team sees everything
+	rec, err := call(keys["ana"], http.MethodGet, "", h.GetPrompts)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count(rec))
+	rec, err = call(keys["ana"], http.MethodGet, "", h.GetComments, "launch")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count(rec))
+	rec, err = call(keys["ana"], http.MethodGet, "", h.GetEvaluations, "launch", "1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count(rec))
+
+	// Bo's team sees nothing of it, by ID or by slug
+	rec, err = call(keys["bo"], http.MethodGet, "", h.GetPrompts)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count(rec))
+	rec, err = call(keys["bo"], http.MethodGet, "", h.SearchPrompts)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count(rec))
+	for _, ref := range []string{"prompt-a", "launch"} {
+		for name, fn := range map[string]echo.HandlerFunc{
+			"prompt":   h.GetPrompt,
+			"versions": h.GetVersions,
+			"comments": h.GetComments,
+			"delete":   h.DeletePrompt,
+		} {
+			_, err = call(keys["bo"], http.MethodGet, "", fn, ref)
+			assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code, name+" "+ref)
+		}
+		_, err = call(keys["bo"], http.MethodGet, "", h.GetVersion, ref, "1")
+		assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code, ref)
+		_, err = call(keys["bo"], http.MethodGet, "", h.GetEvaluations, ref, "1")
+		assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code, ref)
+		_, err = call(keys["bo"], http.MethodPost, `{"content":"Leaked?"}`, h.AddComment, ref)
+		assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code, ref)
+	}
+
+	// Slugs only need to be unique within a workspace
+	rec, err = call(keys["bo"], http.MethodPost, `{"title":"Launch plan","description":"Bo's launch","slug":"launch","messages":[{"role":"user","content":"Hi"}]}`, h.CreatePrompt)
+	require.NoError(t, err)
+	var created struct {
+		ID   string `json:"id"`
+		Slug string `json:"slug"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "launch", created.Slug)
+	assert.NotEqual(t, "prompt-a", created.ID)
+	rec, err = call(keys["ana"], http.MethodGet, "", h.GetPrompt, "launch")
+	require.NoError(t, err)
+	assert.Contains(t, rec.Body.String(), `"id":"prompt-a"`)
+
+	// Nothing of Ana's changed
+	comments, err := store.ListComments(teamA, sql.NullString{String: "prompt-a", Valid: true})
+	require.NoError(t, err)
+	assert.Len(t, comments, 1)
+	prompt, err := store.GetPrompt(teamA, "prompt-a")
+	require.NoError(t, err)
+	assert.False(t, prompt.DeletedAt.Valid)
+}
+
+func TestWorkspaces(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	for _, id := range []string{"ana", "bo", "cy"} {
+		_, err := store.CreateUser(context.Background(), sqlc.CreateUserParams{ID: id, Name: id, Email: id + "@example.com"})
+		require.NoError(t, err)
+	}
+
+	call := func(caller, method, body string, fn echo.HandlerFunc, params ...string) (*httptest.ResponseRecorder, error) {
+		req := withUser(httptest.NewRequest(method, "/", strings.NewReader(body)), caller)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		names := []string{"workspace", "user"}
+		c.SetParamNames(names[:len(params)]...)
+		c.SetParamValues(params...)
+		return rec, fn(c)
+	}
+
+	// The creator owns a new workspace
+	rec, err := call("ana", http.MethodPost, `{"name":"Research"}`, h.CreateWorkspace)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var workspace models.Workspace
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &workspace))
+	assert.Equal(t, db.RoleOwner, workspace.Role)
+	_, err = call("ana", http.MethodPost, `{"name":" "}`, h.CreateWorkspace)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+
+	rec, err = call("ana", http.MethodGet, "", h.GetWorkspaces)
+	require.NoError(t, err)
+	assert.Contains(t, rec.Body.String(), `"name":"Research"`)
+
+	// Outsiders can't see the workspace; only owners manage members
+	_, err = call("bo", http.MethodGet, "", h.GetWorkspaceMembers, workspace.ID)
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+	_, err = call("bo", http.MethodPut, `{"role":"member"}`, h.SetWorkspaceMember, workspace.ID, "bo")
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+
+	_, err = call("ana", http.MethodPut, `{"role":"member"}`, h.SetWorkspaceMember, workspace.ID, "bo")
+	require.NoError(t, err)
+	_, err = call("bo", http.MethodPut, `{"role":"member"}`, h.SetWorkspaceMember, workspace.ID, "cy")
+	assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	_, err = call("ana", http.MethodPut, `{"role":"admin"}`, h.SetWorkspaceMember, workspace.ID, "cy")
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	_, err = call("ana", http.MethodPut, `{"role":"member"}`, h.SetWorkspaceMember, workspace.ID, "missing")
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+
+	rec, err = call("bo", http.MethodGet, "", h.GetWorkspaceMembers, workspace.ID)
+	require.NoError(t, err)
+	var members struct {
+		Items []models.WorkspaceMember `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &members))
+	require.Len(t, members.Items, 2)
+	assert.Equal(t, "ana", members.Items[0].ID)
+	assert.Equal(t, db.RoleOwner, members.Items[0].Role)
+	assert.Equal(t, db.RoleMember, members.Items[1].Role)
+
+	// A workspace always keeps an owner
+	_, err = call("ana", http.MethodPut, `{"role":"member"}`, h.SetWorkspaceMember, workspace.ID, "ana")
+	assert.Equal(t, http.StatusConflict, err.(*echo.HTTPError).Code)
+	_, err = call("ana", http.MethodDelete, "", h.RemoveWorkspaceMember, workspace.ID, "ana")
+	assert.Equal(t, http.StatusConflict, err.(*echo.HTTPError).Code)
+
+	// Members can leave but not remove others
+	_, err = call("bo", http.MethodDelete, "", h.RemoveWorkspaceMember, workspace.ID, "ana")
+	assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	_, err = call("bo", http.MethodDelete, "", h.RemoveWorkspaceMember, workspace.ID, "bo")
+	require.NoError(t, err)
+	_, err = call("ana", http.MethodDelete, "", h.RemoveWorkspaceMember, workspace.ID, "bo")
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+
+	// Keys sign in to one workspace and stop working when the user leaves it
+	_, err = call("bo", http.MethodPost, `{"name":"research","workspace_id":"`+workspace.ID+`"}`, h.CreateAPIKey)
+	assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code)
+
+	_, err = call("ana", http.MethodPut, `{"role":"member"}`, h.SetWorkspaceMember, workspace.ID, "cy")
+	require.NoError(t, err)
+	rec, err = call("cy", http.MethodPost, `{"name":"research","workspace_id":"`+workspace.ID+`"}`, h.CreateAPIKey)
+	require.NoError(t, err)
+	var key models.APIKey
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &key))
+	assert.Equal(t, workspace.ID, key.WorkspaceID)
+
+	whoami := func(c echo.Context) error {
+		return c.String(http.StatusOK, db.WorkspaceFromContext(c.Request().Context()))
+	}
+	authenticate := func() (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+key.Key)
+		rec := httptest.NewRecorder()
+		return rec, h.Authenticate(true)(whoami)(e.NewContext(req, rec))
+	}
+	rec, err = authenticate()
+	require.NoError(t, err)
+	assert.Equal(t, workspace.ID, rec.Body.String())
+
+	_, err = call("ana", http.MethodDelete, "", h.RemoveWorkspaceMember, workspace.ID, "cy")
+	require.NoError(t, err)
+	_, err = authenticate()
+	assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+}
+
+func TestWorkspaceScopedRoutes(t *testing.T) {
+	// Setup
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e := echo.New()
+	h := NewHandler(store)
+
+	keys := map[string]string{}
+	for _, id := range []string{"ana", "bo"} {
+		_, err := store.CreateUser(context.Background(), sqlc.CreateUserParams{ID: id, Name: id, Email: id + "@example.com"})
+		require.NoError(t, err)
+		join(t, store, "team-"+id, id, db.RoleOwner)
+		_, keys[id], err = store.IssueAPIKey(db.WithWorkspace(context.Background(), "team-"+id), id, "laptop")
+		require.NoError(t, err)
+	}
+
+	// Ana's team has a prompt with two versions, a label moved between
+	// them, a run, an eval run, a tag and a collection
+	teamA := db.WithWorkspace(context.Background(), "team-ana")
+	promptID := sql.NullString{String: "prompt-a", Valid: true}
+	_, err := store.CreatePrompt(teamA, sqlc.CreatePromptParams{ID: "prompt-a", Title: "Launch plan", Slug: "launch"})
+	require.NoError(t, err)
+	for _, n := range []int64{1, 2} {
+		_, err = store.CreateVersion(teamA, sqlc.CreateVersionParams{
+			ID:       fmt.Sprintf("version-%d", n),
+			PromptID: promptID,
+			Version:  n,
+			Content:  `[{"role":"user","content":"Plan the launch"}]`,
+		})
+		require.NoError(t, err)
+		_, err = store.MoveLabel(teamA, db.MoveLabelParams{PromptID: "prompt-a", Name: "production", Version: n})
+		require.NoError(t, err)
+	}
+	_, err = store.CreateRun(teamA, sqlc.CreateRunParams{
+		ID:              "run-a",
+		PromptID:        promptID,
+		PromptVersionID: sql.NullString{String: "version-1", Valid: true},
+		Model:           "fake/echo",
+		Parameters:      "{}",
+		Messages:        "[]",
+	})
+	require.NoError(t, err)
+	_, _, err = store.CreateDataset(teamA, sqlc.CreateDatasetParams{ID: "dataset-a", Name: "Launches"}, nil)
+	require.NoError(t, err)
+	_, err = store.CreateEvalRun(teamA, sqlc.CreateEvalRunParams{
+		ID:              "eval-run-a",
+		PromptID:        "prompt-a",
+		PromptVersionID: "version-1",
+		DatasetID:       "dataset-a",
+		Model:           "fake/echo",
+		Parameters:      "{}",
+		Scorers:         "[]",
+	})
+	require.NoError(t, err)
+	_, err = store.SetPromptTags(teamA, "prompt-a", []string{"launch"})
+	require.NoError(t, err)
+	_, err = store.CreateCollection(teamA, sqlc.CreateCollectionParams{ID: "collection-a", Name: "Launches"})
+	require.NoError(t, err)
+
+	// call runs a handler for a prompt route as the holder of key; params
+	// alternate names and values
+	call := func(key, method, body string, next echo.HandlerFunc, params ...string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(method, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+key)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		var names, values []string
+		for i := 0; i < len(params); i += 2 {
+			names = append(names, params[i])
+			values = append(values, params[i+1])
+		}
+		c.SetParamNames(names...)
+		c.SetParamValues(values...)
+		return rec, h.Authenticate(true)(h.ResolvePrompt(next))(c)
+	}
+
+	routes := []struct {
+		name   string
+		method string
+		body   string
+		fn     echo.HandlerFunc
+		params []string
+	}{
+		{"labels", http.MethodGet, "", h.GetLabels, []string{"id", "prompt-a"}},
+		{"resolve label", http.MethodGet, "", h.ResolveLabel, []string{"id", "prompt-a", "label", "production"}},
+		{"move label", http.MethodPut, `{"version":1}`, h.MoveLabel, []string{"id", "prompt-a", "label", "production"}},
+		{"label history", http.MethodGet, "", h.GetLabelHistory, []string{"id", "prompt-a", "label", "production"}},
+		{"roll back label", http.MethodPost, `{}`, h.RollbackLabel, []string{"id", "prompt-a", "label", "production"}},
+		{"delete label", http.MethodDelete, "", h.DeleteLabel, []string{"id", "prompt-a", "label", "production"}},
+		{"prompt runs", http.MethodGet, "", h.GetPromptRuns, []string{"id", "prompt-a"}},
+		{"version runs", http.MethodGet, "", h.GetVersionRuns, []string{"id", "prompt-a", "version", "1"}},
+		{"run", http.MethodGet, "", h.GetRun, []string{"run", "run-a"}},
+		{"eval runs", http.MethodGet, "", h.GetEvalRuns, []string{"id", "prompt-a", "version", "1"}},
+		{"eval run", http.MethodGet, "", h.GetEvalRun, []string{"id", "prompt-a", "version", "1", "run", "eval-run-a"}},
+		{"tag", http.MethodGet, "", h.GetTag, []string{"tag", "launch"}},
+		{"rename tag", http.MethodPut, `{"name":"hijacked"}`, h.RenameTag, []string{"tag", "launch"}},
+		{"delete tag", http.MethodDelete, "", h.DeleteTag, []string{"tag", "launch"}},
+		{"collection", http.MethodGet, "", h.GetCollection, []string{"collection", "collection-a"}},
+		{"update collection", http.MethodPut, `{"name":"Hijacked"}`, h.UpdateCollection, []string{"collection", "collection-a"}},
+		{"delete collection", http.MethodDelete, "", h.DeleteCollection, []string{"collection", "collection-a"}},
+		{"dataset", http.MethodGet, "", h.GetDataset, []string{"dataset", "dataset-a"}},
+		{"update dataset", http.MethodPut, `{"name":"Hijacked"}`, h.UpdateDataset, []string{"dataset", "dataset-a"}},
+		{"add dataset rows", http.MethodPost, `{"rows":[{"variables":{}}]}`, h.AddDatasetRows, []string{"dataset", "dataset-a"}},
+		{"delete dataset", http.MethodDelete, "", h.DeleteDataset, []string{"dataset", "dataset-a"}},
+	}
+
+	// Bo's team can't read or change any of it by the prompt's ID
+	for _, route := range routes {
+		_, err := call(keys["bo"], route.method, route.body, route.fn, route.params...)
+		require.Error(t, err, route.name)
+		assert.Equal(t, http.StatusNotFound, err.(*echo.HTTPError).Code, route.name)
+	}
+
+	// Ana's team still can, and the label was left where it was
+	for _, route := range routes[:4] {
+		_, err := call(keys["ana"], route.method, route.body, route.fn, route.params...)
+		require.NoError(t, err, route.name)
+	}
+	for _, route := range routes[6:] {
+		if route.method == http.MethodGet {
+			_, err := call(keys["ana"], route.method, route.body, route.fn, route.params...)
+			require.NoError(t, err, route.name)
+		}
+	}
+	history, err := store.ListLabelHistory(teamA, sqlc.ListLabelHistoryParams{PromptID: "prompt-a", Name: "production"})
+	require.NoError(t, err)
+	assert.Len(t, history, 3, "only Ana's move is recorded")
+	rows, err := store.ListDatasetRows(teamA, "dataset-a")
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+
+	// Bo's team can't file anything into Ana's collection
+	_, err = call(keys["bo"], http.MethodPost, `{"name":"Mine","parent_id":"collection-a"}`, h.CreateCollection)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+	"github.com/epuerta9/prompts.kitchenai/db/sqlc"
+)
+
+// authRequired reads API_AUTH, which is "required" (the default) to reject
+// requests without an API key or "optional" to let them through
+// anonymously. Presented keys are always verified.
+func authRequired() bool {
+	switch raw := os.Getenv("API_AUTH"); raw {
+	case "", "required":
+		return true
+	case "optional":
+		return false
+	default:
+		log.Printf("Invalid API_AUTH %q, using required", raw)
+		return true
+	}
+}
+
+// CreateKey issues an API key from the command line, creating the user and
+// making them an owner of the workspace if needed, so the first key can be
+// made before anyone can call the API
+func CreateKey(args []string) error {
+	flags := flag.NewFlagSet("create-key", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the user the key belongs to")
+	name := flags.String("user", "", "display name when the user is created")
+	keyName := flags.String("name", "default", "name of the key")
+	workspace := flags.String("workspace", db.DefaultWorkspaceID, "ID of the workspace the key signs in to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return fmt.Errorf("-email is required")
+	}
+	if *name == "" {
+		*name, _, _ = strings.Cut(*email, "@")
+	}
+
+	sqlDB, err := db.Connect(os.Getenv("DATABASE_URL"))
+	if err != nil {
+		return err
+	}
+	if err := db.RunMigrations(sqlDB); err != nil {
+		sqlDB.Close()
+		return err
+	}
+	store := db.NewStore(sqlDB)
+	defer store.Close()
+
+	ctx := db.WithWorkspace(context.Background(), *workspace)
+	user, err := store.GetUserByEmail(ctx, *email)
+	if err == sql.ErrNoRows {
+		user, err = store.CreateUser(ctx, sqlc.CreateUserParams{ID: uuid.New().String(), Name: *name, Email: *email})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	_, err = store.GetWorkspaceMember(ctx, sqlc.GetWorkspaceMemberParams{WorkspaceID: *workspace, UserID: user.ID})
+	if err == sql.ErrNoRows {
+		err = addOwner(ctx, store, *workspace, user.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add user to workspace: %w", err)
+	}
+
+	key, secret, err := store.IssueAPIKey(ctx, user.ID, *keyName)
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	fmt.Printf("Created API key %q (%s) for %s in workspace %s\n%s\n", key.Name, key.Prefix, user.Email, key.WorkspaceID, secret)
+	return nil
+}
+
+// addOwner makes a user an owner of a workspace, creating the workspace
+// if it doesn't exist
+func addOwner(ctx context.Context, store *db.Store, workspaceID, userID string) error {
+	_, err := store.GetWorkspace(ctx, workspaceID)
+	if err == sql.ErrNoRows {
+		_, err = store.CreateWorkspace(ctx, sqlc.CreateWorkspaceParams{ID: workspaceID, Name: workspaceID}, userID)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = store.SetWorkspaceMember(ctx, sqlc.UpsertWorkspaceMemberParams{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Role:        db.RoleOwner,
+	})
+	return err
+}
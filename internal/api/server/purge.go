@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/epuerta9/prompts.kitchenai/db"
+)
+
+const (
+	// defaultTrashRetention is how long soft-deleted prompts can be restored
+	defaultTrashRetention = 30 * 24 * time.Hour
+	// purgeInterval is how often the trash is checked for expired prompts
+	purgeInterval = time.Hour
+)
+
+// trashRetention reads PROMPT_TRASH_RETENTION, e.g. "168h", falling back to
+// the default when it is unset or invalid
+func trashRetention() time.Duration {
+	raw := os.Getenv("PROMPT_TRASH_RETENTION")
+	if raw == "" {
+		return defaultTrashRetention
+	}
+	retention, err := time.ParseDuration(raw)
+	if err != nil || retention < 0 {
+		log.Printf("Invalid PROMPT_TRASH_RETENTION %q, using %s", raw, defaultTrashRetention)
+		return defaultTrashRetention
+	}
+	return retention
+}
+
+// runPurgeJob permanently deletes prompts that have been in the trash for
+// longer than retention, until ctx is cancelled
+func runPurgeJob(ctx context.Context, store *db.Store, retention time.Duration) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := store.PurgeDeletedPrompts(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("Failed to purge deleted prompts: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted prompts", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
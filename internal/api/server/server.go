@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"database/sql"
 	"log"
 	"net/http"
 	"os"
@@ -32,32 +33,108 @@ func Run() error {
 	store := db.NewStore(sqlDB)
 	defer store.Close()
 
+	// Eval runs do not survive a restart
+	if n, err := store.FailUnfinishedEvalRuns(context.Background(), sql.NullString{String: "interrupted by server restart", Valid: true}); err != nil {
+		log.Printf("Failed to mark interrupted eval runs: %v", err)
+	} else if n > 0 {
+		log.Printf("Marked %d interrupted eval runs as failed", n)
+	}
+
+	// Purge prompts that have been in the trash past the retention period
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	go runPurgeJob(purgeCtx, store, trashRetention())
+
 	// Initialize Echo
 	e := echo.New()
 
 	// Middleware
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		// Let browser clients read ETags for conditional requests
+		ExposeHeaders: []string{"ETag"},
+	}))
 
 	// Setup routes
 	h := handler.NewHandler(store)
 
-	// Register routes
-	api := e.Group("/api")
+	// Register routes; every API request is authenticated by API key
+	api := e.Group("/api", h.Authenticate(authRequired()))
+	api.GET("/keys", h.GetAPIKeys)
+	api.POST("/keys", h.CreateAPIKey)
+	api.DELETE("/keys/:key", h.RevokeAPIKey)
+	api.GET("/me", h.GetMe)
+	api.GET("/users", h.GetUsers)
+	api.POST("/users", h.CreateUser)
+	api.GET("/users/:user", h.GetUser)
+	api.PUT("/users/:user", h.UpdateUser)
+	api.DELETE("/users/:user", h.DeleteUser)
+	api.GET("/users/:user/prompts", h.GetUserPrompts)
+	api.GET("/workspaces", h.GetWorkspaces)
+	api.POST("/workspaces", h.CreateWorkspace)
+	api.GET("/workspaces/:workspace/members", h.GetWorkspaceMembers)
+	api.PUT("/workspaces/:workspace/members/:user", h.SetWorkspaceMember)
+	api.DELETE("/workspaces/:workspace/members/:user", h.RemoveWorkspaceMember)
 	api.GET("/prompts", h.GetPrompts)
 	api.POST("/prompts", h.CreatePrompt)
-	api.GET("/prompts/:id", h.GetPrompt)
-	api.PUT("/prompts/:id", h.UpdatePrompt)
-	api.DELETE("/prompts/:id", h.DeletePrompt)
-	api.GET("/prompts/:id/versions", h.GetVersions)
-	api.POST("/prompts/:id/versions", h.CreateVersion)
-	api.GET("/prompts/:id/versions/:version", h.GetVersion)
-	api.GET("/prompts/:id/comments", h.GetComments)
-	api.POST("/prompts/:id/comments", h.AddComment)
-	api.GET("/prompts/:id/versions/:version/evals", h.GetEvaluations)
-	api.POST("/prompts/:id/versions/:version/eval", h.CreateEvaluation)
+	api.GET("/search", h.SearchPrompts)
+	api.GET("/tags", h.GetTags)
+	api.POST("/tags", h.CreateTag)
+	api.GET("/tags/:tag", h.GetTag)
+	api.PUT("/tags/:tag", h.RenameTag)
+	api.DELETE("/tags/:tag", h.DeleteTag)
+	api.GET("/collections", h.GetCollections)
+	api.POST("/collections", h.CreateCollection)
+	api.GET("/collections/:collection", h.GetCollection)
+	api.PUT("/collections/:collection", h.UpdateCollection)
+	api.DELETE("/collections/:collection", h.DeleteCollection)
+	api.GET("/datasets", h.GetDatasets)
+	api.POST("/datasets", h.CreateDataset)
+	api.GET("/datasets/:dataset", h.GetDataset)
+	api.PUT("/datasets/:dataset", h.UpdateDataset)
+	api.DELETE("/datasets/:dataset", h.DeleteDataset)
+	api.POST("/datasets/:dataset/rows", h.AddDatasetRows)
+	api.GET("/scorers", h.GetScorers)
+	api.GET("/review/next", h.GetNextReview)
+	api.POST("/review/judgments", h.CreateJudgment)
+
+	// Routes for a single prompt accept its ID or "[namespace:]slug"
+	prompt := api.Group("/prompts/:id", h.ResolvePrompt)
+	prompt.GET("", h.GetPrompt)
+	prompt.PUT("", h.UpdatePrompt)
+	prompt.DELETE("", h.DeletePrompt)
+	prompt.POST("/restore", h.RestorePrompt)
+	prompt.PUT("/slug", h.UpdatePromptSlug)
+	prompt.GET("/versions", h.GetVersions)
+	prompt.POST("/versions", h.CreateVersion)
+	prompt.GET("/versions/:version", h.GetVersion)
+	prompt.GET("/diff", h.GetVersionDiff)
+	prompt.POST("/compare", h.CompareVersions)
+	prompt.GET("/eval-summary", h.GetEvalSummary)
+	prompt.GET("/ratings", h.GetRatings)
+	prompt.POST("/versions/:version/render", h.RenderVersion)
+	prompt.POST("/versions/:version/restore", h.RestoreVersion)
+	prompt.GET("/labels", h.GetLabels)
+	prompt.GET("/labels/:label", h.ResolveLabel)
+	prompt.PUT("/labels/:label", h.MoveLabel)
+	prompt.DELETE("/labels/:label", h.DeleteLabel)
+	prompt.GET("/labels/:label/history", h.GetLabelHistory)
+	prompt.POST("/labels/:label/rollback", h.RollbackLabel)
+	prompt.GET("/comments", h.GetComments)
+	prompt.POST("/comments", h.AddComment)
+	prompt.GET("/versions/:version/evals", h.GetEvaluations)
+	prompt.POST("/versions/:version/eval", h.CreateEvaluation)
+	prompt.POST("/versions/:version/score", h.ScoreOutput)
+	prompt.GET("/versions/:version/runs", h.GetVersionRuns)
+	prompt.GET("/versions/:version/eval-runs", h.GetEvalRuns)
+	prompt.POST("/versions/:version/eval-runs", h.CreateEvalRun)
+	prompt.GET("/versions/:version/eval-runs/:run", h.GetEvalRun)
+	prompt.GET("/runs", h.GetPromptRuns)
+
+	api.GET("/runs/:run", h.GetRun)
 	api.POST("/run", h.RunPrompt)
+	api.POST("/run/stream", h.RunPromptStream)
 
 	// Serve static files for React frontend
 	e.Static("/", "frontend/build")
@@ -90,6 +167,11 @@ func Run() error {
 	if err := e.Shutdown(ctx); err != nil {
 		log.Printf("Error during server shutdown: %v", err)
 	}
+	// Let eval runs finish; any still running are marked as interrupted
+	// on the next start
+	if err := h.WaitForEvalRuns(ctx); err != nil {
+		log.Printf("Eval runs still running at shutdown: %v", err)
+	}
 
 	log.Println("Server shutdown complete")
 	return nil
@@ -0,0 +1,59 @@
+// Package auth issues API keys and carries the authenticated user through
+// request contexts
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// KeyPrefix starts every API key so keys are recognizable, e.g. in secret
+// scanners
+const KeyPrefix = "pk_"
+
+// displayLength is how many leading characters of a key are kept to tell
+// keys apart
+const displayLength = len(KeyPrefix) + 8
+
+// GenerateKey returns a new random API key
+func GenerateKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return KeyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// HashKey returns the hash an API key is stored and looked up by. Keys are
+// random enough that a fast, unsalted hash is safe.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// DisplayPrefix returns the leading characters of a key that are safe to
+// show
+func DisplayPrefix(key string) string {
+	if len(key) < displayLength {
+		return key
+	}
+	return key[:displayLength]
+}
+
+type userKey struct{}
+
+// WithUser returns a copy of ctx carrying the authenticated user
+func WithUser(ctx context.Context, user models.User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext returns the authenticated user of ctx, if any
+func UserFromContext(ctx context.Context) (models.User, bool) {
+	user, ok := ctx.Value(userKey{}).(models.User)
+	return user, ok
+}
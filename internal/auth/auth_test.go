@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeys(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	other, err := GenerateKey()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(key, KeyPrefix))
+	assert.NotEqual(t, key, other)
+	assert.Equal(t, HashKey(key), HashKey(key))
+	assert.NotEqual(t, HashKey(key), HashKey(other))
+	assert.Len(t, HashKey(key), 64)
+	assert.Equal(t, key[:11], DisplayPrefix(key))
+}
+
+func TestUserContext(t *testing.T) {
+	_, ok := UserFromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := WithUser(context.Background(), models.User{ID: "ada", Email: "ada@example.com"})
+	user, ok := UserFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "ada", user.ID)
+}
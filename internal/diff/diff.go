@@ -0,0 +1,257 @@
+// Package diff compares prompt messages and reports message-level changes
+// with line-level diffs for changed contents
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// Op describes how a message or line differs between two versions
+type Op string
+
+const (
+	OpEqual   Op = "equal"
+	OpAdded   Op = "added"
+	OpRemoved Op = "removed"
+	OpChanged Op = "changed"
+)
+
+// contextLines is the number of unchanged lines kept around each hunk of a
+// unified diff
+const contextLines = 3
+
+// Line is a single line of a content diff. Line numbers are 1-based and
+// zero when the line does not exist on that side.
+type Line struct {
+	Op       Op     `json:"op"`
+	Text     string `json:"text"`
+	FromLine int    `json:"from_line,omitempty"`
+	ToLine   int    `json:"to_line,omitempty"`
+}
+
+// Message is the difference for one message. Indexes are 0-based positions
+// in the from and to message lists and are nil when the message does not
+// exist on that side.
+type Message struct {
+	Op        Op                 `json:"op"`
+	Role      models.MessageRole `json:"role"`
+	FromIndex *int               `json:"from_index"`
+	ToIndex   *int               `json:"to_index"`
+	From      string             `json:"from,omitempty"`
+	To        string             `json:"to,omitempty"`
+	Lines     []Line             `json:"lines,omitempty"`
+	Unified   string             `json:"unified,omitempty"`
+}
+
+// Summary counts messages by operation
+type Summary struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Changed   int `json:"changed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// Result is the difference between two message lists
+type Result struct {
+	Summary  Summary   `json:"summary"`
+	Messages []Message `json:"messages"`
+}
+
+// Messages compares two message lists. Identical messages are matched
+// first; within each run of unmatched messages, removed and added messages
+// with the same role are paired up in order and reported as changed.
+func Messages(from, to []models.Message) Result {
+	result := Result{Messages: []Message{}}
+
+	same := func(i, j int) bool { return from[i] == to[j] }
+	matches := lcs(len(from), len(to), same)
+
+	i, j := 0, 0
+	for _, m := range append(matches, [2]int{len(from), len(to)}) {
+		result.Messages = append(result.Messages, alignGap(from, to, i, m[0], j, m[1])...)
+		if m[0] < len(from) {
+			result.Messages = append(result.Messages, Message{
+				Op:        OpEqual,
+				Role:      from[m[0]].Role,
+				FromIndex: index(m[0]),
+				ToIndex:   index(m[1]),
+			})
+		}
+		i, j = m[0]+1, m[1]+1
+	}
+
+	for _, msg := range result.Messages {
+		switch msg.Op {
+		case OpAdded:
+			result.Summary.Added++
+		case OpRemoved:
+			result.Summary.Removed++
+		case OpChanged:
+			result.Summary.Changed++
+		default:
+			result.Summary.Unchanged++
+		}
+	}
+	return result
+}
+
+// alignGap diffs from[fi:fe] against to[ti:te], neither of which share an
+// identical message
+func alignGap(from, to []models.Message, fi, fe, ti, te int) []Message {
+	var out []Message
+	for fi < fe || ti < te {
+		switch {
+		case fi < fe && ti < te && from[fi].Role == to[ti].Role:
+			lines := Lines(from[fi].Content, to[ti].Content)
+			out = append(out, Message{
+				Op:        OpChanged,
+				Role:      from[fi].Role,
+				FromIndex: index(fi),
+				ToIndex:   index(ti),
+				From:      from[fi].Content,
+				To:        to[ti].Content,
+				Lines:     lines,
+				Unified:   Unified(lines),
+			})
+			fi++
+			ti++
+		case fi < fe && (ti == te || !hasRole(to[ti:te], from[fi].Role)):
+			out = append(out, Message{Op: OpRemoved, Role: from[fi].Role, FromIndex: index(fi), From: from[fi].Content})
+			fi++
+		default:
+			out = append(out, Message{Op: OpAdded, Role: to[ti].Role, ToIndex: index(ti), To: to[ti].Content})
+			ti++
+		}
+	}
+	return out
+}
+
+func hasRole(msgs []models.Message, role models.MessageRole) bool {
+	for _, msg := range msgs {
+		if msg.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+func index(i int) *int {
+	return &i
+}
+
+// Lines returns a line-by-line diff of two texts
+func Lines(from, to string) []Line {
+	a, b := strings.Split(from, "\n"), strings.Split(to, "\n")
+	matches := lcs(len(a), len(b), func(i, j int) bool { return a[i] == b[j] })
+
+	var lines []Line
+	i, j := 0, 0
+	for _, m := range append(matches, [2]int{len(a), len(b)}) {
+		for ; i < m[0]; i++ {
+			lines = append(lines, Line{Op: OpRemoved, Text: a[i], FromLine: i + 1})
+		}
+		for ; j < m[1]; j++ {
+			lines = append(lines, Line{Op: OpAdded, Text: b[j], ToLine: j + 1})
+		}
+		if m[0] < len(a) {
+			lines = append(lines, Line{Op: OpEqual, Text: a[i], FromLine: i + 1, ToLine: j + 1})
+		}
+		i, j = m[0]+1, m[1]+1
+	}
+	return lines
+}
+
+// Unified formats a line diff as unified diff hunks, keeping a few lines
+// of context around each change
+func Unified(lines []Line) string {
+	var sb strings.Builder
+	for i := 0; i < len(lines); {
+		if lines[i].Op == OpEqual {
+			i++
+			continue
+		}
+
+		// Grow the hunk until two changes are separated by more unchanged
+		// lines than the context on both sides would cover
+		end := i + 1
+		for k := end; k < len(lines); k++ {
+			if lines[k].Op != OpEqual {
+				end = k + 1
+			} else if k-end >= 2*contextLines {
+				break
+			}
+		}
+
+		lo := max(i-contextLines, 0)
+		hi := min(end+contextLines, len(lines))
+		writeHunk(&sb, lines[lo:hi])
+		i = hi
+	}
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, hunk []Line) {
+	fromStart, fromCount, toStart, toCount := 0, 0, 0, 0
+	for _, line := range hunk {
+		if line.FromLine > 0 {
+			if fromCount == 0 {
+				fromStart = line.FromLine
+			}
+			fromCount++
+		}
+		if line.ToLine > 0 {
+			if toCount == 0 {
+				toStart = line.ToLine
+			}
+			toCount++
+		}
+	}
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", fromStart, fromCount, toStart, toCount)
+	for _, line := range hunk {
+		prefix := " "
+		switch line.Op {
+		case OpAdded:
+			prefix = "+"
+		case OpRemoved:
+			prefix = "-"
+		}
+		sb.WriteString(prefix + line.Text + "\n")
+	}
+}
+
+// lcs returns the index pairs of a longest common subsequence of two
+// sequences of length n and m, in increasing order
+func lcs(n, m int, equal func(i, j int) bool) [][2]int {
+	// table[i][j] is the LCS length of the suffixes starting at i and j
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if equal(i, j) {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+
+	var matches [][2]int
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case equal(i, j):
+			matches = append(matches, [2]int{i, j})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
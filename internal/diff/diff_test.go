@@ -0,0 +1,62 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessages(t *testing.T) {
+	from := []models.Message{
+		{Role: models.SystemRole, Content: "You are helpful.\nBe brief."},
+		{Role: models.UserRole, Content: "Hello"},
+		{Role: models.AssistantRole, Content: "Hi there"},
+	}
+	to := []models.Message{
+		{Role: models.SystemRole, Content: "You are helpful.\nBe thorough."},
+		{Role: models.UserRole, Content: "Hello"},
+		{Role: models.UserRole, Content: "One more thing"},
+	}
+
+	result := Messages(from, to)
+	assert.Equal(t, Summary{Added: 1, Removed: 1, Changed: 1, Unchanged: 1}, result.Summary)
+	require.Len(t, result.Messages, 4)
+
+	changed := result.Messages[0]
+	assert.Equal(t, OpChanged, changed.Op)
+	assert.Equal(t, models.SystemRole, changed.Role)
+	assert.Equal(t, 0, *changed.FromIndex)
+	assert.Equal(t, 0, *changed.ToIndex)
+	assert.Equal(t, "@@ -1,2 +1,2 @@\n You are helpful.\n-Be brief.\n+Be thorough.\n", changed.Unified)
+
+	assert.Equal(t, OpEqual, result.Messages[1].Op)
+
+	// Messages with a different role are not paired
+	assert.Equal(t, OpRemoved, result.Messages[2].Op)
+	assert.Equal(t, models.AssistantRole, result.Messages[2].Role)
+	assert.Nil(t, result.Messages[2].ToIndex)
+	assert.Equal(t, OpAdded, result.Messages[3].Op)
+	assert.Equal(t, 2, *result.Messages[3].ToIndex)
+}
+
+func TestUnified(t *testing.T) {
+	var from, to []string
+	for i := 1; i <= 20; i++ {
+		from = append(from, strings.Repeat("x", i))
+		to = append(to, strings.Repeat("x", i))
+	}
+	to[1] = "changed"
+	to[17] = "also changed"
+
+	// Changes far apart produce separate hunks with three lines of context
+	unified := Unified(Lines(strings.Join(from, "\n"), strings.Join(to, "\n")))
+	assert.Equal(t, 2, strings.Count(unified, "@@ -"))
+	assert.True(t, strings.HasPrefix(unified, "@@ -1,5 +1,5 @@\n x\n-xx\n+changed\n xxx\n"))
+	assert.Contains(t, unified, "@@ -15,6 +15,6 @@\n")
+
+	// Identical texts produce no hunks
+	assert.Empty(t, Unified(Lines("a\nb", "a\nb")))
+}
@@ -0,0 +1,345 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// builtins are the scorer types every registry starts with
+var builtins = map[string]Factory{
+	"exact_match":  newExactMatch,
+	"contains":     newContains(false),
+	"not_contains": newContains(true),
+	"regex":        newRegex,
+	"json_valid":   newJSONValid,
+	"json_schema":  newJSONSchema,
+	"length":       newLength,
+	"numeric":      newNumeric,
+}
+
+// passFail converts a check into a result scoring 1 or 0
+func passFail(passed bool, reason string) Result {
+	if passed {
+		return Result{Passed: true, Score: 1, Reason: reason}
+	}
+	return Result{Reason: reason}
+}
+
+// exactMatch passes when the output equals the expected output, ignoring
+// surrounding whitespace
+type exactMatch struct {
+	Value           *string `json:"value"`
+	CaseInsensitive bool    `json:"case_insensitive"`
+}
+
+func newExactMatch(ctx context.Context, config json.RawMessage) (Scorer, error) {
+	s := &exactMatch{}
+	return s, json.Unmarshal(config, s)
+}
+
+func (s *exactMatch) Name() string { return "exact_match" }
+
+func (s *exactMatch) Score(ctx context.Context, c Case) (Result, error) {
+	expected := s.Value
+	if expected == nil {
+		expected = c.Expected
+	}
+	if expected == nil {
+		return Result{}, ErrNotApplicable
+	}
+
+	want, got := strings.TrimSpace(*expected), strings.TrimSpace(c.Output)
+	matched := want == got
+	if s.CaseInsensitive {
+		matched = strings.EqualFold(want, got)
+	}
+
+	if matched {
+		return passFail(true, "output matches the expected output"), nil
+	}
+	return passFail(false, "output differs from the expected output"), nil
+}
+
+// contains checks that the output includes, or with negate excludes,
+// every value. Without values it looks for the expected output.
+type contains struct {
+	Value           string   `json:"value"`
+	Values          []string `json:"values"`
+	CaseInsensitive bool     `json:"case_insensitive"`
+	negate          bool
+}
+
+func newContains(negate bool) Factory {
+	return func(ctx context.Context, config json.RawMessage) (Scorer, error) {
+		s := &contains{negate: negate}
+		if err := json.Unmarshal(config, s); err != nil {
+			return nil, err
+		}
+		if s.Value != "" {
+			s.Values = append([]string{s.Value}, s.Values...)
+		}
+		return s, nil
+	}
+}
+
+func (s *contains) Name() string {
+	if s.negate {
+		return "not_contains"
+	}
+	return "contains"
+}
+
+func (s *contains) Score(ctx context.Context, c Case) (Result, error) {
+	values := s.Values
+	if len(values) == 0 {
+		if c.Expected == nil {
+			return Result{}, ErrNotApplicable
+		}
+		values = []string{*c.Expected}
+	}
+
+	output := c.Output
+	if s.CaseInsensitive {
+		output = strings.ToLower(output)
+	}
+
+	var found, missing []string
+	for _, v := range values {
+		needle := v
+		if s.CaseInsensitive {
+			needle = strings.ToLower(v)
+		}
+		if strings.Contains(output, needle) {
+			found = append(found, strconv.Quote(v))
+		} else {
+			missing = append(missing, strconv.Quote(v))
+		}
+	}
+
+	if s.negate {
+		score := 1 - float64(len(found))/float64(len(values))
+		if len(found) > 0 {
+			return Result{Score: score, Reason: "output contains " + strings.Join(found, ", ")}, nil
+		}
+		return passFail(true, "output contains none of the values"), nil
+	}
+
+	score := float64(len(found)) / float64(len(values))
+	if len(missing) > 0 {
+		return Result{Score: score, Reason: "output is missing " + strings.Join(missing, ", ")}, nil
+	}
+	return passFail(true, "output contains every value"), nil
+}
+
+// regex passes when the output matches a regular expression
+type regex struct {
+	re *regexp.Regexp
+}
+
+func newRegex(ctx context.Context, config json.RawMessage) (Scorer, error) {
+	var cfg struct {
+		Pattern string `json:"pattern"`
+	}
+	if err := json.Unmarshal(config, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Pattern == "" {
+		return nil, errors.New("pattern is required")
+	}
+
+	re, err := regexp.Compile(cfg.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &regex{re: re}, nil
+}
+
+func (s *regex) Name() string { return "regex" }
+
+func (s *regex) Score(ctx context.Context, c Case) (Result, error) {
+	if s.re.MatchString(c.Output) {
+		return passFail(true, "output matches "+s.re.String()), nil
+	}
+	return passFail(false, "output does not match "+s.re.String()), nil
+}
+
+// jsonValid passes when the output is a JSON document
+type jsonValid struct{}
+
+func newJSONValid(ctx context.Context, config json.RawMessage) (Scorer, error) {
+	return jsonValid{}, nil
+}
+
+func (jsonValid) Name() string { return "json_valid" }
+
+func (jsonValid) Score(ctx context.Context, c Case) (Result, error) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(c.Output), &v); err != nil {
+		return passFail(false, "output is not valid JSON: "+err.Error()), nil
+	}
+	return passFail(true, "output is valid JSON"), nil
+}
+
+// jsonSchema passes when the output is a JSON document conforming to a
+// schema
+type jsonSchema struct {
+	schema *Schema
+}
+
+func newJSONSchema(ctx context.Context, config json.RawMessage) (Scorer, error) {
+	var cfg struct {
+		Schema json.RawMessage `json:"schema"`
+	}
+	if err := json.Unmarshal(config, &cfg); err != nil {
+		return nil, err
+	}
+	if len(cfg.Schema) == 0 {
+		return nil, errors.New("schema is required")
+	}
+
+	schema, err := CompileSchema(cfg.Schema)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonSchema{schema: schema}, nil
+}
+
+func (s *jsonSchema) Name() string { return "json_schema" }
+
+func (s *jsonSchema) Score(ctx context.Context, c Case) (Result, error) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(c.Output), &v); err != nil {
+		return passFail(false, "output is not valid JSON: "+err.Error()), nil
+	}
+	if err := s.schema.Validate(v); err != nil {
+		return passFail(false, err.Error()), nil
+	}
+	return passFail(true, "output conforms to the schema"), nil
+}
+
+// length passes when the output's length is within bounds, counted in
+// characters or words
+type length struct {
+	Min  *int   `json:"min"`
+	Max  *int   `json:"max"`
+	Unit string `json:"unit"`
+}
+
+func newLength(ctx context.Context, config json.RawMessage) (Scorer, error) {
+	s := &length{}
+	if err := json.Unmarshal(config, s); err != nil {
+		return nil, err
+	}
+
+	switch s.Unit {
+	case "":
+		s.Unit = "characters"
+	case "characters", "words":
+	default:
+		return nil, fmt.Errorf("unit must be characters or words, got %q", s.Unit)
+	}
+	if s.Min == nil && s.Max == nil {
+		return nil, errors.New("min or max is required")
+	}
+	if s.Min != nil && s.Max != nil && *s.Min > *s.Max {
+		return nil, errors.New("min must not exceed max")
+	}
+	return s, nil
+}
+
+func (s *length) Name() string { return "length" }
+
+func (s *length) Score(ctx context.Context, c Case) (Result, error) {
+	n := utf8.RuneCountInString(c.Output)
+	if s.Unit == "words" {
+		n = len(strings.Fields(c.Output))
+	}
+
+	reason := fmt.Sprintf("output is %d %s", n, s.Unit)
+	switch {
+	case s.Min != nil && n < *s.Min:
+		return passFail(false, fmt.Sprintf("%s, fewer than %d", reason, *s.Min)), nil
+	case s.Max != nil && n > *s.Max:
+		return passFail(false, fmt.Sprintf("%s, more than %d", reason, *s.Max)), nil
+	}
+	return passFail(true, reason), nil
+}
+
+// numberPattern finds the first number in free text
+var numberPattern = regexp.MustCompile(`[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?`)
+
+// parseNumber reads a number from text, falling back to the first number
+// that appears in it
+func parseNumber(text string) (float64, bool) {
+	text = strings.TrimSpace(text)
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f, true
+	}
+	match := numberPattern.FindString(strings.ReplaceAll(text, ",", ""))
+	if match == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(match, 64)
+	return f, err == nil
+}
+
+// numeric compares the number in the output with the expected number. It
+// passes within the tolerance and scores by relative closeness.
+type numeric struct {
+	Expected          *float64 `json:"expected"`
+	Tolerance         float64  `json:"tolerance"`
+	RelativeTolerance float64  `json:"relative_tolerance"`
+}
+
+func newNumeric(ctx context.Context, config json.RawMessage) (Scorer, error) {
+	s := &numeric{}
+	if err := json.Unmarshal(config, s); err != nil {
+		return nil, err
+	}
+	if s.Tolerance < 0 || s.RelativeTolerance < 0 {
+		return nil, errors.New("tolerances must not be negative")
+	}
+	return s, nil
+}
+
+func (s *numeric) Name() string { return "numeric" }
+
+func (s *numeric) Score(ctx context.Context, c Case) (Result, error) {
+	var want float64
+	switch {
+	case s.Expected != nil:
+		want = *s.Expected
+	case c.Expected != nil:
+		var ok bool
+		if want, ok = parseNumber(*c.Expected); !ok {
+			return Result{}, fmt.Errorf("expected output %q is not a number", *c.Expected)
+		}
+	default:
+		return Result{}, ErrNotApplicable
+	}
+
+	got, ok := parseNumber(c.Output)
+	if !ok {
+		return passFail(false, "output contains no number"), nil
+	}
+
+	diff := math.Abs(got - want)
+	score := 1.0
+	if scale := math.Max(math.Abs(got), math.Abs(want)); scale > 0 {
+		score = math.Max(0, 1-diff/scale)
+	}
+	passed := diff <= s.Tolerance || diff <= s.RelativeTolerance*math.Abs(want)
+
+	reason := fmt.Sprintf("output %g, expected %g", got, want)
+	if passed {
+		return Result{Passed: true, Score: score, Reason: reason}, nil
+	}
+	return Result{Score: score, Reason: reason}, nil
+}
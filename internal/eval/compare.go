@@ -0,0 +1,29 @@
+package eval
+
+import "math"
+
+// SignTest returns the two-sided p-value of a sign test on paired
+// comparisons: the chance of a split at least as lopsided as wins to
+// losses if neither side were better. Ties are left out; without any wins
+// or losses the p-value is 1.
+func SignTest(wins, losses int) float64 {
+	n := wins + losses
+	if n == 0 {
+		return 1
+	}
+
+	k := wins
+	if losses < k {
+		k = losses
+	}
+
+	// Sum the binomial tail in log space so large samples don't overflow
+	lgN, _ := math.Lgamma(float64(n + 1))
+	p := 0.0
+	for i := 0; i <= k; i++ {
+		lgI, _ := math.Lgamma(float64(i + 1))
+		lgRest, _ := math.Lgamma(float64(n - i + 1))
+		p += math.Exp(lgN - lgI - lgRest - float64(n)*math.Ln2)
+	}
+	return math.Min(1, 2*p)
+}
@@ -0,0 +1,27 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignTest(t *testing.T) {
+	tests := []struct {
+		wins, losses int
+		p            float64
+	}{
+		{0, 0, 1},
+		{3, 3, 1},
+		{5, 0, 0.0625},
+		{0, 10, 2.0 / 1024},
+		{6, 4, 772.0 / 1024},
+		{1000, 1000, 1},
+	}
+	for _, tt := range tests {
+		assert.InDelta(t, tt.p, SignTest(tt.wins, tt.losses), 1e-9, "%d wins, %d losses", tt.wins, tt.losses)
+	}
+
+	// A large, lopsided sample is significant without overflowing
+	assert.Less(t, SignTest(600, 400), 0.001)
+}
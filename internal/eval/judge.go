@@ -0,0 +1,81 @@
+package eval
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNoJudgeScore is returned when a judge's reply contains no score
+var ErrNoJudgeScore = errors.New("judge reply contains no score")
+
+var (
+	// scoreLine matches a "Score: 7" or "Score: 7/10" line
+	scoreLine = regexp.MustCompile(`(?im)^[\s*#_]*score[\s*_]*[:=][\s*_]*([-+]?\d+(?:\.\d+)?)(?:\s*/\s*\d+(?:\.\d+)?)?[*_]*\s*$`)
+	// rationaleLabel matches the label that starts the rationale
+	rationaleLabel = regexp.MustCompile(`(?im)^[\s*#_]*(?:rationale|reasoning|explanation|reason)[\s*_]*[:=][*_]*\s*`)
+)
+
+// rationaleKeys are the JSON fields a judge may put its rationale in
+var rationaleKeys = []string{"rationale", "reasoning", "explanation", "reason"}
+
+// ParseJudgeReply reads a score and rationale from a judge model's reply.
+// Replies may be a JSON object with a "score" and a "rationale" (possibly
+// inside a code fence), or text with a "Score: N" line. Without a labelled
+// rationale the rest of the text is used.
+func ParseJudgeReply(reply string) (float64, string, error) {
+	if score, rationale, ok := parseJudgeJSON(reply); ok {
+		return score, rationale, nil
+	}
+
+	match := scoreLine.FindStringSubmatchIndex(reply)
+	if match == nil {
+		return 0, "", ErrNoJudgeScore
+	}
+	score, err := strconv.ParseFloat(reply[match[2]:match[3]], 64)
+	if err != nil {
+		return 0, "", ErrNoJudgeScore
+	}
+
+	rest := reply[:match[0]] + reply[match[1]:]
+	if label := rationaleLabel.FindStringIndex(rest); label != nil {
+		rest = rest[label[1]:]
+	}
+	return score, strings.TrimSpace(rest), nil
+}
+
+// parseJudgeJSON reads a reply holding a JSON object
+func parseJudgeJSON(reply string) (float64, string, bool) {
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return 0, "", false
+	}
+
+	var verdict map[string]interface{}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &verdict); err != nil {
+		return 0, "", false
+	}
+
+	var score float64
+	switch v := verdict["score"].(type) {
+	case float64:
+		score = v
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, "", false
+		}
+		score = f
+	default:
+		return 0, "", false
+	}
+
+	for _, key := range rationaleKeys {
+		if rationale, ok := verdict[key].(string); ok {
+			return score, strings.TrimSpace(rationale), true
+		}
+	}
+	return score, "", true
+}
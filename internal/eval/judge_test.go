@@ -0,0 +1,36 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJudgeReply(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     string
+		score     float64
+		rationale string
+	}{
+		{"json", `{"score": 4, "rationale": "Accurate but terse."}`, 4, "Accurate but terse."},
+		{"fenced json", "Here is my grade:\n```json\n{\"reasoning\": \"Off topic\", \"score\": \"1.5\"}\n```", 1.5, "Off topic"},
+		{"score line first", "Score: 8/10\nRationale: Polite and correct.", 8, "Polite and correct."},
+		{"score line last", "The answer cites the policy.\nIt is friendly.\n\n**Score:** 9", 9, "The answer cites the policy.\nIt is friendly."},
+		{"labelled rationale", "Reasoning: misses the deadline\nSCORE = 2", 2, "misses the deadline"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, rationale, err := ParseJudgeReply(tt.reply)
+			require.NoError(t, err)
+			assert.Equal(t, tt.score, score)
+			assert.Equal(t, tt.rationale, rationale)
+		})
+	}
+
+	for _, reply := range []string{"Looks good to me.", `{"grade": 5}`, "The score is high"} {
+		_, _, err := ParseJudgeReply(reply)
+		assert.ErrorIs(t, err, ErrNoJudgeScore, reply)
+	}
+}
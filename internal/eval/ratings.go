@@ -0,0 +1,83 @@
+package eval
+
+import "math"
+
+// Matchup is the record of one player against another over any number of
+// pairwise judgments
+type Matchup struct {
+	A, B         string
+	WinsA, WinsB int
+	Ties         int
+}
+
+// BradleyTerry fits Bradley-Terry strengths to pairwise results and
+// returns them as Elo-style ratings: the geometric mean strength rates
+// 1000 and 400 points mean 10 to 1 odds of being preferred. Ties count as
+// half a win for each side, and every pair that met gets one extra tie as
+// a prior so that players who never won or never lost still get finite
+// ratings. Ratings are only comparable between players connected by
+// matchups.
+func BradleyTerry(matchups []Matchup) map[string]float64 {
+	type pair struct{ a, b string }
+	games := make(map[pair]float64)
+	wins := make(map[string]float64)
+	for _, m := range matchups {
+		if m.A == m.B {
+			continue
+		}
+		a, b := m.A, m.B
+		winsA, winsB := float64(m.WinsA), float64(m.WinsB)
+		if b < a {
+			a, b, winsA, winsB = b, a, winsB, winsA
+		}
+		if _, ok := games[pair{a, b}]; !ok {
+			// The prior tie
+			games[pair{a, b}] = 1
+			wins[a] += 0.5
+			wins[b] += 0.5
+		}
+		games[pair{a, b}] += float64(m.WinsA + m.WinsB + m.Ties)
+		wins[a] += winsA + float64(m.Ties)/2
+		wins[b] += winsB + float64(m.Ties)/2
+	}
+
+	strength := make(map[string]float64, len(wins))
+	for player := range wins {
+		strength[player] = 1
+	}
+
+	// Minorization-maximization updates (Hunter, 2004), rescaled to a
+	// geometric mean of 1 after each round
+	for iter := 0; iter < 10000; iter++ {
+		denom := make(map[string]float64, len(strength))
+		for p, n := range games {
+			d := n / (strength[p.a] + strength[p.b])
+			denom[p.a] += d
+			denom[p.b] += d
+		}
+
+		next := make(map[string]float64, len(strength))
+		logSum := 0.0
+		for player := range strength {
+			next[player] = wins[player] / denom[player]
+			logSum += math.Log(next[player])
+		}
+		scale := math.Exp(logSum / float64(len(next)))
+
+		change := 0.0
+		for player, s := range next {
+			s /= scale
+			change = math.Max(change, math.Abs(s-strength[player]))
+			strength[player] = s
+		}
+		if change < 1e-10 {
+			break
+		}
+	}
+
+	ratings := make(map[string]float64, len(strength))
+	for player, s := range strength {
+		ratings[player] = 1000 + 400*math.Log10(s)
+	}
+	return ratings
+}
@@ -0,0 +1,38 @@
+package eval
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBradleyTerry(t *testing.T) {
+	// Two players: the odds are the ratio of wins, counting the prior tie
+	ratings := BradleyTerry([]Matchup{{A: "v1", B: "v2", WinsA: 1, WinsB: 3}})
+	spread := 400 * math.Log10(3.5/1.5)
+	assert.InDelta(t, 1000+spread/2, ratings["v2"], 1e-6)
+	assert.InDelta(t, 1000-spread/2, ratings["v1"], 1e-6)
+
+	// Matchups in either order are combined, ties split evenly
+	ratings = BradleyTerry([]Matchup{
+		{A: "v1", B: "v2", WinsA: 1, WinsB: 1},
+		{A: "v2", B: "v1", Ties: 4},
+	})
+	assert.InDelta(t, 1000, ratings["v1"], 1e-6)
+	assert.InDelta(t, 1000, ratings["v2"], 1e-6)
+
+	// Ratings follow the transitive order, even for a player that never won
+	ratings = BradleyTerry([]Matchup{
+		{A: "v1", B: "v2", WinsB: 4},
+		{A: "v2", B: "v3", WinsA: 1, WinsB: 3},
+		{A: "v1", B: "v3", WinsB: 2},
+	})
+	assert.Len(t, ratings, 3)
+	assert.Greater(t, ratings["v3"], ratings["v2"])
+	assert.Greater(t, ratings["v2"], ratings["v1"])
+	assert.False(t, math.IsInf(ratings["v1"], 0))
+	assert.InDelta(t, 3000, ratings["v1"]+ratings["v2"]+ratings["v3"], 1e-6)
+
+	assert.Empty(t, BradleyTerry(nil))
+}
@@ -0,0 +1,352 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema. It supports the keywords most useful
+// for checking structured model output: type, enum, const, properties,
+// required, additionalProperties, items, minItems, maxItems, minLength,
+// maxLength, pattern, minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, allOf, anyOf, oneOf and not. Annotations such as
+// title and description are allowed; any other keyword, e.g. $ref or
+// format, makes compiling fail rather than being silently ignored.
+type Schema struct {
+	// reject is set for the false schema, which nothing conforms to
+	reject bool
+	// unsupported lists the keywords the schema uses that are not checked
+	unsupported []string
+
+	Type                 typeList           `json:"type"`
+	Enum                 []interface{}      `json:"enum"`
+	Const                json.RawMessage    `json:"const"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum"`
+	AllOf                []*Schema          `json:"allOf"`
+	AnyOf                []*Schema          `json:"anyOf"`
+	OneOf                []*Schema          `json:"oneOf"`
+	Not                  *Schema            `json:"not"`
+
+	pattern  *regexp.Regexp
+	constVal interface{}
+}
+
+// typeList accepts a single type name or a list of them
+type typeList []string
+
+func (t *typeList) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = typeList{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New("type must be a string or a list of strings")
+	}
+	*t = many
+	return nil
+}
+
+// annotations are keywords that describe a schema without affecting
+// validation
+var annotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true, "deprecated": true, "readOnly": true, "writeOnly": true,
+}
+
+// keywords are the keywords Schema validates, taken from its JSON tags
+var keywords = func() map[string]bool {
+	known := make(map[string]bool)
+	t := reflect.TypeOf(Schema{})
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get("json"); tag != "" {
+			known[tag] = true
+		}
+	}
+	return known
+}()
+
+// UnmarshalJSON accepts the boolean schemas true and false as well as
+// schema objects
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{reject: true}
+		return nil
+	}
+
+	type plain Schema
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for name := range fields {
+		if !keywords[name] && !annotations[name] {
+			s.unsupported = append(s.unsupported, name)
+		}
+	}
+	sort.Strings(s.unsupported)
+	return nil
+}
+
+// CompileSchema parses a JSON Schema document
+func CompileSchema(data []byte) (*Schema, error) {
+	s := &Schema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	if err := s.compile("$"); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// compile checks the schema's keywords and prepares them for validation
+func (s *Schema) compile(path string) error {
+	if len(s.unsupported) > 0 {
+		return fmt.Errorf("invalid schema at %s: unsupported keyword %q", path, s.unsupported[0])
+	}
+	for _, t := range s.Type {
+		switch t {
+		case "null", "boolean", "object", "array", "number", "integer", "string":
+		default:
+			return fmt.Errorf("invalid schema at %s: unknown type %q", path, t)
+		}
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid schema at %s: %w", path, err)
+		}
+		s.pattern = re
+	}
+	if len(s.Const) > 0 {
+		if err := json.Unmarshal(s.Const, &s.constVal); err != nil {
+			return fmt.Errorf("invalid schema at %s: %w", path, err)
+		}
+	}
+
+	for name, sub := range s.Properties {
+		if err := sub.compile(path + "." + name); err != nil {
+			return err
+		}
+	}
+	children := map[string]*Schema{"additionalProperties": s.AdditionalProperties, "items": s.Items, "not": s.Not}
+	for keyword, sub := range children {
+		if sub == nil {
+			continue
+		}
+		if err := sub.compile(path + "/" + keyword); err != nil {
+			return err
+		}
+	}
+	for keyword, subs := range map[string][]*Schema{"allOf": s.AllOf, "anyOf": s.AnyOf, "oneOf": s.OneOf} {
+		for i, sub := range subs {
+			if err := sub.compile(fmt.Sprintf("%s/%s[%d]", path, keyword, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SchemaError lists every way a document failed to conform to a schema
+type SchemaError struct {
+	Problems []string
+}
+
+func (e *SchemaError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// Validate checks a decoded JSON document against the schema
+func (s *Schema) Validate(v interface{}) error {
+	var problems []string
+	s.validate(v, "$", &problems)
+	if len(problems) > 0 {
+		return &SchemaError{Problems: problems}
+	}
+	return nil
+}
+
+// validate appends a problem for every keyword v fails at path
+func (s *Schema) validate(v interface{}, path string, problems *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if s.reject {
+		fail("is not allowed")
+		return
+	}
+	if len(s.Type) > 0 && !s.Type.matches(v) {
+		fail("must be %s", strings.Join(s.Type, " or "))
+		return
+	}
+	if len(s.Enum) > 0 && !containsValue(s.Enum, v) {
+		fail("must be one of the enumerated values")
+	}
+	if len(s.Const) > 0 && !reflect.DeepEqual(s.constVal, v) {
+		fail("must equal %s", s.Const)
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		s.validateObject(v, path, problems)
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("must match %s", s.Pattern)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("must be at least %g", *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("must be at most %g", *s.Maximum)
+		}
+		if s.ExclusiveMinimum != nil && v <= *s.ExclusiveMinimum {
+			fail("must be greater than %g", *s.ExclusiveMinimum)
+		}
+		if s.ExclusiveMaximum != nil && v >= *s.ExclusiveMaximum {
+			fail("must be less than %g", *s.ExclusiveMaximum)
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(v, path, problems)
+	}
+	if len(s.AnyOf) > 0 && countValid(s.AnyOf, v) == 0 {
+		fail("must match at least one schema in anyOf")
+	}
+	if len(s.OneOf) > 0 && countValid(s.OneOf, v) != 1 {
+		fail("must match exactly one schema in oneOf")
+	}
+	if s.Not != nil && s.Not.Validate(v) == nil {
+		fail("must not match the schema in not")
+	}
+}
+
+// validateObject checks the object keywords
+func (s *Schema) validateObject(v map[string]interface{}, path string, problems *[]string) {
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			*problems = append(*problems, path+"."+name+": is required")
+		}
+	}
+
+	// Visit properties in order so problems are reported consistently
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if sub, ok := s.Properties[name]; ok {
+			sub.validate(v[name], path+"."+name, problems)
+		} else if s.AdditionalProperties != nil {
+			s.AdditionalProperties.validate(v[name], path+"."+name, problems)
+		}
+	}
+}
+
+// matches reports whether v has one of the listed JSON types
+func (t typeList) matches(v interface{}) bool {
+	for _, name := range t {
+		switch v := v.(type) {
+		case nil:
+			if name == "null" {
+				return true
+			}
+		case bool:
+			if name == "boolean" {
+				return true
+			}
+		case map[string]interface{}:
+			if name == "object" {
+				return true
+			}
+		case []interface{}:
+			if name == "array" {
+				return true
+			}
+		case float64:
+			if name == "number" || (name == "integer" && v == math.Trunc(v)) {
+				return true
+			}
+		case string:
+			if name == "string" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsValue reports whether v equals one of values
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, value := range values {
+		if reflect.DeepEqual(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// countValid counts the schemas v conforms to
+func countValid(schemas []*Schema, v interface{}) int {
+	n := 0
+	for _, s := range schemas {
+		if s.Validate(v) == nil {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,85 @@
+package eval
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := CompileSchema([]byte(`{
+		"type": "object",
+		"required": ["sentiment", "confidence"],
+		"additionalProperties": false,
+		"properties": {
+			"sentiment": {"enum": ["positive", "negative", "neutral"]},
+			"confidence": {"type": "number", "minimum": 0, "maximum": 1},
+			"tags": {"type": "array", "maxItems": 2, "items": {"type": "string", "pattern": "^[a-z]+$"}},
+			"count": {"type": "integer"}
+		}
+	}`))
+	require.NoError(t, err)
+
+	decode := func(doc string) interface{} {
+		var v interface{}
+		require.NoError(t, json.Unmarshal([]byte(doc), &v))
+		return v
+	}
+
+	assert.NoError(t, schema.Validate(decode(`{"sentiment": "positive", "confidence": 0.9, "tags": ["billing"], "count": 3}`)))
+
+	err = schema.Validate(decode(`{"sentiment": "angry", "tags": ["a", "B", "c"], "count": 1.5, "extra": true}`))
+	var serr *SchemaError
+	require.ErrorAs(t, err, &serr)
+	assert.Equal(t, []string{
+		"$.confidence: is required",
+		"$.count: must be integer",
+		"$.extra: is not allowed",
+		"$.sentiment: must be one of the enumerated values",
+		"$.tags: must have at most 2 items",
+		"$.tags[1]: must match ^[a-z]+$",
+	}, serr.Problems)
+
+	assert.Error(t, schema.Validate(decode(`[]`)))
+}
+
+func TestSchemaCombinators(t *testing.T) {
+	schema, err := CompileSchema([]byte(`{
+		"oneOf": [{"type": "string"}, {"type": "integer"}],
+		"not": {"const": "skip"}
+	}`))
+	require.NoError(t, err)
+
+	assert.NoError(t, schema.Validate("hello"))
+	assert.NoError(t, schema.Validate(float64(3)))
+	assert.Error(t, schema.Validate(true))
+	assert.Error(t, schema.Validate("skip"))
+}
+
+func TestSchemaUnsupportedKeywords(t *testing.T) {
+	// Keywords that aren't checked would let every output pass, so they
+	// are rejected wherever they appear
+	for _, doc := range []string{
+		`{"$ref": "#/$defs/item", "$defs": {"item": {"type": "string"}}}`,
+		`{"type": "string", "format": "email"}`,
+		`{"properties": {"tags": {"items": {"uniqueItems": true}}}}`,
+		`{"anyOf": [{"type": "object", "dependentRequired": {"a": ["b"]}}]}`,
+	} {
+		_, err := CompileSchema([]byte(doc))
+		assert.ErrorContains(t, err, "unsupported keyword", doc)
+	}
+
+	// Annotations don't affect validation and are allowed
+	schema, err := CompileSchema([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "Answer",
+		"description": "A short answer",
+		"type": "string",
+		"examples": ["yes"]
+	}`))
+	require.NoError(t, err)
+	assert.NoError(t, schema.Validate("yes"))
+	assert.Error(t, schema.Validate(float64(1)))
+}
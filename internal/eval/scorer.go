@@ -0,0 +1,156 @@
+// Package eval contains the scorers that check model outputs automatically
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Manual is the scorer name recorded for scores entered by hand
+const Manual = "manual"
+
+var (
+	// ErrUnknownScorer is returned when no scorer is registered for a type
+	ErrUnknownScorer = errors.New("unknown scorer")
+	// ErrNotApplicable is returned by a scorer when a test case lacks what
+	// it needs, such as an expected output. No evaluation is recorded.
+	ErrNotApplicable = errors.New("scorer does not apply to this case")
+)
+
+// Case is a single test case: the output a model produced and what it was
+// produced from
+type Case struct {
+	// Input is the rendered prompt the model was given
+	Input string
+	// Variables are the template variables the prompt was rendered with
+	Variables map[string]interface{}
+	// Output is the model's reply
+	Output string
+	// Expected is the reference output, if the case has one
+	Expected *string
+}
+
+// Result is the outcome of scoring one case
+type Result struct {
+	// Passed reports whether the output met the scorer's check
+	Passed bool
+	// Score is between 0 and 1, where 1 is best
+	Score float64
+	// Reason explains the result
+	Reason string
+	// JudgeVersionID is the rubric prompt version a judge scorer graded
+	// with, and JudgeRunID the run that recorded the judge's reply
+	JudgeVersionID string
+	JudgeRunID     string
+}
+
+// Scorer checks a model output
+type Scorer interface {
+	// Name identifies the scorer in recorded evaluations
+	Name() string
+	// Score checks a case. It returns ErrNotApplicable when the case lacks
+	// what the scorer needs.
+	Score(ctx context.Context, c Case) (Result, error)
+}
+
+// Factory builds a scorer from its JSON configuration. The configuration
+// is the whole scorer spec, including its "type" and "name".
+type Factory func(ctx context.Context, config json.RawMessage) (Scorer, error)
+
+// spec holds the fields common to every scorer configuration
+type spec struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// Registry resolves scorer specs to scorers
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry creates a registry with the built-in scorers
+func NewRegistry() *Registry {
+	r := &Registry{factories: make(map[string]Factory)}
+	for name, f := range builtins {
+		r.Register(name, f)
+	}
+	return r
+}
+
+// Register adds a scorer type, replacing any existing one with the same
+// name
+func (r *Registry) Register(name string, f Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[name] = f
+}
+
+// Types returns the registered scorer types in name order
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// New builds the scorer described by a spec such as
+// {"type": "contains", "value": "refund"}. A "name" in the spec replaces
+// the type as the scorer's name in recorded evaluations.
+func (r *Registry) New(ctx context.Context, config json.RawMessage) (Scorer, error) {
+	var s spec
+	if err := json.Unmarshal(config, &s); err != nil {
+		return nil, fmt.Errorf("invalid scorer: %w", err)
+	}
+	if s.Type == "" {
+		return nil, errors.New("scorer type is required")
+	}
+
+	r.mu.RLock()
+	f, ok := r.factories[s.Type]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownScorer, s.Type)
+	}
+
+	scorer, err := f(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s scorer: %w", s.Type, err)
+	}
+	if s.Name != "" && s.Name != scorer.Name() {
+		scorer = named{Scorer: scorer, name: s.Name}
+	}
+	return scorer, nil
+}
+
+// NewAll builds a scorer for every spec
+func (r *Registry) NewAll(ctx context.Context, configs []json.RawMessage) ([]Scorer, error) {
+	scorers := make([]Scorer, len(configs))
+	for i, config := range configs {
+		scorer, err := r.New(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		scorers[i] = scorer
+	}
+	return scorers, nil
+}
+
+// named renames a scorer
+type named struct {
+	Scorer
+	name string
+}
+
+func (n named) Name() string {
+	return n.name
+}
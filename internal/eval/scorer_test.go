@@ -0,0 +1,96 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinScorers(t *testing.T) {
+	r := NewRegistry()
+	expected := func(s string) *string { return &s }
+
+	tests := []struct {
+		name   string
+		spec   string
+		c      Case
+		passed bool
+		score  float64
+	}{
+		{"exact match", `{"type":"exact_match"}`, Case{Output: " Paris\n", Expected: expected("Paris")}, true, 1},
+		{"exact match differs", `{"type":"exact_match"}`, Case{Output: "paris", Expected: expected("Paris")}, false, 0},
+		{"exact match ignoring case", `{"type":"exact_match","case_insensitive":true}`, Case{Output: "paris", Expected: expected("Paris")}, true, 1},
+		{"contains all", `{"type":"contains","values":["refund","30 days"]}`, Case{Output: "A refund within 30 days"}, true, 1},
+		{"contains some", `{"type":"contains","values":["refund","30 days"]}`, Case{Output: "A refund"}, false, 0.5},
+		{"contains expected", `{"type":"contains"}`, Case{Output: "It is Paris.", Expected: expected("Paris")}, true, 1},
+		{"not contains", `{"type":"not_contains","value":"sorry","case_insensitive":true}`, Case{Output: "Sorry, no"}, false, 0},
+		{"regex", `{"type":"regex","pattern":"^\\d{3}-\\d{4}$"}`, Case{Output: "555-1234"}, true, 1},
+		{"json valid", `{"type":"json_valid"}`, Case{Output: `{"a": 1}`}, true, 1},
+		{"json invalid", `{"type":"json_valid"}`, Case{Output: `{"a": 1`}, false, 0},
+		{"json schema", `{"type":"json_schema","schema":{"type":"object","required":["a"]}}`, Case{Output: `{"b": 1}`}, false, 0},
+		{"length in words", `{"type":"length","max":3,"unit":"words"}`, Case{Output: "one two three"}, true, 1},
+		{"too short", `{"type":"length","min":10}`, Case{Output: "short"}, false, 0},
+		{"numeric exact", `{"type":"numeric"}`, Case{Output: "The answer is 42.", Expected: expected("42")}, true, 1},
+		{"numeric close", `{"type":"numeric","expected":100,"relative_tolerance":0.05}`, Case{Output: "96"}, true, 0.96},
+		{"numeric far", `{"type":"numeric","expected":100,"tolerance":1}`, Case{Output: "1,050"}, false, 100.0 / 1050},
+		{"no number", `{"type":"numeric","expected":1}`, Case{Output: "none"}, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scorer, err := r.New(context.Background(), json.RawMessage(tt.spec))
+			require.NoError(t, err)
+
+			result, err := scorer.Score(context.Background(), tt.c)
+			require.NoError(t, err)
+			assert.Equal(t, tt.passed, result.Passed, result.Reason)
+			assert.InDelta(t, tt.score, result.Score, 1e-9)
+			assert.NotEmpty(t, result.Reason)
+		})
+	}
+}
+
+func TestScorersWithoutExpectedOutput(t *testing.T) {
+	r := NewRegistry()
+	for _, spec := range []string{`{"type":"exact_match"}`, `{"type":"contains"}`, `{"type":"numeric"}`} {
+		scorer, err := r.New(context.Background(), json.RawMessage(spec))
+		require.NoError(t, err)
+		_, err = scorer.Score(context.Background(), Case{Output: "anything"})
+		assert.ErrorIs(t, err, ErrNotApplicable, spec)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	// Specs are validated when scorers are built
+	for _, spec := range []string{
+		`{}`,
+		`{"type":"regex"}`,
+		`{"type":"regex","pattern":"("}`,
+		`{"type":"length"}`,
+		`{"type":"length","min":5,"max":1}`,
+		`{"type":"json_schema","schema":{"type":"text"}}`,
+		`{"type":"json_schema","schema":{"properties":{"id":{"format":"uuid"}}}}`,
+	} {
+		_, err := r.New(context.Background(), json.RawMessage(spec))
+		assert.Error(t, err, spec)
+	}
+	_, err := r.New(context.Background(), json.RawMessage(`{"type":"sentiment"}`))
+	assert.ErrorIs(t, err, ErrUnknownScorer)
+
+	// Custom scorers can be registered and scorers renamed
+	r.Register("sentiment", func(ctx context.Context, config json.RawMessage) (Scorer, error) {
+		return jsonValid{}, nil
+	})
+	scorers, err := r.NewAll(context.Background(), []json.RawMessage{
+		json.RawMessage(`{"type":"sentiment"}`),
+		json.RawMessage(`{"type":"contains","value":"refund","name":"mentions_refund"}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "json_valid", scorers[0].Name())
+	assert.Equal(t, "mentions_refund", scorers[1].Name())
+	assert.Contains(t, r.Types(), "sentiment")
+}
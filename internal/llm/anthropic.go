@@ -0,0 +1,211 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+const (
+	defaultAnthropicBaseURL   = "https://api.anthropic.com/v1"
+	defaultAnthropicMaxTokens = 1024
+	anthropicVersion          = "2023-06-01"
+)
+
+// AnthropicProvider talks to the Anthropic Messages API
+type AnthropicProvider struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// NewAnthropicProvider creates an Anthropic provider. An empty baseURL
+// uses the public Anthropic endpoint.
+func NewAnthropicProvider(baseURL, apiKey string) *AnthropicProvider {
+	if baseURL == "" {
+		baseURL = defaultAnthropicBaseURL
+	}
+	return &AnthropicProvider{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		APIKey:  apiKey,
+	}
+}
+
+// Name returns the provider name
+func (p *AnthropicProvider) Name() string {
+	return "anthropic"
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature *float64           `json:"temperature,omitempty"`
+	TopP        *float64           `json:"top_p,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+type anthropicResponse struct {
+	Model   string `json:"model"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage anthropicUsage `json:"usage"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// anthropicEvent covers the fields used from every streaming event type
+type anthropicEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Model string         `json:"model"`
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Usage anthropicUsage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Complete sends a Messages API request
+func (p *AnthropicProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	httpReq, err := p.newRequest(ctx, p.buildRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var out anthropicResponse
+	if err := doJSON(p.HTTPClient, p.Name(), httpReq, &out); err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	for _, block := range out.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+
+	return &Response{
+		Content: text.String(),
+		Model:   out.Model,
+		Usage:   out.Usage.toModel(),
+	}, nil
+}
+
+// Stream sends a streaming Messages API request. Input tokens are reported
+// in message_start and output tokens in the final message_delta.
+func (p *AnthropicProvider) Stream(ctx context.Context, req Request, onDelta DeltaFunc) (*Response, error) {
+	body := p.buildRequest(req)
+	body.Stream = true
+
+	httpReq, err := p.newRequest(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := do(p.HTTPClient, p.Name(), httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &Response{Model: req.Model}
+	var usage anthropicUsage
+	var content strings.Builder
+	err = readSSE(resp.Body, func(_, data string) error {
+		var ev anthropicEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return fmt.Errorf("%s: failed to decode event: %w", p.Name(), err)
+		}
+
+		switch ev.Type {
+		case "message_start":
+			result.Model = ev.Message.Model
+			usage.InputTokens = ev.Message.Usage.InputTokens
+		case "content_block_delta":
+			if ev.Delta.Type != "text_delta" || ev.Delta.Text == "" {
+				return nil
+			}
+			content.WriteString(ev.Delta.Text)
+			return onDelta(ev.Delta.Text)
+		case "message_delta":
+			usage.OutputTokens = ev.Usage.OutputTokens
+		case "error":
+			return fmt.Errorf("%s: stream error: %s: %s", p.Name(), ev.Error.Type, ev.Error.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Content = content.String()
+	result.Usage = usage.toModel()
+	return result, nil
+}
+
+// newRequest builds an authenticated Messages API request
+func (p *AnthropicProvider) newRequest(ctx context.Context, body anthropicRequest) (*http.Request, error) {
+	httpReq, err := newJSONRequest(ctx, p.BaseURL+"/messages", body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("x-api-key", p.APIKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	return httpReq, nil
+}
+
+func (u anthropicUsage) toModel() models.Usage {
+	return models.Usage{
+		PromptTokens:     u.InputTokens,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      u.InputTokens + u.OutputTokens,
+	}
+}
+
+// buildRequest converts a Request into the Messages API wire format.
+// System messages are hoisted into the top-level system field because the
+// API does not accept them inside the message list.
+func (p *AnthropicProvider) buildRequest(req Request) anthropicRequest {
+	body := anthropicRequest{
+		Model:       req.Model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+	}
+	if body.MaxTokens <= 0 {
+		body.MaxTokens = defaultAnthropicMaxTokens
+	}
+
+	var system []string
+	for _, msg := range req.Messages {
+		if msg.Role == models.SystemRole {
+			system = append(system, msg.Content)
+			continue
+		}
+		body.Messages = append(body.Messages, anthropicMessage{Role: string(msg.Role), Content: msg.Content})
+	}
+	body.System = strings.Join(system, "\n\n")
+	return body
+}
@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// FakeProvider is a deterministic, offline provider for tests and local
+// development. It echoes the last user message with whitespace collapsed
+// and counts whitespace separated words as tokens.
+type FakeProvider struct{}
+
+// NewFakeProvider creates a fake provider
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{}
+}
+
+// Name returns the provider name
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+// Complete returns a canned completion derived from the request
+func (p *FakeProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var prompt, last string
+	for _, msg := range req.Messages {
+		prompt += msg.Content + " "
+		if msg.Role == models.UserRole {
+			last = msg.Content
+		}
+	}
+
+	words := strings.Fields("echo: " + last)
+	if req.MaxTokens > 0 && len(words) > req.MaxTokens {
+		words = words[:req.MaxTokens]
+	}
+	content := strings.Join(words, " ")
+
+	promptTokens := len(strings.Fields(prompt))
+	return &Response{
+		Content: content,
+		Model:   req.Model,
+		Usage: models.Usage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: len(words),
+			TotalTokens:      promptTokens + len(words),
+		},
+	}, nil
+}
+
+// Stream delivers the fake completion one word at a time
+func (p *FakeProvider) Stream(ctx context.Context, req Request, onDelta DeltaFunc) (*Response, error) {
+	resp, err := p.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, word := range strings.Fields(resp.Content) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			word = " " + word
+		}
+		if err := onDelta(word); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
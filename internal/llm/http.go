@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// defaultHTTPClient is shared by providers that are not given their own
+// client. There is no overall timeout because streamed completions can run
+// for a long time; callers bound requests through their context instead,
+// and must do so for Complete, whose body can otherwise stall forever.
+var defaultHTTPClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: 120 * time.Second,
+	},
+}
+
+// APIError is returned when a provider responds with a non-2xx status
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: unexpected status %d: %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// newJSONRequest builds a POST request with a JSON encoded body
+func newJSONRequest(ctx context.Context, url string, body interface{}) (*http.Request, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// do sends the request and returns the response if it has a 2xx status.
+// The caller must close the response body.
+func do(client *http.Client, provider string, req *http.Request) (*http.Response, error) {
+	if client == nil {
+		client = defaultHTTPClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: request failed: %w", provider, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &APIError{Provider: provider, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return resp, nil
+}
+
+// doJSON sends the request and decodes a successful JSON response into out
+func doJSON(client *http.Client, provider string, req *http.Request, out interface{}) error {
+	resp, err := do(client, provider, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s: failed to decode response: %w", provider, err)
+	}
+	return nil
+}
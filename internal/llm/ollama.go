@@ -0,0 +1,148 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// OllamaProvider talks to a local Ollama server's chat API
+type OllamaProvider struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewOllamaProvider creates an Ollama provider for the given host,
+// e.g. "http://localhost:11434"
+func NewOllamaProvider(host string) *OllamaProvider {
+	if !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://") {
+		host = "http://" + host
+	}
+	return &OllamaProvider{
+		BaseURL: strings.TrimRight(host, "/"),
+	}
+}
+
+// Name returns the provider name
+func (p *OllamaProvider) Name() string {
+	return "ollama"
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"`
+}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  ollamaOptions   `json:"options"`
+}
+
+type ollamaResponse struct {
+	Model           string        `json:"model"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+}
+
+// Complete sends a non-streaming chat request
+func (p *OllamaProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	httpReq, err := newJSONRequest(ctx, p.BaseURL+"/api/chat", p.buildRequest(req, false))
+	if err != nil {
+		return nil, err
+	}
+
+	var out ollamaResponse
+	if err := doJSON(p.HTTPClient, p.Name(), httpReq, &out); err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		Content: out.Message.Content,
+		Model:   out.Model,
+		Usage: models.Usage{
+			PromptTokens:     out.PromptEvalCount,
+			CompletionTokens: out.EvalCount,
+			TotalTokens:      out.PromptEvalCount + out.EvalCount,
+		},
+	}, nil
+}
+
+// Stream sends a streaming chat request. Ollama streams newline delimited
+// JSON objects and reports token counts on the final one.
+func (p *OllamaProvider) Stream(ctx context.Context, req Request, onDelta DeltaFunc) (*Response, error) {
+	httpReq, err := newJSONRequest(ctx, p.BaseURL+"/api/chat", p.buildRequest(req, true))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := do(p.HTTPClient, p.Name(), httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &Response{Model: req.Model}
+	var content strings.Builder
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk ollamaResponse
+		if err := decoder.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("%s: failed to decode chunk: %w", p.Name(), err)
+		}
+
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			if err := onDelta(chunk.Message.Content); err != nil {
+				return nil, err
+			}
+		}
+		if chunk.Done {
+			result.Model = chunk.Model
+			result.Usage = models.Usage{
+				PromptTokens:     chunk.PromptEvalCount,
+				CompletionTokens: chunk.EvalCount,
+				TotalTokens:      chunk.PromptEvalCount + chunk.EvalCount,
+			}
+			break
+		}
+	}
+
+	result.Content = content.String()
+	return result, nil
+}
+
+// buildRequest converts a Request into the Ollama wire format
+func (p *OllamaProvider) buildRequest(req Request, stream bool) ollamaRequest {
+	body := ollamaRequest{
+		Model:    req.Model,
+		Messages: make([]ollamaMessage, len(req.Messages)),
+		Stream:   stream,
+		Options: ollamaOptions{
+			Temperature: req.Temperature,
+			TopP:        req.TopP,
+			NumPredict:  req.MaxTokens,
+		},
+	}
+	for i, msg := range req.Messages {
+		body.Messages[i] = ollamaMessage{Role: string(msg.Role), Content: msg.Content}
+	}
+	return body
+}
@@ -0,0 +1,193 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+const defaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// OpenAIProvider talks to any OpenAI-compatible chat completions API
+type OpenAIProvider struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// NewOpenAIProvider creates an OpenAI-compatible provider. An empty
+// baseURL uses the public OpenAI endpoint.
+func NewOpenAIProvider(baseURL, apiKey string) *OpenAIProvider {
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	return &OpenAIProvider{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		APIKey:  apiKey,
+	}
+}
+
+// Name returns the provider name
+func (p *OpenAIProvider) Name() string {
+	return "openai"
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Temperature *float64        `json:"temperature,omitempty"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+	TopP        *float64        `json:"top_p,omitempty"`
+
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (u openAIUsage) toModel() models.Usage {
+	return models.Usage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+}
+
+type openAIResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Usage openAIUsage `json:"usage"`
+}
+
+type openAIChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage"`
+}
+
+// Complete sends a chat completion request
+func (p *OpenAIProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	httpReq, err := p.newRequest(ctx, p.buildRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var out openAIResponse
+	if err := doJSON(p.HTTPClient, p.Name(), httpReq, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Choices) == 0 {
+		return nil, fmt.Errorf("%s: response contained no choices", p.Name())
+	}
+
+	return &Response{
+		Content: out.Choices[0].Message.Content,
+		Model:   out.Model,
+		Usage:   out.Usage.toModel(),
+	}, nil
+}
+
+// Stream sends a streaming chat completion request. Usage is requested
+// through stream_options and arrives in the final chunk.
+func (p *OpenAIProvider) Stream(ctx context.Context, req Request, onDelta DeltaFunc) (*Response, error) {
+	body := p.buildRequest(req)
+	body.Stream = true
+	body.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+
+	httpReq, err := p.newRequest(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := do(p.HTTPClient, p.Name(), httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &Response{Model: req.Model}
+	var content strings.Builder
+	err = readSSE(resp.Body, func(_, data string) error {
+		if data == "[DONE]" {
+			return nil
+		}
+
+		var chunk openAIChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("%s: failed to decode chunk: %w", p.Name(), err)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			result.Usage = chunk.Usage.toModel()
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Content = content.String()
+	return result, nil
+}
+
+// newRequest builds an authenticated chat completions request
+func (p *OpenAIProvider) newRequest(ctx context.Context, body openAIRequest) (*http.Request, error) {
+	httpReq, err := newJSONRequest(ctx, p.BaseURL+"/chat/completions", body)
+	if err != nil {
+		return nil, err
+	}
+	if p.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+	return httpReq, nil
+}
+
+// buildRequest converts a Request into the OpenAI wire format. Unset
+// sampling parameters are omitted so the API defaults apply.
+func (p *OpenAIProvider) buildRequest(req Request) openAIRequest {
+	body := openAIRequest{
+		Model:       req.Model,
+		Messages:    make([]openAIMessage, len(req.Messages)),
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+	}
+	for i, msg := range req.Messages {
+		body.Messages[i] = openAIMessage{Role: string(msg.Role), Content: msg.Content}
+	}
+	return body
+}
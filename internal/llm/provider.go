@@ -0,0 +1,120 @@
+// Package llm contains the model providers used to run prompts
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// ErrUnknownProvider is returned when no provider matches a model name
+var ErrUnknownProvider = errors.New("unknown model provider")
+
+// Request is a provider-agnostic chat completion request. Temperature and
+// TopP are only sent when set, so nil means the provider's default.
+type Request struct {
+	Model       string
+	Messages    []models.Message
+	Temperature *float64
+	MaxTokens   int
+	TopP        *float64
+}
+
+// Response is a provider-agnostic chat completion result
+type Response struct {
+	Content string
+	Model   string
+	Usage   models.Usage
+}
+
+// Provider sends chat completion requests to a model backend
+type Provider interface {
+	// Name returns the prefix used to select this provider, e.g. "openai"
+	Name() string
+	// Complete runs the request and returns the full completion
+	Complete(ctx context.Context, req Request) (*Response, error)
+}
+
+// Registry resolves model names to providers
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+	fallback  string
+}
+
+// NewRegistry creates an empty registry. Models without an explicit
+// provider prefix are routed to the fallback provider.
+func NewRegistry(fallback string) *Registry {
+	return &Registry{
+		providers: make(map[string]Provider),
+		fallback:  fallback,
+	}
+}
+
+// NewRegistryFromEnv creates a registry with every provider that is
+// configured in the environment. The fake provider is always available.
+func NewRegistryFromEnv() *Registry {
+	r := NewRegistry(os.Getenv("LLM_DEFAULT_PROVIDER"))
+	r.Register(NewFakeProvider())
+
+	if key := os.Getenv("OPENAI_API_KEY"); key != "" {
+		r.Register(NewOpenAIProvider(os.Getenv("OPENAI_BASE_URL"), key))
+	}
+	if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
+		r.Register(NewAnthropicProvider(os.Getenv("ANTHROPIC_BASE_URL"), key))
+	}
+	if host := os.Getenv("OLLAMA_HOST"); host != "" {
+		r.Register(NewOllamaProvider(host))
+	}
+
+	return r
+}
+
+// Register adds a provider, replacing any existing one with the same name
+func (r *Registry) Register(p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[p.Name()] = p
+}
+
+// Resolve picks the provider for a model and returns the model name with
+// any provider prefix removed. Models may be written as "provider/model"
+// (e.g. "ollama/llama3"); otherwise well-known name prefixes are used.
+func (r *Registry) Resolve(model string) (Provider, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if name, rest, ok := strings.Cut(model, "/"); ok {
+		if p, found := r.providers[name]; found {
+			return p, rest, nil
+		}
+	}
+
+	name := inferProvider(model)
+	if name == "" {
+		name = r.fallback
+	}
+
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, "", fmt.Errorf("%w for model %q", ErrUnknownProvider, model)
+	}
+	return p, model, nil
+}
+
+// inferProvider guesses the provider from well-known model name prefixes
+func inferProvider(model string) string {
+	switch {
+	case strings.HasPrefix(model, "gpt-"), strings.HasPrefix(model, "o1"), strings.HasPrefix(model, "o3"):
+		return "openai"
+	case strings.HasPrefix(model, "claude-"):
+		return "anthropic"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,155 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMessages = []models.Message{
+	{Role: models.SystemRole, Content: "You are terse."},
+	{Role: models.UserRole, Content: "Say hello"},
+}
+
+func TestRegistryResolve(t *testing.T) {
+	r := NewRegistry("ollama")
+	r.Register(NewFakeProvider())
+	r.Register(NewOpenAIProvider("", "key"))
+	r.Register(NewOllamaProvider("localhost:11434"))
+
+	tests := []struct {
+		model    string
+		provider string
+		name     string
+	}{
+		{"fake/echo", "fake", "echo"},
+		{"gpt-4o", "openai", "gpt-4o"},
+		{"openai/my-finetune", "openai", "my-finetune"},
+		{"llama3", "ollama", "llama3"},
+	}
+	for _, tt := range tests {
+		p, name, err := r.Resolve(tt.model)
+		require.NoError(t, err, tt.model)
+		assert.Equal(t, tt.provider, p.Name())
+		assert.Equal(t, tt.name, name)
+	}
+
+	_, _, err := r.Resolve("claude-3-5-sonnet")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
+
+func TestOpenAIProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var body openAIRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "gpt-4o", body.Model)
+		assert.Len(t, body.Messages, 2)
+		assert.Equal(t, 64, body.MaxTokens)
+		// An explicit zero temperature is sent rather than dropped
+		require.NotNil(t, body.Temperature)
+		assert.Equal(t, 0.0, *body.Temperature)
+		assert.Nil(t, body.TopP)
+
+		w.Write([]byte(`{"model":"gpt-4o","choices":[{"message":{"role":"assistant","content":"hello"}}],
+			"usage":{"prompt_tokens":12,"completion_tokens":1,"total_tokens":13}}`))
+	}))
+	defer srv.Close()
+
+	temperature := 0.0
+	p := NewOpenAIProvider(srv.URL, "secret")
+	resp, err := p.Complete(context.Background(), Request{
+		Model:       "gpt-4o",
+		Messages:    testMessages,
+		Temperature: &temperature,
+		MaxTokens:   64,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", resp.Content)
+	assert.Equal(t, models.Usage{PromptTokens: 12, CompletionTokens: 1, TotalTokens: 13}, resp.Usage)
+}
+
+func TestAnthropicProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/messages", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("x-api-key"))
+		assert.Equal(t, anthropicVersion, r.Header.Get("anthropic-version"))
+
+		var body anthropicRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "You are terse.", body.System)
+		assert.Len(t, body.Messages, 1)
+		assert.Equal(t, defaultAnthropicMaxTokens, body.MaxTokens)
+		assert.Nil(t, body.Temperature)
+
+		w.Write([]byte(`{"model":"claude-3-5-sonnet","content":[{"type":"text","text":"hel"},{"type":"text","text":"lo"}],
+			"usage":{"input_tokens":10,"output_tokens":2}}`))
+	}))
+	defer srv.Close()
+
+	p := NewAnthropicProvider(srv.URL, "secret")
+	resp, err := p.Complete(context.Background(), Request{Model: "claude-3-5-sonnet", Messages: testMessages})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", resp.Content)
+	assert.Equal(t, models.Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}, resp.Usage)
+}
+
+func TestOllamaProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+
+		var body ollamaRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.False(t, body.Stream)
+		assert.Equal(t, 32, body.Options.NumPredict)
+		require.NotNil(t, body.Options.TopP)
+		assert.Equal(t, 0.0, *body.Options.TopP)
+
+		w.Write([]byte(`{"model":"llama3","message":{"role":"assistant","content":"hello"},"done":true,
+			"prompt_eval_count":8,"eval_count":3}`))
+	}))
+	defer srv.Close()
+
+	topP := 0.0
+	p := NewOllamaProvider(srv.URL)
+	resp, err := p.Complete(context.Background(), Request{Model: "llama3", Messages: testMessages, MaxTokens: 32, TopP: &topP})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", resp.Content)
+	assert.Equal(t, models.Usage{PromptTokens: 8, CompletionTokens: 3, TotalTokens: 11}, resp.Usage)
+}
+
+func TestProviderAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"bad key"}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	p := NewOpenAIProvider(srv.URL, "wrong")
+	_, err := p.Complete(context.Background(), Request{Model: "gpt-4o", Messages: testMessages})
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+}
+
+func TestFakeProviderIsDeterministic(t *testing.T) {
+	p := NewFakeProvider()
+	req := Request{Model: "echo", Messages: testMessages}
+
+	first, err := p.Complete(context.Background(), req)
+	require.NoError(t, err)
+	second, err := p.Complete(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, "echo: Say hello", first.Content)
+	assert.Equal(t, models.Usage{PromptTokens: 5, CompletionTokens: 3, TotalTokens: 8}, first.Usage)
+}
@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// DeltaFunc receives each chunk of generated text as it arrives. Returning
+// an error aborts the stream.
+type DeltaFunc func(delta string) error
+
+// StreamingProvider is implemented by providers that can relay tokens as
+// they are generated
+type StreamingProvider interface {
+	Provider
+	// Stream runs the request, calling onDelta for every text chunk, and
+	// returns the assembled completion once the upstream stream ends
+	Stream(ctx context.Context, req Request, onDelta DeltaFunc) (*Response, error)
+}
+
+// Stream runs the request on p, streaming deltas when the provider
+// supports it. Providers without streaming support deliver their whole
+// completion as a single delta.
+func Stream(ctx context.Context, p Provider, req Request, onDelta DeltaFunc) (*Response, error) {
+	if sp, ok := p.(StreamingProvider); ok {
+		return sp.Stream(ctx, req, onDelta)
+	}
+
+	resp, err := p.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := onDelta(resp.Content); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// readSSE parses a server-sent event stream, calling fn with the event
+// name and data of every event. Parsing stops at EOF or when fn fails.
+func readSSE(r io.Reader, fn func(event, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				if err := fn(event, strings.Join(data, "\n")); err != nil {
+					return err
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		return fn(event, strings.Join(data, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collect streams req through p and returns every delta received
+func collect(t *testing.T, p Provider, req Request) ([]string, *Response) {
+	var deltas []string
+	resp, err := Stream(context.Background(), p, req, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	require.NoError(t, err)
+	return deltas, resp
+}
+
+func TestOpenAIStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body openAIRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.True(t, body.Stream)
+		require.NotNil(t, body.StreamOptions)
+		assert.True(t, body.StreamOptions.IncludeUsage)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"model\":\"gpt-4o\",\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n"))
+		w.Write([]byte("data: {\"model\":\"gpt-4o\",\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n"))
+		w.Write([]byte("data: {\"model\":\"gpt-4o\",\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n"))
+		w.Write([]byte("data: {\"model\":\"gpt-4o\",\"choices\":[],\"usage\":{\"prompt_tokens\":4,\"completion_tokens\":2,\"total_tokens\":6}}\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	deltas, resp := collect(t, NewOpenAIProvider(srv.URL, "key"), Request{Model: "gpt-4o", Messages: testMessages})
+	assert.Equal(t, []string{"Hel", "lo"}, deltas)
+	assert.Equal(t, "Hello", resp.Content)
+	assert.Equal(t, models.Usage{PromptTokens: 4, CompletionTokens: 2, TotalTokens: 6}, resp.Usage)
+}
+
+func TestAnthropicStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"model\":\"claude-3-5-sonnet\",\"usage\":{\"input_tokens\":9,\"output_tokens\":1}}}\n\n"))
+		w.Write([]byte("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\n\n"))
+		w.Write([]byte("event: ping\ndata: {\"type\":\"ping\"}\n\n"))
+		w.Write([]byte("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\" there\"}}\n\n"))
+		w.Write([]byte("event: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":3}}\n\n"))
+		w.Write([]byte("event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"))
+	}))
+	defer srv.Close()
+
+	deltas, resp := collect(t, NewAnthropicProvider(srv.URL, "key"), Request{Model: "claude-3-5-sonnet", Messages: testMessages})
+	assert.Equal(t, []string{"Hi", " there"}, deltas)
+	assert.Equal(t, "Hi there", resp.Content)
+	assert.Equal(t, models.Usage{PromptTokens: 9, CompletionTokens: 3, TotalTokens: 12}, resp.Usage)
+}
+
+func TestOllamaStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"llama3","message":{"role":"assistant","content":"Hi"},"done":false}` + "\n"))
+		w.Write([]byte(`{"model":"llama3","message":{"role":"assistant","content":"!"},"done":false}` + "\n"))
+		w.Write([]byte(`{"model":"llama3","message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":5,"eval_count":2}` + "\n"))
+	}))
+	defer srv.Close()
+
+	deltas, resp := collect(t, NewOllamaProvider(srv.URL), Request{Model: "llama3", Messages: testMessages})
+	assert.Equal(t, []string{"Hi", "!"}, deltas)
+	assert.Equal(t, "Hi!", resp.Content)
+	assert.Equal(t, models.Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7}, resp.Usage)
+}
+
+func TestFakeStream(t *testing.T) {
+	deltas, resp := collect(t, NewFakeProvider(), Request{Model: "echo", Messages: testMessages})
+	assert.Equal(t, []string{"echo:", " Say", " hello"}, deltas)
+	assert.Equal(t, "echo: Say hello", resp.Content)
+}
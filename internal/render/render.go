@@ -0,0 +1,107 @@
+// Package render detects and substitutes {{variable}} placeholders in
+// prompt messages
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// placeholder matches {{name}} with optional inner whitespace. Names start
+// with a letter or underscore and may contain letters, digits, _ and .
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.]*)\s*\}\}`)
+
+// Error reports variables that were required but not supplied, or supplied
+// but not used by any message
+type Error struct {
+	Missing []string `json:"missing,omitempty"`
+	Unknown []string `json:"unknown,omitempty"`
+}
+
+func (e *Error) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing variables: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Unknown) > 0 {
+		parts = append(parts, "unknown variables: "+strings.Join(e.Unknown, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Variables returns the distinct variable names used by the messages, in
+// order of first appearance
+func Variables(msgs []models.Message) []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, msg := range msgs {
+		for _, match := range placeholder.FindAllStringSubmatch(msg.Content, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	return names
+}
+
+// Render substitutes every placeholder with its value and returns new
+// messages. It fails with *Error if a placeholder has no value or a value
+// has no placeholder.
+func Render(msgs []models.Message, vars map[string]interface{}) ([]models.Message, error) {
+	used := Variables(msgs)
+
+	renderErr := &Error{}
+	for _, name := range used {
+		if _, ok := vars[name]; !ok {
+			renderErr.Missing = append(renderErr.Missing, name)
+		}
+	}
+	known := make(map[string]bool, len(used))
+	for _, name := range used {
+		known[name] = true
+	}
+	for name := range vars {
+		if !known[name] {
+			renderErr.Unknown = append(renderErr.Unknown, name)
+		}
+	}
+	if len(renderErr.Missing) > 0 || len(renderErr.Unknown) > 0 {
+		sort.Strings(renderErr.Unknown)
+		return nil, renderErr
+	}
+
+	rendered := make([]models.Message, len(msgs))
+	for i, msg := range msgs {
+		rendered[i] = models.Message{
+			Role: msg.Role,
+			Content: placeholder.ReplaceAllStringFunc(msg.Content, func(match string) string {
+				name := placeholder.FindStringSubmatch(match)[1]
+				return FormatValue(vars[name])
+			}),
+		}
+	}
+	return rendered, nil
+}
+
+// FormatValue converts a variable value to the text substituted into a
+// message. Strings are used verbatim; everything else is JSON encoded.
+func FormatValue(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case nil:
+		return ""
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(encoded)
+	}
+}
@@ -0,0 +1,49 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var messages = []models.Message{
+	{Role: models.SystemRole, Content: "You answer questions about {{ company }}."},
+	{Role: models.UserRole, Content: "{{question}} (max {{max_words}} words, {{company}})"},
+}
+
+func TestVariables(t *testing.T) {
+	assert.Equal(t, []string{"company", "question", "max_words"}, Variables(messages))
+	assert.Equal(t, []string{}, Variables([]models.Message{{Role: models.UserRole, Content: "no {{ 1bad }} vars"}}))
+}
+
+func TestRender(t *testing.T) {
+	rendered, err := Render(messages, map[string]interface{}{
+		"company":   "Acme",
+		"question":  "What is the refund policy?",
+		"max_words": 50,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []models.Message{
+		{Role: models.SystemRole, Content: "You answer questions about Acme."},
+		{Role: models.UserRole, Content: "What is the refund policy? (max 50 words, Acme)"},
+	}, rendered)
+
+	// The input is left untouched
+	assert.Equal(t, "You answer questions about {{ company }}.", messages[0].Content)
+}
+
+func TestRenderErrors(t *testing.T) {
+	_, err := Render(messages, map[string]interface{}{
+		"company": "Acme",
+		"tone":    "friendly",
+		"extra":   true,
+	})
+
+	var renderErr *Error
+	require.ErrorAs(t, err, &renderErr)
+	assert.Equal(t, []string{"question", "max_words"}, renderErr.Missing)
+	assert.Equal(t, []string{"extra", "tone"}, renderErr.Unknown)
+	assert.Equal(t, "missing variables: question, max_words; unknown variables: extra, tone", err.Error())
+}
@@ -0,0 +1,189 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+)
+
+// validName matches the variable names accepted inside placeholders
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// ValidationError lists every field that failed validation
+type ValidationError struct {
+	Fields []models.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// add records a field error
+func (e *ValidationError) add(field, format string, args ...interface{}) {
+	e.Fields = append(e.Fields, models.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// orNil returns e if any field failed, nil otherwise
+func (e *ValidationError) orNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// FieldErrors converts missing and unknown variables into field errors
+func (e *Error) FieldErrors() []models.FieldError {
+	var fields []models.FieldError
+	for _, name := range e.Missing {
+		fields = append(fields, models.FieldError{Field: "variables." + name, Message: "is required"})
+	}
+	for _, name := range e.Unknown {
+		fields = append(fields, models.FieldError{Field: "variables." + name, Message: "is not used by any message"})
+	}
+	return fields
+}
+
+// ValidateSchema checks that the input declarations are well formed and
+// that every variable used in msgs is declared
+func ValidateSchema(inputs []models.Input, msgs []models.Message) error {
+	verr := &ValidationError{}
+	declared := make(map[string]bool, len(inputs))
+
+	for i, input := range inputs {
+		field := fmt.Sprintf("inputs[%d]", i)
+
+		if !validName.MatchString(input.Name) {
+			verr.add(field+".name", "must start with a letter or underscore and contain only letters, digits, _ and .")
+		} else if declared[input.Name] {
+			verr.add(field+".name", "%q is declared more than once", input.Name)
+		}
+		declared[input.Name] = true
+
+		switch input.Type {
+		case "", models.StringInput, models.NumberInput, models.IntegerInput, models.BooleanInput:
+		default:
+			verr.add(field+".type", "must be one of string, number, integer or boolean")
+			continue
+		}
+
+		if input.Default != nil {
+			if msg := checkValue(input, input.Default); msg != "" {
+				verr.add(field+".default", msg)
+			}
+		}
+		for j, value := range input.Enum {
+			if msg := checkType(input.Type, value); msg != "" {
+				verr.add(fmt.Sprintf("%s.enum[%d]", field, j), msg)
+			}
+		}
+	}
+
+	for _, name := range Variables(msgs) {
+		if !declared[name] {
+			verr.add("inputs", "variable %q is used in messages but not declared", name)
+		}
+	}
+
+	return verr.orNil()
+}
+
+// Resolve validates caller supplied values against the schema and returns
+// the complete variable map with defaults applied
+func Resolve(inputs []models.Input, vars map[string]interface{}) (map[string]interface{}, error) {
+	verr := &ValidationError{}
+	resolved := make(map[string]interface{}, len(inputs))
+	declared := make(map[string]bool, len(inputs))
+
+	for _, input := range inputs {
+		declared[input.Name] = true
+		field := "variables." + input.Name
+
+		value, ok := vars[input.Name]
+		if !ok || value == nil {
+			switch {
+			case input.Default != nil:
+				resolved[input.Name] = input.Default
+			case input.Required:
+				verr.add(field, "is required")
+			default:
+				resolved[input.Name] = ""
+			}
+			continue
+		}
+
+		if msg := checkValue(input, value); msg != "" {
+			verr.add(field, msg)
+			continue
+		}
+		resolved[input.Name] = value
+	}
+
+	var unknown []string
+	for name := range vars {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		verr.add("variables."+name, "is not a declared input")
+	}
+
+	if err := verr.orNil(); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// checkValue returns why value is not acceptable for input, or ""
+func checkValue(input models.Input, value interface{}) string {
+	if msg := checkType(input.Type, value); msg != "" {
+		return msg
+	}
+	if len(input.Enum) == 0 {
+		return ""
+	}
+	for _, allowed := range input.Enum {
+		if reflect.DeepEqual(allowed, value) {
+			return ""
+		}
+	}
+	options := make([]string, len(input.Enum))
+	for i, allowed := range input.Enum {
+		options[i] = FormatValue(allowed)
+	}
+	return "must be one of " + strings.Join(options, ", ")
+}
+
+// checkType returns why value is not of type t, or "". Values are
+// expected in their decoded JSON form, so all numbers are float64.
+func checkType(t models.InputType, value interface{}) string {
+	switch t {
+	case "", models.StringInput:
+		if _, ok := value.(string); !ok {
+			return "must be a string"
+		}
+	case models.NumberInput:
+		if _, ok := value.(float64); !ok {
+			return "must be a number"
+		}
+	case models.IntegerInput:
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			return "must be an integer"
+		}
+	case models.BooleanInput:
+		if _, ok := value.(bool); !ok {
+			return "must be a boolean"
+		}
+	}
+	return ""
+}
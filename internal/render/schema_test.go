@@ -0,0 +1,64 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/epuerta9/prompts.kitchenai/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSchema(t *testing.T) {
+	inputs := []models.Input{
+		{Name: "company", Type: models.StringInput, Required: true},
+		{Name: "question"},
+		{Name: "max_words", Type: models.IntegerInput, Default: float64(50)},
+	}
+	assert.NoError(t, ValidateSchema(inputs, messages))
+
+	err := ValidateSchema([]models.Input{
+		{Name: "company", Type: "text"},
+		{Name: "company"},
+		{Name: "9lives"},
+		{Name: "max_words", Type: models.IntegerInput, Default: "many", Enum: []interface{}{float64(10), true}},
+	}, messages)
+
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []models.FieldError{
+		{Field: "inputs[0].type", Message: "must be one of string, number, integer or boolean"},
+		{Field: "inputs[1].name", Message: `"company" is declared more than once`},
+		{Field: "inputs[2].name", Message: "must start with a letter or underscore and contain only letters, digits, _ and ."},
+		{Field: "inputs[3].default", Message: "must be an integer"},
+		{Field: "inputs[3].enum[1]", Message: "must be an integer"},
+		{Field: "inputs", Message: `variable "question" is used in messages but not declared`},
+	}, verr.Fields)
+}
+
+func TestResolve(t *testing.T) {
+	inputs := []models.Input{
+		{Name: "company", Required: true},
+		{Name: "tone", Enum: []interface{}{"formal", "casual"}, Default: "formal"},
+		{Name: "strict", Type: models.BooleanInput},
+		{Name: "temperature", Type: models.NumberInput},
+	}
+
+	resolved, err := Resolve(inputs, map[string]interface{}{"company": "Acme", "temperature": 0.5})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"company":     "Acme",
+		"tone":        "formal",
+		"strict":      "",
+		"temperature": 0.5,
+	}, resolved)
+
+	_, err = Resolve(inputs, map[string]interface{}{"tone": "angry", "strict": "yes", "temperature": "hot"})
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []models.FieldError{
+		{Field: "variables.company", Message: "is required"},
+		{Field: "variables.tone", Message: "must be one of formal, casual"},
+		{Field: "variables.strict", Message: "must be a boolean"},
+		{Field: "variables.temperature", Message: "must be a number"},
+	}, verr.Fields)
+}
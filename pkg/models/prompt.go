@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -15,6 +16,8 @@ type User struct {
 // Prompt represents a prompt template
 type Prompt struct {
 	ID          string    `json:"id"`
+	Slug        string    `json:"slug"`
+	Namespace   string    `json:"namespace,omitempty"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	CreatedBy   User      `json:"created_by"`
@@ -39,15 +42,38 @@ type Message struct {
 	Content string      `json:"content"`
 }
 
+// InputType is the type of value a template variable accepts
+type InputType string
+
+const (
+	StringInput  InputType = "string"
+	NumberInput  InputType = "number"
+	IntegerInput InputType = "integer"
+	BooleanInput InputType = "boolean"
+)
+
+// Input declares a template variable accepted by a version
+type Input struct {
+	Name        string        `json:"name"`
+	Type        InputType     `json:"type"`
+	Required    bool          `json:"required,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Description string        `json:"description,omitempty"`
+}
+
 // Version represents a version of a prompt
 type Version struct {
-	ID        string    `json:"id"`
-	PromptID  string    `json:"prompt_id"`
-	Version   int       `json:"version"`
-	Messages  []Message `json:"messages"`
-	CreatedBy User      `json:"created_by"`
-	CreatedAt time.Time `json:"created_at,omitempty"`
-	Evals     []Eval    `json:"evals,omitempty"`
+	ID           string    `json:"id"`
+	PromptID     string    `json:"prompt_id"`
+	Version      int       `json:"version"`
+	Messages     []Message `json:"messages"`
+	Inputs       []Input   `json:"inputs,omitempty"`
+	Variables    []string  `json:"variables,omitempty"`
+	RestoredFrom int       `json:"restored_from,omitempty"`
+	CreatedBy    User      `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at,omitempty"`
+	Evals        []Eval    `json:"evals,omitempty"`
 }
 
 // Comment represents a comment on a prompt
@@ -72,19 +98,354 @@ type Eval struct {
 // PromptRequest represents the request body for creating/updating a prompt
 type PromptRequest struct {
 	ID          string    `json:"id,omitempty"`
+	Slug        string    `json:"slug,omitempty"`
+	Namespace   string    `json:"namespace,omitempty"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	CreatedBy   User      `json:"created_by"`
 	Messages    []Message `json:"messages,omitempty"`
+	Inputs      []Input   `json:"inputs,omitempty"`
+	// CollectionID and Tags are left unchanged on update when omitted. An
+	// empty collection ID moves the prompt out of its collection.
+	CollectionID *string  `json:"collection_id,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+// TagRequest represents the request body for creating or renaming a tag
+type TagRequest struct {
+	Name string `json:"name"`
+}
+
+// CollectionRequest represents the request body for creating or updating
+// a collection. An empty ParentID places the collection at the top level.
+type CollectionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ParentID    string `json:"parent_id,omitempty"`
+	CreatedBy   User   `json:"created_by"`
+}
+
+// DatasetRow is one example of a dataset: the input variables to render a
+// prompt with and, optionally, the output it is expected to produce
+type DatasetRow struct {
+	Variables map[string]interface{} `json:"variables"`
+	Expected  string                 `json:"expected,omitempty"`
+}
+
+// DatasetRequest represents the request body for creating or updating a
+// dataset. Rows are only read on create.
+type DatasetRequest struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Rows        []DatasetRow `json:"rows,omitempty"`
+	CreatedBy   User         `json:"created_by"`
+}
+
+// DatasetRowsRequest represents the request body for appending rows to a
+// dataset
+type DatasetRowsRequest struct {
+	Rows []DatasetRow `json:"rows"`
+}
+
+// EvalRunRequest represents the request body for running a prompt version
+// against every row of a dataset
+type EvalRunRequest struct {
+	DatasetID   string   `json:"dataset_id"`
+	Model       string   `json:"model"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	MaxTokens   int      `json:"max_tokens"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	// Scorers are scorer specs such as {"type": "contains", "value": "x"}.
+	// Without any, outputs are compared with the expected output.
+	Scorers   []json.RawMessage `json:"scorers,omitempty"`
+	CreatedBy User              `json:"created_by"`
+}
+
+// EvalRunSummary aggregates the results of an eval run
+type EvalRunSummary struct {
+	Rows          int64           `json:"rows"`
+	Completed     int64           `json:"completed"`
+	Errors        int64           `json:"errors"`
+	Scored        int64           `json:"scored"`
+	MeanScore     *float64        `json:"mean_score"`
+	TotalTokens   int64           `json:"total_tokens"`
+	MeanLatencyMs *float64        `json:"mean_latency_ms"`
+	Scorers       []ScorerSummary `json:"scorers"`
 }
 
-// VersionRequest represents the request body for creating a new version
+// ScorerSummary aggregates the evaluations one scorer recorded in an eval
+// run
+type ScorerSummary struct {
+	Scorer      string   `json:"scorer"`
+	Evaluations int64    `json:"evaluations"`
+	Passed      int64    `json:"passed"`
+	Failed      int64    `json:"failed"`
+	MeanScore   *float64 `json:"mean_score"`
+}
+
+// ScoreRequest represents the request body for scoring a single output of
+// a prompt version with automatic scorers
+type ScoreRequest struct {
+	Input     string                 `json:"input,omitempty"`
+	Output    string                 `json:"output"`
+	Expected  *string                `json:"expected,omitempty"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+	Scorers   []json.RawMessage      `json:"scorers"`
+	CreatedBy User                   `json:"created_by"`
+}
+
+// CompareRequest represents the request body for comparing two versions
+// of a prompt on a dataset. The eval run fields apply to both versions.
+type CompareRequest struct {
+	Baseline  int64 `json:"baseline"`
+	Candidate int64 `json:"candidate"`
+	EvalRunRequest
+	// TieMargin is the largest score difference counted as a tie
+	TieMargin float64 `json:"tie_margin"`
+	// Rerun starts new eval runs instead of reusing matching ones
+	Rerun bool `json:"rerun"`
+}
+
+// Comparison compares the eval runs of two prompt versions on the same
+// dataset. Summary and Cases are set once both runs have completed.
+type Comparison struct {
+	Status    string             `json:"status"`
+	Baseline  ComparedRun        `json:"baseline"`
+	Candidate ComparedRun        `json:"candidate"`
+	Summary   *ComparisonSummary `json:"summary,omitempty"`
+	Cases     []CaseComparison   `json:"cases,omitempty"`
+}
+
+// ComparedRun is the eval run of one side of a comparison
+type ComparedRun struct {
+	Version   int64  `json:"version"`
+	EvalRunID string `json:"eval_run_id"`
+	Status    string `json:"status"`
+	Reused    bool   `json:"reused"`
+}
+
+// ComparisonSummary aggregates a comparison. Wins, ties and losses are
+// counted from the candidate's side over the cases scored in both runs,
+// and the means are taken over those same cases. PValue is the two-sided
+// sign test of wins against losses.
+type ComparisonSummary struct {
+	Cases         int           `json:"cases"`
+	Wins          int           `json:"wins"`
+	Ties          int           `json:"ties"`
+	Losses        int           `json:"losses"`
+	Unscored      int           `json:"unscored"`
+	BaselineMean  *float64      `json:"baseline_mean"`
+	CandidateMean *float64      `json:"candidate_mean"`
+	MeanDelta     *float64      `json:"mean_delta"`
+	Scorers       []ScorerDelta `json:"scorers"`
+	PValue        float64       `json:"p_value"`
+	Significant   bool          `json:"significant"`
+	Verdict       string        `json:"verdict"`
+}
+
+// ScorerDelta compares one scorer's mean score across the two runs
+type ScorerDelta struct {
+	Scorer        string   `json:"scorer"`
+	BaselineMean  *float64 `json:"baseline_mean"`
+	CandidateMean *float64 `json:"candidate_mean"`
+	Delta         *float64 `json:"delta"`
+}
+
+// CaseComparison is the result of one dataset row in both runs. Delta is
+// the candidate's score minus the baseline's.
+type CaseComparison struct {
+	DatasetRowID    string                 `json:"dataset_row_id"`
+	Position        int64                  `json:"position"`
+	Variables       map[string]interface{} `json:"variables"`
+	Expected        *string                `json:"expected,omitempty"`
+	BaselineOutput  *string                `json:"baseline_output"`
+	CandidateOutput *string                `json:"candidate_output"`
+	BaselineError   *string                `json:"baseline_error,omitempty"`
+	CandidateError  *string                `json:"candidate_error,omitempty"`
+	BaselineScore   *float64               `json:"baseline_score"`
+	CandidateScore  *float64               `json:"candidate_score"`
+	Delta           *float64               `json:"delta"`
+	Outcome         string                 `json:"outcome"`
+}
+
+// EvalSummary aggregates the evaluation scores of every version of a
+// prompt and how they trend across versions
+type EvalSummary struct {
+	PromptID string              `json:"prompt_id"`
+	Scorer   string              `json:"scorer,omitempty"`
+	Versions []VersionScoreStats `json:"versions"`
+	Trend    ScoreTrend          `json:"trend"`
+}
+
+// VersionScoreStats are the score statistics of one version. They are nil
+// when the version has no scores (and StdDev with a single score). Delta is
+// the change in mean from the previous version with scores.
+type VersionScoreStats struct {
+	Version   int64     `json:"version"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	Count     int64     `json:"count"`
+	Mean      *float64  `json:"mean"`
+	Median    *float64  `json:"median"`
+	P10       *float64  `json:"p10"`
+	P90       *float64  `json:"p90"`
+	StdDev    *float64  `json:"stddev"`
+	Delta     *float64  `json:"delta"`
+}
+
+// ScoreTrend describes how mean scores move across the versions with
+// scores. Slope is the least-squares change in mean per version and
+// Change the difference between the last and first of those versions.
+// Direction is "improving", "declining" or "flat", and empty with fewer
+// than two such versions.
+type ScoreTrend struct {
+	Versions  int      `json:"versions"`
+	Change    *float64 `json:"change"`
+	Slope     *float64 `json:"slope"`
+	Direction string   `json:"direction,omitempty"`
+}
+
+// ReviewItem is a pair of outputs for a reviewer to compare blind. Both
+// were produced for the same dataset row by different versions of a
+// prompt and are shown in random order.
+type ReviewItem struct {
+	PromptID     string                 `json:"prompt_id"`
+	DatasetRowID string                 `json:"dataset_row_id"`
+	Variables    map[string]interface{} `json:"variables"`
+	Expected     *string                `json:"expected,omitempty"`
+	Left         ReviewOutput           `json:"left"`
+	Right        ReviewOutput           `json:"right"`
+}
+
+// ReviewOutput is one side of a review item. ResultID identifies it when
+// the judgment is submitted.
+type ReviewOutput struct {
+	ResultID int64  `json:"result_id"`
+	Output   string `json:"output"`
+}
+
+// JudgmentRequest represents the request body for recording which of two
+// outputs a reviewer preferred. Winner is "left", "right" or "tie".
+type JudgmentRequest struct {
+	LeftResultID  int64  `json:"left_result_id"`
+	RightResultID int64  `json:"right_result_id"`
+	Winner        string `json:"winner"`
+	Notes         string `json:"notes"`
+	CreatedBy     User   `json:"created_by"`
+}
+
+// VersionRating is a version's Bradley-Terry rating from pairwise
+// judgments, on an Elo-like scale centered on 1000
+type VersionRating struct {
+	Version   int64   `json:"version"`
+	Rating    float64 `json:"rating"`
+	Judgments int     `json:"judgments"`
+	Wins      int     `json:"wins"`
+	Losses    int     `json:"losses"`
+	Ties      int     `json:"ties"`
+}
+
+// UserRequest represents the request body for creating or updating a user
+type UserRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// APIKeyRequest represents the request body for creating an API key.
+// WorkspaceID defaults to the workspace of the key making the request.
+type APIKeyRequest struct {
+	Name        string `json:"name"`
+	WorkspaceID string `json:"workspace_id,omitempty"`
+}
+
+// APIKey describes an API key. Key holds the secret key only in the
+// response that creates it; afterwards only its Prefix is known.
+type APIKey struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Key         string     `json:"key,omitempty"`
+	WorkspaceID string     `json:"workspace_id"`
+	CreatedAt   time.Time  `json:"created_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+}
+
+// Workspace is a team's space for prompts. Role is the caller's role in it.
+type Workspace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// WorkspaceRequest represents the request body for creating a workspace
+type WorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+// WorkspaceMember is a user who belongs to a workspace
+type WorkspaceMember struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at,omitempty"`
+}
+
+// WorkspaceMemberRequest represents the request body for adding a member
+// or changing their role
+type WorkspaceMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// ListResponse is one page of a list endpoint. NextCursor is passed back
+// as ?cursor= to fetch the following page and is empty on the last page.
+type ListResponse struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// SearchResult is a prompt matching a search query. Highlights hold
+// HTML-escaped fragments with matches wrapped in <mark>; a field is empty
+// when the query did not match it.
+type SearchResult struct {
+	ID          string           `json:"id"`
+	Slug        string           `json:"slug"`
+	Namespace   string           `json:"namespace,omitempty"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Highlights  SearchHighlights `json:"highlights"`
+	Score       float64          `json:"score"`
+}
+
+// SearchHighlights are the matching fragments of a search result
+type SearchHighlights struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Content     string `json:"content,omitempty"`
+}
+
+// SlugRequest represents the request body for renaming a prompt's slug
+type SlugRequest struct {
+	Slug      string `json:"slug"`
+	Namespace string `json:"namespace"`
+}
+
+// VersionRequest represents the request body for creating a new version.
+// When Inputs is set, every variable used in Messages must be declared.
 type VersionRequest struct {
 	ID        string    `json:"id,omitempty"`
 	Messages  []Message `json:"messages"`
+	Inputs    []Input   `json:"inputs,omitempty"`
 	CreatedBy User      `json:"created_by"`
 }
 
+// RestoreRequest represents the request body for restoring a version
+type RestoreRequest struct {
+	ID        string `json:"id,omitempty"`
+	CreatedBy User   `json:"created_by"`
+}
+
 // CommentRequest represents the request body for adding a comment
 type CommentRequest struct {
 	ID        string `json:"id,omitempty"`
@@ -100,24 +461,64 @@ type EvalRequest struct {
 	CreatedBy User    `json:"created_by"`
 }
 
-// RunPromptRequest represents the request to run a prompt with a specific model
+// LabelRequest represents the request body for moving a deployment label
+type LabelRequest struct {
+	Version int  `json:"version"`
+	MovedBy User `json:"moved_by"`
+}
+
+// RunPromptRequest represents the request to run a prompt with a specific model.
+// When PromptID is set the run is recorded against that prompt's Version
+// (the latest one if Version is zero), and the version's messages are used
+// if Messages is empty. Variables are substituted into the messages before
+// the model is called.
 type RunPromptRequest struct {
-	Messages    []Message `json:"messages"`
-	Model       string    `json:"model"`
-	Temperature float64   `json:"temperature"`
-	MaxTokens   int       `json:"max_tokens"`
-	TopP        float64   `json:"top_p"`
+	PromptID    string                 `json:"prompt_id,omitempty"`
+	Version     int                    `json:"version,omitempty"`
+	Messages    []Message              `json:"messages"`
+	Variables   map[string]interface{} `json:"variables,omitempty"`
+	Model       string                 `json:"model"`
+	Temperature *float64               `json:"temperature,omitempty"`
+	MaxTokens   int                    `json:"max_tokens"`
+	TopP        *float64               `json:"top_p,omitempty"`
+	CreatedBy   User                   `json:"created_by"`
+}
+
+// Usage represents the token usage reported by a model provider
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 // RunPromptResponse represents the response from running a prompt
 type RunPromptResponse struct {
+	RunID    string `json:"run_id,omitempty"`
 	Response string `json:"response"`
 	Model    string `json:"model"`
-	Usage    struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	Usage    Usage  `json:"usage"`
+}
+
+// RenderRequest represents the request to render a version's messages
+type RenderRequest struct {
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// RenderResponse represents the rendered messages of a version
+type RenderResponse struct {
+	Messages []Message `json:"messages"`
+}
+
+// FieldError describes why a single request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is returned when request fields fail validation
+type ValidationErrorResponse struct {
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields"`
 }
 
 // IntegrationRequest represents a request to integrate a prompt into a file
@@ -3,6 +3,7 @@ import json
 import os
 import shutil
 from typing import List, Dict, Optional, Any, Union
+from urllib.parse import quote
 
 
 class PromptClient:
@@ -65,13 +66,48 @@ class PromptClient:
         response.raise_for_status()
         return response.json()
     
+    def _list_all(self, path: str) -> List[Dict]:
+        """Fetch every page of a paginated list endpoint.
+        
+        Args:
+            path: API endpoint path
+            
+        Returns:
+            The items of all pages
+        """
+        items: List[Dict] = []
+        page_path = path
+        while True:
+            page = self._make_request("GET", page_path)
+            if isinstance(page, list):
+                # Servers without pagination return a bare list
+                return items + page
+            items.extend(page.get("items") or [])
+            cursor = page.get("next_cursor")
+            if not cursor:
+                return items
+            page_path = f"{path}?cursor={quote(cursor)}"
+    
     def list_prompts(self) -> List[Dict]:
         """List all prompts.
         
         Returns:
             A list of prompt objects
         """
-        return self._make_request("GET", "/api/prompts")
+        return self._list_all("/api/prompts")
+    
+    def search_prompts(self, query: str, limit: int = 20) -> List[Dict]:
+        """Search prompts by title, description and latest version content.
+        
+        Args:
+            query: Words to search for
+            limit: Maximum number of results
+            
+        Returns:
+            Matching prompts, best match first
+        """
+        response = self._make_request("GET", f"/api/search?q={quote(query)}&limit={limit}")
+        return response.get("items", [])
     
     def get_prompt(self, prompt_id: str) -> Dict:
         """Get a specific prompt.
@@ -137,7 +173,7 @@ class PromptClient:
         Returns:
             A list of version objects
         """
-        return self._make_request("GET", f"/api/prompts/{prompt_id}/versions")
+        return self._list_all(f"/api/prompts/{prompt_id}/versions")
     
     def get_version(self, prompt_id: str, version: Union[int, str]) -> Dict:
         """Get a specific version of a prompt.
@@ -175,7 +211,7 @@ class PromptClient:
         Returns:
             A list of comment objects
         """
-        return self._make_request("GET", f"/api/prompts/{prompt_id}/comments")
+        return self._list_all(f"/api/prompts/{prompt_id}/comments")
     
     def add_comment(self, prompt_id: str, content: str) -> Dict:
         """Add a comment to a prompt.
@@ -220,7 +256,7 @@ class PromptClient:
         Returns:
             A list of evaluation objects
         """
-        return self._make_request("GET", f"/api/prompts/{prompt_id}/versions/{version}/evals")
+        return self._list_all(f"/api/prompts/{prompt_id}/versions/{version}/evals")
     
     def integrate_prompt(self, prompt_id: str, version: Union[int, str], file_path: str) -> Dict:
         """Integrate a prompt version into a file.